@@ -0,0 +1,362 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RocksDBConfig is the structured form of the rocksdb.* configs of kvrocks
+type RocksDBConfig struct {
+	// +kubebuilder:validation:Enum=no;snappy;lz4;zstd;zlib
+	// +optional
+	Compression string `json:"compression,omitempty"`
+	// BlockSize in bytes
+	// +optional
+	BlockSize *int64 `json:"blockSize,omitempty"`
+	// +optional
+	BlockCacheSizeMB *int32 `json:"blockCacheSizeMB,omitempty"`
+	// +optional
+	RowCacheSizeMB *int32 `json:"rowCacheSizeMB,omitempty"`
+	// +optional
+	MaxOpenFiles *int32 `json:"maxOpenFiles,omitempty"`
+	// +optional
+	WriteBufferSizeMB *int32 `json:"writeBufferSizeMB,omitempty"`
+	// +optional
+	MaxWriteBufferNumber *int32 `json:"maxWriteBufferNumber,omitempty"`
+	// +optional
+	TargetFileSizeBaseMB *int32 `json:"targetFileSizeBaseMB,omitempty"`
+	// +optional
+	MaxBackgroundCompactions *int32 `json:"maxBackgroundCompactions,omitempty"`
+	// +optional
+	MaxBackgroundFlushes *int32 `json:"maxBackgroundFlushes,omitempty"`
+	// +optional
+	MaxSubCompactions *int32 `json:"maxSubCompactions,omitempty"`
+	// +optional
+	Level0SlowdownWritesTrigger *int32 `json:"level0SlowdownWritesTrigger,omitempty"`
+	// +optional
+	Level0StopWritesTrigger *int32 `json:"level0StopWritesTrigger,omitempty"`
+	// +optional
+	WalTTLSeconds *int32 `json:"walTTLSeconds,omitempty"`
+	// +optional
+	WalSizeLimitMB *int32 `json:"walSizeLimitMB,omitempty"`
+	// +optional
+	CacheIndexAndFilterBlocks *bool `json:"cacheIndexAndFilterBlocks,omitempty"`
+	// +optional
+	EnablePipelinedWrite *bool `json:"enablePipelinedWrite,omitempty"`
+	// +optional
+	DisableAutoCompactions *bool `json:"disableAutoCompactions,omitempty"`
+}
+
+// Configs returns the rocksDB config in kvrocks config format
+func (c *RocksDBConfig) Configs() map[string]string {
+	config := map[string]string{}
+	if c == nil {
+		return config
+	}
+	if c.Compression != "" {
+		config["rocksdb.compression"] = c.Compression
+	}
+	setInt := func(key string, value *int32) {
+		if value != nil {
+			config[key] = strconv.Itoa(int(*value))
+		}
+	}
+	setBool := func(key string, value *bool) {
+		if value != nil {
+			config[key] = formatBool(*value)
+		}
+	}
+	if c.BlockSize != nil {
+		config["rocksdb.block_size"] = strconv.FormatInt(*c.BlockSize, 10)
+	}
+	setInt("rocksdb.block_cache_size", c.BlockCacheSizeMB)
+	setInt("rocksdb.row_cache_size", c.RowCacheSizeMB)
+	setInt("rocksdb.max_open_files", c.MaxOpenFiles)
+	setInt("rocksdb.write_buffer_size", c.WriteBufferSizeMB)
+	setInt("rocksdb.max_write_buffer_number", c.MaxWriteBufferNumber)
+	setInt("rocksdb.target_file_size_base", c.TargetFileSizeBaseMB)
+	setInt("rocksdb.max_background_compactions", c.MaxBackgroundCompactions)
+	setInt("rocksdb.max_background_flushes", c.MaxBackgroundFlushes)
+	setInt("rocksdb.max_sub_compactions", c.MaxSubCompactions)
+	setInt("rocksdb.level0_slowdown_writes_trigger", c.Level0SlowdownWritesTrigger)
+	setInt("rocksdb.level0_stop_writes_trigger", c.Level0StopWritesTrigger)
+	setInt("rocksdb.wal_ttl_seconds", c.WalTTLSeconds)
+	setInt("rocksdb.wal_size_limit_mb", c.WalSizeLimitMB)
+	setBool("rocksdb.cache_index_and_filter_blocks", c.CacheIndexAndFilterBlocks)
+	setBool("rocksdb.enable_pipelined_write", c.EnablePipelinedWrite)
+	setBool("rocksdb.disable_auto_compactions", c.DisableAutoCompactions)
+	return config
+}
+
+func formatBool(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
+
+type ConfigValueType string
+
+const (
+	ConfigInt    ConfigValueType = "int"
+	ConfigBool   ConfigValueType = "bool"
+	ConfigEnum   ConfigValueType = "enum"
+	ConfigString ConfigValueType = "string"
+)
+
+// ConfigSchema describes a kvrocks config key
+// +kubebuilder:object:generate=false
+type ConfigSchema struct {
+	Type ConfigValueType
+	// Min and Max bound the value of int configs
+	Min int64
+	Max int64
+	// Enum lists the allowed values of enum configs
+	Enum []string
+	// Dynamic configs can be changed by CONFIG SET, the others need a restart
+	Dynamic bool
+	// MinVersion is the first kvrocks version supporting the config, empty means all versions
+	MinVersion string
+}
+
+func intConfig(min, max int64, dynamic bool) ConfigSchema {
+	return ConfigSchema{Type: ConfigInt, Min: min, Max: max, Dynamic: dynamic}
+}
+
+func boolConfig(dynamic bool) ConfigSchema {
+	return ConfigSchema{Type: ConfigBool, Dynamic: dynamic}
+}
+
+func enumConfig(dynamic bool, values ...string) ConfigSchema {
+	return ConfigSchema{Type: ConfigEnum, Enum: values, Dynamic: dynamic}
+}
+
+func stringConfig(dynamic bool) ConfigSchema {
+	return ConfigSchema{Type: ConfigString, Dynamic: dynamic}
+}
+
+func (s ConfigSchema) since(version string) ConfigSchema {
+	s.MinVersion = version
+	return s
+}
+
+const maxInt = int64(^uint32(0) >> 1)
+
+// KVRocksConfigSchema lists the configs known by the operator
+var KVRocksConfigSchema = map[string]ConfigSchema{
+	"daemonize":                            boolConfig(false),
+	"bind":                                 stringConfig(false),
+	"port":                                 intConfig(1, 65535, false),
+	"workers":                              intConfig(1, 256, false),
+	"tcp-backlog":                          intConfig(0, maxInt, false),
+	"slaveof":                              stringConfig(false),
+	"db-name":                              stringConfig(false),
+	"dir":                                  stringConfig(false),
+	"backup-dir":                           stringConfig(false),
+	"log-dir":                              stringConfig(false),
+	"pidfile":                              stringConfig(false),
+	"supervised":                           enumConfig(false, "no", "upstart", "systemd", "auto"),
+	"rename-command":                       stringConfig(false),
+	"masterauth":                           stringConfig(false),
+	"requirepass":                          stringConfig(false),
+	"cluster-enabled":                      boolConfig(false),
+	"timeout":                              intConfig(0, maxInt, true),
+	"maxclients":                           intConfig(1, maxInt, true),
+	"max-backup-to-keep":                   intConfig(0, 1, true),
+	"max-backup-keep-hours":                intConfig(0, maxInt, true),
+	"master-use-repl-port":                 boolConfig(true),
+	"max-io-mb":                            intConfig(0, maxInt, true),
+	"max-db-size":                          intConfig(0, maxInt, true),
+	"max-replication-mb":                   intConfig(0, maxInt, true),
+	"slave-serve-stale-data":               boolConfig(true),
+	"slave-empty-db-before-fullsync":       boolConfig(true),
+	"slave-priority":                       intConfig(0, maxInt, true),
+	"slave-read-only":                      boolConfig(true),
+	"purge-backup-on-fullsync":             boolConfig(true),
+	"auto-resize-block-and-sst":            boolConfig(true),
+	"fullsync-recv-file-delay":             intConfig(0, maxInt, true),
+	"slowlog-log-slower-than":              intConfig(-1, maxInt, true),
+	"slowlog-max-len":                      intConfig(0, maxInt, true),
+	"profiling-sample-ratio":               intConfig(0, 100, true),
+	"profiling-sample-record-max-len":      intConfig(0, maxInt, true),
+	"profiling-sample-record-threshold-ms": intConfig(0, maxInt, true),
+	"profiling-sample-commands":            stringConfig(true),
+	"compact-cron":                         stringConfig(true),
+	"bgsave-cron":                          stringConfig(true),
+	"compaction-checker-range":             stringConfig(true),
+	"force-compact-file-age":               intConfig(0, maxInt, true),
+	"force-compact-file-min-deleted-percentage": intConfig(1, 100, true),
+	"migrate-speed":                 intConfig(0, maxInt, true),
+	"migrate-pipeline-size":         intConfig(1, maxInt, true),
+	"migrate-sequence-gap":          intConfig(1, maxInt, true),
+	"log-level":                     enumConfig(true, "debug", "info", "warning", "error", "fatal"),
+	"log-retention-days":            intConfig(-1, maxInt, true),
+	"redis-cursor-compatible":       boolConfig(true),
+	"repl-namespace-enabled":        boolConfig(true),
+	"persist-cluster-nodes-enabled": boolConfig(true).since("2.4.0"),
+	"resp3-enabled":                 boolConfig(true).since("2.6.0"),
+	"json-max-nesting-depth":        intConfig(0, maxInt, true).since("2.7.0"),
+	"json-storage-format":           enumConfig(true, "json", "cbor").since("2.7.0"),
+
+	"rocksdb.compression":                           enumConfig(true, "no", "snappy", "lz4", "zstd", "zlib"),
+	"rocksdb.block_size":                            intConfig(0, maxInt, false),
+	"rocksdb.block_cache_size":                      intConfig(0, maxInt, false),
+	"rocksdb.row_cache_size":                        intConfig(0, maxInt, false),
+	"rocksdb.metadata_block_cache_size":             intConfig(0, maxInt, false),
+	"rocksdb.subkey_block_cache_size":               intConfig(0, maxInt, false),
+	"rocksdb.share_metadata_and_subkey_block_cache": boolConfig(false),
+	"rocksdb.cache_index_and_filter_blocks":         boolConfig(false),
+	"rocksdb.enable_pipelined_write":                boolConfig(false),
+	"rocksdb.wal_ttl_seconds":                       intConfig(0, maxInt, false),
+	"rocksdb.wal_size_limit_mb":                     intConfig(0, maxInt, false),
+	"rocksdb.level_compaction_dynamic_level_bytes":  boolConfig(false),
+	"rocksdb.max_open_files":                        intConfig(-1, maxInt, true),
+	"rocksdb.write_buffer_size":                     intConfig(1, 4096, true),
+	"rocksdb.max_write_buffer_number":               intConfig(1, 256, true),
+	"rocksdb.target_file_size_base":                 intConfig(1, 1024, true),
+	"rocksdb.max_background_compactions":            intConfig(-1, 32, true),
+	"rocksdb.max_background_flushes":                intConfig(-1, 32, true),
+	"rocksdb.max_sub_compactions":                   intConfig(0, 16, true),
+	"rocksdb.delayed_write_rate":                    intConfig(0, maxInt, true),
+	"rocksdb.max_total_wal_size":                    intConfig(0, maxInt, true),
+	"rocksdb.disable_auto_compactions":              boolConfig(true),
+	"rocksdb.stats_dump_period_sec":                 intConfig(0, maxInt, true),
+	"rocksdb.compaction_readahead_size":             intConfig(0, maxInt, true),
+	"rocksdb.level0_slowdown_writes_trigger":        intConfig(1, 1024, true),
+	"rocksdb.level0_stop_writes_trigger":            intConfig(1, 1024, true),
+	"rocksdb.level0_file_num_compaction_trigger":    intConfig(1, 1024, true),
+	"rocksdb.max_bytes_for_level_base":              intConfig(1, maxInt, true),
+	"rocksdb.max_bytes_for_level_multiplier":        intConfig(1, 100, true),
+	"rocksdb.enable_blob_files":                     boolConfig(true),
+	"rocksdb.min_blob_size":                         intConfig(0, maxInt, true),
+	"rocksdb.blob_file_size":                        intConfig(1, maxInt, true),
+	"rocksdb.enable_blob_garbage_collection":        boolConfig(true),
+	"rocksdb.blob_garbage_collection_age_cutoff":    intConfig(0, 100, true),
+	"rocksdb.read_options.async_io":                 boolConfig(true).since("2.4.0"),
+	"rocksdb.write_options.sync":                    boolConfig(true),
+	"rocksdb.write_options.disable_wal":             boolConfig(true),
+	"rocksdb.write_options.no_slowdown":             boolConfig(true),
+	"rocksdb.write_options.low_pri":                 boolConfig(true),
+}
+
+// Validate checks the value against the schema
+func (s ConfigSchema) Validate(value string) error {
+	switch s.Type {
+	case ConfigInt:
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+		if v < s.Min || v > s.Max {
+			return fmt.Errorf("%d is out of range [%d, %d]", v, s.Min, s.Max)
+		}
+	case ConfigBool:
+		if value != "yes" && value != "no" {
+			return fmt.Errorf("%q must be yes or no", value)
+		}
+	case ConfigEnum:
+		for _, e := range s.Enum {
+			if value == e {
+				return nil
+			}
+		}
+		return fmt.Errorf("%q must be one of %s", value, strings.Join(s.Enum, ","))
+	}
+	return nil
+}
+
+// Configs returns kvrocksConfig merged with the structured rocksDBConfig
+func (spec *KVRocksSpec) Configs() map[string]string {
+	config := spec.RocksDBConfig.Configs()
+	for key, value := range spec.KVRocksConfig {
+		config[key] = value
+	}
+	return config
+}
+
+// ValidateConfigs checks the configs against KVRocksConfigSchema, and returns the valid ones
+// with the reasons of the invalid ones sorted by key
+func (spec *KVRocksSpec) ValidateConfigs() (map[string]string, []string) {
+	valid := map[string]string{}
+	var invalid []string
+	rocksDBConfig := spec.RocksDBConfig.Configs()
+	version := ImageVersion(spec.Image)
+	for key, value := range spec.Configs() {
+		schema, ok := KVRocksConfigSchema[key]
+		if !ok {
+			invalid = append(invalid, fmt.Sprintf("%s: unknown config", key))
+			continue
+		}
+		// an empty value in kvrocksConfig still overrides rocksDBConfig
+		_, inRocksDB := rocksDBConfig[key]
+		if _, inKVRocks := spec.KVRocksConfig[key]; inRocksDB && inKVRocks {
+			invalid = append(invalid, fmt.Sprintf("%s: set in both kvrocksConfig and rocksDBConfig", key))
+			continue
+		}
+		if err := schema.Validate(value); err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s: %s", key, err.Error()))
+			continue
+		}
+		if schema.MinVersion != "" && version != "" && compareVersion(version, schema.MinVersion) < 0 {
+			invalid = append(invalid, fmt.Sprintf("%s: requires kvrocks %s or later", key, schema.MinVersion))
+			continue
+		}
+		valid[key] = value
+	}
+	sort.Strings(invalid)
+	return valid, invalid
+}
+
+// ImageVersion returns the version in the image tag, or empty if the tag is not a version like 2.4.0
+func ImageVersion(image string) string {
+	index := strings.LastIndex(image, ":")
+	if index == -1 || strings.Contains(image[index:], "/") {
+		return ""
+	}
+	version := strings.TrimPrefix(image[index+1:], "v")
+	for _, field := range strings.Split(version, ".") {
+		if _, err := strconv.Atoi(field); err != nil {
+			return ""
+		}
+	}
+	return version
+}
+
+func compareVersion(a, b string) int {
+	fieldsA := strings.Split(a, ".")
+	fieldsB := strings.Split(b, ".")
+	for i := 0; i < len(fieldsA) || i < len(fieldsB); i++ {
+		var x, y int
+		if i < len(fieldsA) {
+			x, _ = strconv.Atoi(fieldsA[i])
+		}
+		if i < len(fieldsB) {
+			y, _ = strconv.Atoi(fieldsB[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
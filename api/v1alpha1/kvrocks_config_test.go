@@ -0,0 +1,172 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSchemaValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema ConfigSchema
+		value  string
+		expErr bool
+	}{
+		{
+			name:   "An int in range should be valid.",
+			schema: intConfig(1, 256, false),
+			value:  "8",
+		}, {
+			name:   "An int out of range should be invalid.",
+			schema: intConfig(1, 256, false),
+			value:  "0",
+			expErr: true,
+		}, {
+			name:   "A non-integer should be invalid for int configs.",
+			schema: intConfig(1, 256, false),
+			value:  "eight",
+			expErr: true,
+		}, {
+			name:   "yes should be valid for bool configs.",
+			schema: boolConfig(true),
+			value:  "yes",
+		}, {
+			name:   "true should be invalid for bool configs.",
+			schema: boolConfig(true),
+			value:  "true",
+			expErr: true,
+		}, {
+			name:   "A listed value should be valid for enum configs.",
+			schema: enumConfig(true, "no", "snappy"),
+			value:  "snappy",
+		}, {
+			name:   "An unlisted value should be invalid for enum configs.",
+			schema: enumConfig(true, "no", "snappy"),
+			value:  "zstd",
+			expErr: true,
+		}, {
+			name:   "Any value should be valid for string configs.",
+			schema: stringConfig(false),
+			value:  "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.schema.Validate(test.value)
+			if test.expErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateConfigs(t *testing.T) {
+	blockSize := int64(4096)
+
+	tests := []struct {
+		name       string
+		spec       KVRocksSpec
+		expValid   map[string]string
+		expInvalid []string
+	}{
+		{
+			name: "Known valid configs should be returned.",
+			spec: KVRocksSpec{
+				KVRocksConfig: map[string]string{"workers": "8", "maxclients": "100"},
+			},
+			expValid: map[string]string{"workers": "8", "maxclients": "100"},
+		}, {
+			name: "Unknown and invalid configs should be reported sorted by key.",
+			spec: KVRocksSpec{
+				KVRocksConfig: map[string]string{"workers": "0", "unknown": "1", "maxclients": "100"},
+			},
+			expValid:   map[string]string{"maxclients": "100"},
+			expInvalid: []string{"unknown: unknown config", "workers: 0 is out of range [1, 256]"},
+		}, {
+			name: "A config set in both kvrocksConfig and rocksDBConfig should be invalid.",
+			spec: KVRocksSpec{
+				KVRocksConfig: map[string]string{"rocksdb.block_size": "8192"},
+				RocksDBConfig: &RocksDBConfig{BlockSize: &blockSize},
+			},
+			expValid:   map[string]string{},
+			expInvalid: []string{"rocksdb.block_size: set in both kvrocksConfig and rocksDBConfig"},
+		}, {
+			name: "An empty override of rocksDBConfig should be invalid.",
+			spec: KVRocksSpec{
+				KVRocksConfig: map[string]string{"rocksdb.block_size": ""},
+				RocksDBConfig: &RocksDBConfig{BlockSize: &blockSize},
+			},
+			expValid:   map[string]string{},
+			expInvalid: []string{"rocksdb.block_size: set in both kvrocksConfig and rocksDBConfig"},
+		}, {
+			name: "A config newer than the image should be invalid.",
+			spec: KVRocksSpec{
+				Image:         "apache/kvrocks:2.5.1",
+				KVRocksConfig: map[string]string{"resp3-enabled": "yes", "persist-cluster-nodes-enabled": "yes"},
+			},
+			expValid:   map[string]string{"persist-cluster-nodes-enabled": "yes"},
+			expInvalid: []string{"resp3-enabled: requires kvrocks 2.6.0 or later"},
+		}, {
+			name: "The version should not be checked if the image tag is not a version.",
+			spec: KVRocksSpec{
+				Image:         "apache/kvrocks:latest",
+				KVRocksConfig: map[string]string{"resp3-enabled": "yes"},
+			},
+			expValid: map[string]string{"resp3-enabled": "yes"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			valid, invalid := test.spec.ValidateConfigs()
+			assert.Equal(test.expValid, valid)
+			assert.Equal(test.expInvalid, invalid)
+		})
+	}
+}
+
+func TestImageVersion(t *testing.T) {
+	tests := []struct {
+		image      string
+		expVersion string
+	}{
+		{image: "apache/kvrocks:2.4.0", expVersion: "2.4.0"},
+		{image: "apache/kvrocks:v2.6", expVersion: "2.6"},
+		{image: "registry:5000/apache/kvrocks:2.5.1", expVersion: "2.5.1"},
+		{image: "registry:5000/apache/kvrocks", expVersion: ""},
+		{image: "apache/kvrocks:latest", expVersion: ""},
+		{image: "apache/kvrocks:2.4.0-rc1", expVersion: ""},
+		{image: "apache/kvrocks", expVersion: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.image, func(t *testing.T) {
+			assert.Equal(t, test.expVersion, ImageVersion(test.image))
+		})
+	}
+}
+
+func TestCompareVersion(t *testing.T) {
+	tests := []struct {
+		a, b   string
+		expCmp int
+	}{
+		{a: "2.4.0", b: "2.4.0", expCmp: 0},
+		{a: "2.4", b: "2.4.0", expCmp: 0},
+		{a: "2.10.0", b: "2.9.0", expCmp: 1},
+		{a: "2.4.0", b: "2.4.1", expCmp: -1},
+		{a: "3", b: "2.6.0", expCmp: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.a+"_"+test.b, func(t *testing.T) {
+			assert.Equal(t, test.expCmp, compareVersion(test.a, test.b))
+		})
+	}
+}
@@ -31,8 +31,14 @@ type KVRocksSpec struct {
 	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
 	Type            KVRocksType       `json:"type"`
 	KVRocksConfig   map[string]string `json:"kvrocksConfig,omitempty"`
-	// RocksDBConfig   map[string]string            `json:"rocksDBConfig,omitempty"`
-	Replicas int32 `json:"replicas"`
+	// ConfigDriftPolicy decides what to do with configs changed outside the operator by CONFIG SET,
+	// Reset (default) sets them back to kvrocksConfig, Report only reports them in status
+	// +kubebuilder:validation:Enum=Reset;Report
+	// +optional
+	ConfigDriftPolicy ConfigDriftPolicy `json:"configDriftPolicy,omitempty"`
+	// +optional
+	RocksDBConfig *RocksDBConfig `json:"rocksDBConfig,omitempty"`
+	Replicas      int32          `json:"replicas"`
 	// +optional
 	Master       uint                         `json:"master"`
 	Password     string                       `json:"password"`
@@ -40,7 +46,178 @@ type KVRocksSpec struct {
 	NodeSelector map[string]string            `json:"nodeSelector,omitempty"`
 	Toleration   []corev1.Toleration          `json:"toleration,omitempty"`
 	Affinity     *corev1.Affinity             `json:"affinity,omitempty"`
-	Storage      *KVRocksStorage              `json:"storage,omitempty"`
+	// TopologySpreadConstraints are added to the pods, the constraints without labelSelector select all pods of the kvrocks
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	// Placement spreads the replicas of each shard and the masters across zones
+	// +optional
+	Placement *KVRocksPlacement `json:"placement,omitempty"`
+	Storage   *KVRocksStorage   `json:"storage,omitempty"`
+	// Exporter configures the metrics exporter sidecar of the kvrocks pods
+	// +optional
+	Exporter *KVRocksExporter `json:"exporter,omitempty"`
+	// SentinelTemplate configures the sentinel created for the kvrocks, the kvrocks image and resources are used by
+	// default
+	// +optional
+	SentinelTemplate *KVRocksSentinelTemplate `json:"sentinelTemplate,omitempty"`
+	// Monitoring creates the PodMonitor and PrometheusRule of the Prometheus operator, they are skipped if the CRDs
+	// are not installed
+	// +optional
+	Monitoring *KVRocksMonitoring `json:"monitoring,omitempty"`
+	// PodTemplate customizes the pods of kvrocks, and those of sentinel, etcd and the kvrocks controller it creates
+	// +optional
+	PodTemplate *KVRocksPodTemplate `json:"podTemplate,omitempty"`
+	// WorkloadBackend manages the kvrocks pods with OpenKruise or native statefulSets, the default is set by the
+	// operator. It can not be changed after the kvrocks is created
+	// +kubebuilder:validation:Enum=Kruise;StatefulSet
+	// +optional
+	WorkloadBackend WorkloadBackendType `json:"workloadBackend,omitempty"`
+	// Expose makes kvrocks reachable from outside the kubernetes cluster
+	// +optional
+	Expose *KVRocksExpose `json:"expose,omitempty"`
+	// MaxReplicationLag is the max replication offset a slave can lag behind its master and still serve reads
+	// from the read service, 1048576 by default
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxReplicationLag *int64 `json:"maxReplicationLag,omitempty"`
+	// Reseed rebuilds the slaves which are out of sync with their masters for too long, it is disabled if not set
+	// +optional
+	Reseed *KVRocksReseed `json:"reseed,omitempty"`
+}
+
+// KVRocksReseed rebuilds a slave from its master by a Resync KVRocksOperation, the slave is wiped and full synced
+type KVRocksReseed struct {
+	// OutOfSyncTimeout is how long a slave can be out of sync since it was last in sync, or since it was first
+	// observed if it has never been in sync, before it is reseeded. A slave is not reseeded again within the timeout
+	OutOfSyncTimeout metav1.Duration `json:"outOfSyncTimeout"`
+}
+
+// KVRocksExpose exposes the master and read services, and in cluster mode a service for each pod whose
+// address is announced by the node
+type KVRocksExpose struct {
+	// +kubebuilder:validation:Enum=NodePort;LoadBalancer
+	Type corev1.ServiceType `json:"type"`
+	// Annotations are added to the exposed services, e.g. to configure the load balancer
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// KVRocksExporter is the metrics exporter sidecar, the image is set by the operator by default
+type KVRocksExporter struct {
+	// Enabled adds the exporter to the kvrocks pods, true by default
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+	// +optional
+	Image string `json:"image,omitempty"`
+	// +optional
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// ExtraArgs are appended to the args of the exporter
+	// +optional
+	ExtraArgs []string `json:"extraArgs,omitempty"`
+}
+
+type KVRocksMonitoring struct {
+	// PodMonitor scrapes the exporters of the kvrocks pods
+	// +optional
+	PodMonitor bool `json:"podMonitor,omitempty"`
+	// PrometheusRule alerts on missing masters, replication lag, uncovered slots, full disks and sentinel quorum lost
+	// +optional
+	PrometheusRule bool `json:"prometheusRule,omitempty"`
+	// Interval is the scrape interval, the one of Prometheus is used by default
+	// +optional
+	Interval string `json:"interval,omitempty"`
+	// DiskUsagePercent is the disk usage which fires the alert, 85 by default
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	DiskUsagePercent int32 `json:"diskUsagePercent,omitempty"`
+	// Labels are added to the PodMonitor and PrometheusRule besides the kvrocks labels, so that Prometheus can
+	// select them
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// KVRocksSentinelTemplate is the spec of the sentinel created for the kvrocks, the sentinel image is set by the
+// operator or the kvrocks image by default
+type KVRocksSentinelTemplate struct {
+	// +optional
+	Image string `json:"image,omitempty"`
+	// +optional
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// KVRocksPodTemplate is overlaid on the pod templates generated by the operator
+type KVRocksPodTemplate struct {
+	// Labels are added to the pods, they can not override the labels set by the operator
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// +optional
+	SchedulerName string `json:"schedulerName,omitempty"`
+	// HostNetwork runs the pods in the host network, which is forbidden by the baseline and restricted pod security
+	// +optional
+	HostNetwork bool `json:"hostNetwork,omitempty"`
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// Restricted sets the default security contexts which pass the restricted pod security: the pods run as the
+	// non-root user 999 with the RuntimeDefault seccomp profile, and the containers disallow the privilege escalation
+	// and drop all capabilities. The images must be runnable as the user 999
+	// +optional
+	Restricted bool `json:"restricted,omitempty"`
+	// SecurityContext is set to the pods, it replaces the default one of Restricted
+	// +optional
+	SecurityContext *corev1.PodSecurityContext `json:"securityContext,omitempty"`
+	// ContainerSecurityContext is set to the containers without security context, it replaces the default one of
+	// Restricted
+	// +optional
+	ContainerSecurityContext *corev1.SecurityContext `json:"containerSecurityContext,omitempty"`
+	// Volumes are added to the pods, and mounted to the main container by VolumeMounts
+	// +optional
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+	// +optional
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+	// Env is added to the main container
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// InitContainers run before the containers of the pods. The schema is not generated, the containers are validated
+	// when the pods are created
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	InitContainers []corev1.Container `json:"initContainers,omitempty"`
+}
+
+type ZonePlacementPolicy string
+
+const (
+	// PreferredZonePlacement schedules the replicas of a shard to different zones if possible
+	PreferredZonePlacement ZonePlacementPolicy = "Preferred"
+	// RequiredZonePlacement never schedules two replicas of a shard to the same zone
+	RequiredZonePlacement ZonePlacementPolicy = "Required"
+)
+
+// KVRocksPlacement is the zone-aware placement of the pods
+type KVRocksPlacement struct {
+	// ZoneKey is the node label of the zone, topology.kubernetes.io/zone by default
+	// +optional
+	ZoneKey string `json:"zoneKey,omitempty"`
+	// Policy decides whether the replicas of a shard should (Preferred, default) or must (Required) be in different zones
+	// +kubebuilder:validation:Enum=Preferred;Required
+	// +optional
+	Policy ZonePlacementPolicy `json:"policy,omitempty"`
+	// RebalanceMasters switches over the shards in cluster mode, so that the masters are spread across zones
+	// again after failover
+	// +optional
+	RebalanceMasters bool `json:"rebalanceMasters,omitempty"`
 }
 
 // KVRocksStatus defines the observed state of KVRocks
@@ -51,11 +228,66 @@ type KVRocksStatus struct {
 	Rebalance bool                    `json:"rebalance,omitempty"`
 	Topo      []KVRocksTopoPartitions `json:"topo,omitempty"`
 	Shrink    *KVRocksShrinkMsg       `json:"shrink,omitempty"`
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// AppliedConfig is the online changeable config last applied to all nodes
+	AppliedConfig map[string]string `json:"appliedConfig,omitempty"`
+	// NodeConfig reports the config convergence of each node
+	NodeConfig []KVRocksNodeConfig `json:"nodeConfig,omitempty"`
+	// StorageSize is the volume size expanded by the storage autoscale, it overrides a smaller spec.storage.size
+	StorageSize *resource.Quantity `json:"storageSize,omitempty"`
+	// WorkloadBackend is the backend which the statefulSets are created with
+	WorkloadBackend WorkloadBackendType `json:"workloadBackend,omitempty"`
+	// Volumes reports the expansion progress of each pod's volume while the storage size grows
+	Volumes []KVRocksVolumeStatus `json:"volumes,omitempty"`
+	// Endpoints are the addresses for writes and reads
+	Endpoints *KVRocksEndpoints `json:"endpoints,omitempty"`
+	// Binding is the secret with the connection info, it follows the Service Binding spec
+	Binding *corev1.LocalObjectReference `json:"binding,omitempty"`
+	// Nodes are the kvrocks nodes in standard mode, the nodes in cluster mode are in topo
+	Nodes []KVRocksTopology `json:"nodes,omitempty"`
+	// ReplicationLag is the max lag of the slaves behind their masters
+	ReplicationLag int64 `json:"replicationLag,omitempty"`
+	// Monitors are the masters monitored by the sentinels in sentinel mode
+	Monitors []KVRocksSentinelMonitor `json:"monitors,omitempty"`
+}
+
+// KVRocksSentinelMonitor is a master monitored by the sentinels and the address each sentinel reports
+type KVRocksSentinelMonitor struct {
+	// Name is the master name in sentinel
+	Name string `json:"name"`
+	// Expected is the address of the master found by the operator
+	Expected string `json:"expected,omitempty"`
+	// Master is the master address reported by the most sentinels
+	Master string `json:"master,omitempty"`
+	// Agreed is the number of the sentinels reporting the master
+	Agreed int `json:"agreed"`
+	// Sentinels are the master addresses reported by each sentinel pod
+	Sentinels []KVRocksSentinelView `json:"sentinels,omitempty"`
+	// Disagreement describes the sentinels disagreeing with the master or the master disagreeing with the expected
+	Disagreement string `json:"disagreement,omitempty"`
+}
+
+type KVRocksSentinelView struct {
+	Pod    string `json:"pod"`
+	Master string `json:"master,omitempty"`
+	// Error is set if the sentinel can not be queried
+	Error string `json:"error,omitempty"`
+}
+
+type KVRocksEndpoints struct {
+	// Write is the address of the master service
+	Write string `json:"write"`
+	// Read is the address of the read service, which selects the slaves not lagging behind their masters
+	Read string `json:"read"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.status`
 //+kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.reason`
+//+kubebuilder:printcolumn:name="Master",type=string,JSONPath=`.status.nodes[?(@.role=="master")].pod`,priority=1
+//+kubebuilder:printcolumn:name="Replication",type=string,JSONPath=`.status.conditions[?(@.type=="ReplicationHealthy")].reason`
+//+kubebuilder:printcolumn:name="Lag",type=integer,JSONPath=`.status.replicationLag`
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="CreationTimestamp is a timestamp representing the server time when this object was created. It is not guaranteed to be set in happens-before order across separate operations. Clients may not set this value. It is represented in RFC3339 form and is in UTC."
 
 // KVRocks is the Schema for the kvrocks API
@@ -97,6 +329,33 @@ type KVRocksTopology struct {
 	MasterId string       `json:"masterId,omitempty"`
 	Migrate  []MigrateMsg `json:"migrate,omitempty"`
 	Failover bool         `json:"failover,omitempty"`
+	// Announce is the external address of the node in host:port, which is announced by the node. CLUSTER NODES and
+	// MOVED still return the address registered in the controller
+	Announce string `json:"announce,omitempty"`
+	// Replication is the replication health of the node
+	Replication *KVRocksReplication `json:"replication,omitempty"`
+}
+
+// KVRocksReplication is the replication health of a node observed by the operator
+type KVRocksReplication struct {
+	// LinkStatus is the master_link_status of a slave, up or down
+	LinkStatus string `json:"linkStatus,omitempty"`
+	// Offset is the replication offset of the node
+	Offset int64 `json:"offset"`
+	// Lag is how far the offset of a slave is behind its master
+	Lag int64 `json:"lag,omitempty"`
+	// LagSeconds is how long the slave has been out of sync with its master, since the last sync time or the first
+	// observed time if it has never been in sync
+	LagSeconds int64 `json:"lagSeconds,omitempty"`
+	// LastSyncTime is the last time the slave was observed in sync with its master
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+	// FirstObservedTime is when a slave never in sync was first observed out of sync
+	FirstObservedTime *metav1.Time `json:"firstObservedTime,omitempty"`
+	// SyncInProgress is true while the slave is doing a full sync
+	SyncInProgress bool `json:"syncInProgress,omitempty"`
+	// ObservedTime is when the replication was observed, the offsets are refreshed at most once per refresh
+	// interval unless the health changes
+	ObservedTime metav1.Time `json:"observedTime"`
 }
 
 type MigrateMsg struct {
@@ -104,11 +363,90 @@ type MigrateMsg struct {
 	Slots []string `json:"slots"`
 }
 
+type KVRocksNodeConfig struct {
+	Pod       string `json:"pod"`
+	Converged bool   `json:"converged"`
+	// Persisted is false if the live config could not be written back to the config file by CONFIG REWRITE
+	Persisted bool `json:"persisted"`
+	// Drifted lists the keys changed by CONFIG SET outside the operator
+	Drifted []string `json:"drifted,omitempty"`
+	Message string   `json:"message,omitempty"`
+}
+
+type KVRocksVolumeStatus struct {
+	Pod      string `json:"pod"`
+	Capacity string `json:"capacity,omitempty"`
+	Resizing bool   `json:"resizing"`
+	Message  string `json:"message,omitempty"`
+}
+
 type KVRocksStorage struct {
 	Size  resource.Quantity `json:"size"`
 	Class string            `json:"class"`
+	// AutoScale expands the volumes when the disk usage reaches the threshold
+	// +optional
+	AutoScale *KVRocksStorageAutoScale `json:"autoScale,omitempty"`
+	// RetentionPolicy controls whether the pvcs are deleted when they are not used any more
+	// +optional
+	RetentionPolicy *KVRocksPVCRetentionPolicy `json:"retentionPolicy,omitempty"`
+}
+
+type PVCRetentionPolicyType string
+
+const (
+	RetainPVCRetentionPolicy PVCRetentionPolicyType = "Retain"
+	DeletePVCRetentionPolicy PVCRetentionPolicyType = "Delete"
+)
+
+type FailoverPVCPolicyType string
+
+const (
+	// DeleteFailoverPVCPolicy always replaces the pvc of a failed pod
+	DeleteFailoverPVCPolicy FailoverPVCPolicyType = "Delete"
+	// DeleteOnNodeLossFailoverPVCPolicy replaces the pvc of a failed pod only if it is bound to a local volume on a lost node
+	DeleteOnNodeLossFailoverPVCPolicy FailoverPVCPolicyType = "DeleteOnNodeLoss"
+)
+
+type KVRocksPVCRetentionPolicy struct {
+	// WhenScaled is applied to the pvcs of the pods removed by scaling down
+	// +kubebuilder:validation:Enum=Retain;Delete
+	// +kubebuilder:default=Delete
+	// +optional
+	WhenScaled PVCRetentionPolicyType `json:"whenScaled,omitempty"`
+	// WhenDeleted is applied to the pvcs when the kvrocks is deleted
+	// +kubebuilder:validation:Enum=Retain;Delete
+	// +kubebuilder:default=Delete
+	// +optional
+	WhenDeleted PVCRetentionPolicyType `json:"whenDeleted,omitempty"`
+	// WhenFailover is applied to the pvc of a failed pod which is replaced
+	// +kubebuilder:validation:Enum=Delete;DeleteOnNodeLoss
+	// +kubebuilder:default=Delete
+	// +optional
+	WhenFailover FailoverPVCPolicyType `json:"whenFailover,omitempty"`
+}
+
+type KVRocksStorageAutoScale struct {
+	// ThresholdPercent is the disk usage percent which triggers the expansion
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=99
+	// +kubebuilder:default=80
+	// +optional
+	ThresholdPercent int32 `json:"thresholdPercent,omitempty"`
+	// Step is the size added to the volumes at each expansion
+	Step resource.Quantity `json:"step"`
+	// MaxSize is the upper bound of the volume size
+	MaxSize resource.Quantity `json:"maxSize"`
 }
 
+type WorkloadBackendType string
+
+const (
+	// KruiseWorkloadBackend uses the OpenKruise advanced statefulSet
+	KruiseWorkloadBackend WorkloadBackendType = "Kruise"
+	// StatefulSetWorkloadBackend uses the native apps/v1 statefulSet
+	StatefulSetWorkloadBackend WorkloadBackendType = "StatefulSet"
+)
+
 type KVRocksType string
 
 const (
@@ -117,6 +455,13 @@ const (
 	ClusterType  KVRocksType = "cluster"
 )
 
+type ConfigDriftPolicy string
+
+const (
+	ConfigDriftReset  ConfigDriftPolicy = "Reset"
+	ConfigDriftReport ConfigDriftPolicy = "Report"
+)
+
 type KVRocksStatusType string
 
 const (
@@ -126,6 +471,28 @@ const (
 	StatusFailed   KVRocksStatusType = "Failed"
 )
 
+const (
+	// ConditionPendingRestart is true while pods are being restarted to apply configs which can not be changed online
+	ConditionPendingRestart = "PendingRestart"
+	// ConditionConfigConverged is true when the live config of all nodes matches the spec
+	ConditionConfigConverged = "ConfigConverged"
+	// ConditionConfigValid is false when kvrocksConfig or rocksDBConfig contain unknown keys or invalid values
+	ConditionConfigValid = "ConfigValid"
+	// ConditionStorageResizing is true while the volumes are being expanded to spec.storage.size
+	ConditionStorageResizing = "StorageResizing"
+	// ConditionStorageMaxSizeReached is true when the disk usage reaches the autoscale threshold at the max size
+	ConditionStorageMaxSizeReached = "StorageMaxSizeReached"
+	// ConditionReplicationHealthy is true when all slaves are linked to their masters and not lagging behind
+	ConditionReplicationHealthy = "ReplicationHealthy"
+	// ConditionSentinelsAgreed is true when all sentinels report the expected master of each monitor
+	ConditionSentinelsAgreed = "SentinelsAgreed"
+	// ConditionPaused is true while the reconciliation is paused by the kvrocks.apache.org/paused annotation
+	ConditionPaused = "Paused"
+	// ConditionMaintenance is true while failover, pvc deletion and scaling are suppressed by the
+	// kvrocks.apache.org/maintenance annotation
+	ConditionMaintenance = "Maintenance"
+)
+
 const KVRocksFinalizer = "kvrocks/finalizer"
 
 func init() {
@@ -0,0 +1,107 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+func (r *KVRocks) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-kvrocks-apache-org-v1alpha1-kvrocks,mutating=false,failurePolicy=fail,sideEffects=None,groups=kvrocks.apache.org,resources=kvrocks,verbs=create;update,versions=v1alpha1,name=vkvrocks.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &KVRocks{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *KVRocks) ValidateCreate() error {
+	if err := r.validateStorage(); err != nil {
+		return err
+	}
+	return r.validateConfigs()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
+// The operator updates the whole kvrocks for its status and finalizer, so only the changed fields are validated,
+// and nothing is validated once it is being deleted
+func (r *KVRocks) ValidateUpdate(old runtime.Object) error {
+	if r.DeletionTimestamp != nil {
+		return nil
+	}
+	oldKVRocks, ok := old.(*KVRocks)
+	if !ok {
+		return r.ValidateCreate()
+	}
+	if oldKVRocks.Spec.Storage != nil && (r.Spec.Storage == nil || r.Spec.Storage.Size.Cmp(oldKVRocks.Spec.Storage.Size) < 0) {
+		return fmt.Errorf("storage size can not be decreased")
+	}
+	if backend := oldKVRocks.Status.WorkloadBackend; backend != "" && r.Spec.WorkloadBackend != oldKVRocks.Spec.WorkloadBackend &&
+		r.Spec.WorkloadBackend != "" && r.Spec.WorkloadBackend != backend {
+		return fmt.Errorf("workloadBackend can not be changed from %s", backend)
+	}
+	if !equality.Semantic.DeepEqual(r.Spec.Storage, oldKVRocks.Spec.Storage) {
+		if err := r.validateStorage(); err != nil {
+			return err
+		}
+	}
+	// the configs are checked against the version of the image
+	if !reflect.DeepEqual(r.Spec.Configs(), oldKVRocks.Spec.Configs()) || r.Spec.Image != oldKVRocks.Spec.Image {
+		return r.validateConfigs()
+	}
+	return nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *KVRocks) ValidateDelete() error {
+	return nil
+}
+
+// validateStorage checks the storage autoscale policy
+func (r *KVRocks) validateStorage() error {
+	if r.Spec.Storage == nil || r.Spec.Storage.AutoScale == nil {
+		return nil
+	}
+	autoScale := r.Spec.Storage.AutoScale
+	if autoScale.Step.Sign() <= 0 {
+		return fmt.Errorf("storage autoScale step must be greater than 0")
+	}
+	if autoScale.MaxSize.Cmp(r.Spec.Storage.Size) < 0 {
+		return fmt.Errorf("storage autoScale maxSize must not be less than storage size")
+	}
+	return nil
+}
+
+// validateConfigs rejects the unknown or invalid kvrocks configs
+func (r *KVRocks) validateConfigs() error {
+	if r.Spec.Type == SentinelType {
+		return nil
+	}
+	if _, invalid := r.Spec.ValidateConfigs(); len(invalid) != 0 {
+		return fmt.Errorf("invalid kvrocks configs: %s", strings.Join(invalid, "; "))
+	}
+	return nil
+}
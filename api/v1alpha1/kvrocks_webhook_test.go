@@ -0,0 +1,100 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateUpdate(t *testing.T) {
+	now := metav1.Now()
+	newKVRocks := func(config map[string]string, size string) *KVRocks {
+		return &KVRocks{
+			Spec: KVRocksSpec{
+				Type:          StandardType,
+				Image:         "apache/kvrocks:2.5.0",
+				KVRocksConfig: config,
+				Storage:       &KVRocksStorage{Size: resource.MustParse(size)},
+			},
+		}
+	}
+	withBackend := func(r *KVRocks, spec, status WorkloadBackendType) *KVRocks {
+		r.Spec.WorkloadBackend = spec
+		r.Status.WorkloadBackend = status
+		return r
+	}
+
+	tests := []struct {
+		name   string
+		old    *KVRocks
+		new    *KVRocks
+		update func(r *KVRocks)
+		expErr bool
+	}{
+		{
+			name: "Unchanged invalid configs should not block the updates of the operator.",
+			old:  newKVRocks(map[string]string{"unknown": "1"}, "10Gi"),
+			new:  newKVRocks(map[string]string{"unknown": "1"}, "10Gi"),
+			update: func(r *KVRocks) {
+				r.Finalizers = []string{KVRocksFinalizer}
+				r.Status.Status = StatusRunning
+			},
+		}, {
+			name:   "Changed invalid configs should be rejected.",
+			old:    newKVRocks(map[string]string{"workers": "8"}, "10Gi"),
+			new:    newKVRocks(map[string]string{"workers": "0"}, "10Gi"),
+			expErr: true,
+		}, {
+			name:   "Changed valid configs should be accepted.",
+			old:    newKVRocks(map[string]string{"workers": "8"}, "10Gi"),
+			new:    newKVRocks(map[string]string{"workers": "16"}, "10Gi"),
+			expErr: false,
+		}, {
+			name: "Configs should be checked again if the image is changed.",
+			old:  newKVRocks(map[string]string{"resp3-enabled": "yes"}, "10Gi"),
+			new:  newKVRocks(map[string]string{"resp3-enabled": "yes"}, "10Gi"),
+			update: func(r *KVRocks) {
+				r.Spec.Image = "apache/kvrocks:2.6.0"
+				r.Spec.KVRocksConfig["unknown"] = "1"
+			},
+			expErr: true,
+		}, {
+			name:   "Decreasing the storage size should be rejected.",
+			old:    newKVRocks(nil, "10Gi"),
+			new:    newKVRocks(nil, "5Gi"),
+			expErr: true,
+		}, {
+			name:   "Changing the workload backend from the recorded one should be rejected.",
+			old:    withBackend(newKVRocks(nil, "10Gi"), "", KruiseWorkloadBackend),
+			new:    withBackend(newKVRocks(nil, "10Gi"), StatefulSetWorkloadBackend, KruiseWorkloadBackend),
+			expErr: true,
+		}, {
+			name: "An unchanged workload backend should not block the updates of the operator.",
+			old:  withBackend(newKVRocks(nil, "10Gi"), StatefulSetWorkloadBackend, KruiseWorkloadBackend),
+			new:  withBackend(newKVRocks(nil, "10Gi"), StatefulSetWorkloadBackend, KruiseWorkloadBackend),
+		}, {
+			name: "Removing the finalizer of a deleting kvrocks should be accepted.",
+			old:  newKVRocks(map[string]string{"unknown": "1"}, "10Gi"),
+			new:  newKVRocks(map[string]string{"unknown": "2"}, "10Gi"),
+			update: func(r *KVRocks) {
+				r.DeletionTimestamp = &now
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.update != nil {
+				test.update(test.new)
+			}
+			err := test.new.ValidateUpdate(test.old)
+			if test.expErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
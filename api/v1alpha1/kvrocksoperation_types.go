@@ -0,0 +1,116 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type KVRocksOperationType string
+
+const (
+	// SwitchoverOperation promotes the slave in spec.pod, or the most up-to-date slave, with the master still alive
+	SwitchoverOperation KVRocksOperationType = "Switchover"
+	// FailoverOperation lets sentinel or the controller elect a new master
+	FailoverOperation KVRocksOperationType = "Failover"
+	// RestartPodOperation restarts the pod, a master is switched over first
+	RestartPodOperation KVRocksOperationType = "RestartPod"
+	// ReplaceNodeOperation recreates the pod with a new pvc by the failover policy, a master is switched over first
+	ReplaceNodeOperation KVRocksOperationType = "ReplaceNode"
+	// ResyncOperation wipes the data of a slave and lets it full sync from its master
+	ResyncOperation KVRocksOperationType = "Resync"
+)
+
+type KVRocksOperationPhase string
+
+const (
+	OperationPending   KVRocksOperationPhase = "Pending"
+	OperationRunning   KVRocksOperationPhase = "Running"
+	OperationSucceeded KVRocksOperationPhase = "Succeeded"
+	OperationFailed    KVRocksOperationPhase = "Failed"
+)
+
+// KVRocksOperationSpec defines the operation on a kvrocks
+type KVRocksOperationSpec struct {
+	// Instance is the name of the kvrocks in the same namespace
+	Instance string `json:"instance"`
+	// +kubebuilder:validation:Enum=Switchover;Failover;RestartPod;ReplaceNode;Resync
+	Type KVRocksOperationType `json:"type"`
+	// Shard is the shard index in cluster mode, it is found by spec.pod if not set
+	// +optional
+	Shard *int `json:"shard,omitempty"`
+	// Pod is the target pod, the slave to promote for Switchover, or the pod to restart, replace or resync
+	// +optional
+	Pod string `json:"pod,omitempty"`
+	// Force skips the pre-checks, such as the slave to promote being in sync with its master
+	// +optional
+	Force bool `json:"force,omitempty"`
+}
+
+// KVRocksOperationStatus defines the observed state of KVRocksOperation
+type KVRocksOperationStatus struct {
+	Phase KVRocksOperationPhase `json:"phase,omitempty"`
+	// Step is the current step of the operation
+	Step    string `json:"step,omitempty"`
+	Message string `json:"message,omitempty"`
+	// PreviousMaster is the master pod when the operation started
+	// +optional
+	PreviousMaster string `json:"previousMaster,omitempty"`
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// StepTime is when the current step started
+	// +optional
+	StepTime *metav1.Time `json:"stepTime,omitempty"`
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:shortName=kvop
+//+kubebuilder:printcolumn:name="Instance",type=string,JSONPath=`.spec.instance`
+//+kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+//+kubebuilder:printcolumn:name="Pod",type=string,JSONPath=`.spec.pod`
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Step",type=string,JSONPath=`.status.step`,priority=1
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// KVRocksOperation is an operation on a kvrocks, it is run by the kvrocks reconciler one step per reconcile
+type KVRocksOperation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KVRocksOperationSpec   `json:"spec,omitempty"`
+	Status KVRocksOperationStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// KVRocksOperationList contains a list of KVRocksOperation
+type KVRocksOperationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KVRocksOperation `json:"items"`
+}
+
+// IsFinished checks if the operation succeeded or failed
+func (op *KVRocksOperation) IsFinished() bool {
+	return op.Status.Phase == OperationSucceeded || op.Status.Phase == OperationFailed
+}
+
+func init() {
+	SchemeBuilder.Register(&KVRocksOperation{}, &KVRocksOperationList{})
+}
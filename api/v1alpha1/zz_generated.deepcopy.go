@@ -23,7 +23,8 @@ package v1alpha1
 
 import (
 	"k8s.io/api/core/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -53,6 +54,73 @@ func (in *KVRocks) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KVRocksEndpoints) DeepCopyInto(out *KVRocksEndpoints) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVRocksEndpoints.
+func (in *KVRocksEndpoints) DeepCopy() *KVRocksEndpoints {
+	if in == nil {
+		return nil
+	}
+	out := new(KVRocksEndpoints)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KVRocksExporter) DeepCopyInto(out *KVRocksExporter) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExtraArgs != nil {
+		in, out := &in.ExtraArgs, &out.ExtraArgs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVRocksExporter.
+func (in *KVRocksExporter) DeepCopy() *KVRocksExporter {
+	if in == nil {
+		return nil
+	}
+	out := new(KVRocksExporter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KVRocksExpose) DeepCopyInto(out *KVRocksExpose) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVRocksExpose.
+func (in *KVRocksExpose) DeepCopy() *KVRocksExpose {
+	if in == nil {
+		return nil
+	}
+	out := new(KVRocksExpose)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KVRocksList) DeepCopyInto(out *KVRocksList) {
 	*out = *in
@@ -85,6 +153,351 @@ func (in *KVRocksList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KVRocksMonitoring) DeepCopyInto(out *KVRocksMonitoring) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVRocksMonitoring.
+func (in *KVRocksMonitoring) DeepCopy() *KVRocksMonitoring {
+	if in == nil {
+		return nil
+	}
+	out := new(KVRocksMonitoring)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KVRocksNodeConfig) DeepCopyInto(out *KVRocksNodeConfig) {
+	*out = *in
+	if in.Drifted != nil {
+		in, out := &in.Drifted, &out.Drifted
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVRocksNodeConfig.
+func (in *KVRocksNodeConfig) DeepCopy() *KVRocksNodeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(KVRocksNodeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KVRocksOperation) DeepCopyInto(out *KVRocksOperation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVRocksOperation.
+func (in *KVRocksOperation) DeepCopy() *KVRocksOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(KVRocksOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KVRocksOperation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KVRocksOperationList) DeepCopyInto(out *KVRocksOperationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KVRocksOperation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVRocksOperationList.
+func (in *KVRocksOperationList) DeepCopy() *KVRocksOperationList {
+	if in == nil {
+		return nil
+	}
+	out := new(KVRocksOperationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KVRocksOperationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KVRocksOperationSpec) DeepCopyInto(out *KVRocksOperationSpec) {
+	*out = *in
+	if in.Shard != nil {
+		in, out := &in.Shard, &out.Shard
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVRocksOperationSpec.
+func (in *KVRocksOperationSpec) DeepCopy() *KVRocksOperationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KVRocksOperationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KVRocksOperationStatus) DeepCopyInto(out *KVRocksOperationStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.StepTime != nil {
+		in, out := &in.StepTime, &out.StepTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVRocksOperationStatus.
+func (in *KVRocksOperationStatus) DeepCopy() *KVRocksOperationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KVRocksOperationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KVRocksPVCRetentionPolicy) DeepCopyInto(out *KVRocksPVCRetentionPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVRocksPVCRetentionPolicy.
+func (in *KVRocksPVCRetentionPolicy) DeepCopy() *KVRocksPVCRetentionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(KVRocksPVCRetentionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KVRocksPlacement) DeepCopyInto(out *KVRocksPlacement) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVRocksPlacement.
+func (in *KVRocksPlacement) DeepCopy() *KVRocksPlacement {
+	if in == nil {
+		return nil
+	}
+	out := new(KVRocksPlacement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KVRocksPodTemplate) DeepCopyInto(out *KVRocksPodTemplate) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(v1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ContainerSecurityContext != nil {
+		in, out := &in.ContainerSecurityContext, &out.ContainerSecurityContext
+		*out = new(v1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]v1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]v1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InitContainers != nil {
+		in, out := &in.InitContainers, &out.InitContainers
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVRocksPodTemplate.
+func (in *KVRocksPodTemplate) DeepCopy() *KVRocksPodTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(KVRocksPodTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KVRocksReplication) DeepCopyInto(out *KVRocksReplication) {
+	*out = *in
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.FirstObservedTime != nil {
+		in, out := &in.FirstObservedTime, &out.FirstObservedTime
+		*out = (*in).DeepCopy()
+	}
+	in.ObservedTime.DeepCopyInto(&out.ObservedTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVRocksReplication.
+func (in *KVRocksReplication) DeepCopy() *KVRocksReplication {
+	if in == nil {
+		return nil
+	}
+	out := new(KVRocksReplication)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KVRocksReseed) DeepCopyInto(out *KVRocksReseed) {
+	*out = *in
+	out.OutOfSyncTimeout = in.OutOfSyncTimeout
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVRocksReseed.
+func (in *KVRocksReseed) DeepCopy() *KVRocksReseed {
+	if in == nil {
+		return nil
+	}
+	out := new(KVRocksReseed)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KVRocksSentinelMonitor) DeepCopyInto(out *KVRocksSentinelMonitor) {
+	*out = *in
+	if in.Sentinels != nil {
+		in, out := &in.Sentinels, &out.Sentinels
+		*out = make([]KVRocksSentinelView, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVRocksSentinelMonitor.
+func (in *KVRocksSentinelMonitor) DeepCopy() *KVRocksSentinelMonitor {
+	if in == nil {
+		return nil
+	}
+	out := new(KVRocksSentinelMonitor)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KVRocksSentinelTemplate) DeepCopyInto(out *KVRocksSentinelTemplate) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVRocksSentinelTemplate.
+func (in *KVRocksSentinelTemplate) DeepCopy() *KVRocksSentinelTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(KVRocksSentinelTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KVRocksSentinelView) DeepCopyInto(out *KVRocksSentinelView) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVRocksSentinelView.
+func (in *KVRocksSentinelView) DeepCopy() *KVRocksSentinelView {
+	if in == nil {
+		return nil
+	}
+	out := new(KVRocksSentinelView)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KVRocksShrinkMsg) DeepCopyInto(out *KVRocksShrinkMsg) {
 	*out = *in
@@ -130,6 +543,11 @@ func (in *KVRocksSpec) DeepCopyInto(out *KVRocksSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.RocksDBConfig != nil {
+		in, out := &in.RocksDBConfig, &out.RocksDBConfig
+		*out = new(RocksDBConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Resources != nil {
 		in, out := &in.Resources, &out.Resources
 		*out = new(v1.ResourceRequirements)
@@ -154,11 +572,58 @@ func (in *KVRocksSpec) DeepCopyInto(out *KVRocksSpec) {
 		*out = new(v1.Affinity)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Placement != nil {
+		in, out := &in.Placement, &out.Placement
+		*out = new(KVRocksPlacement)
+		**out = **in
+	}
 	if in.Storage != nil {
 		in, out := &in.Storage, &out.Storage
 		*out = new(KVRocksStorage)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Exporter != nil {
+		in, out := &in.Exporter, &out.Exporter
+		*out = new(KVRocksExporter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SentinelTemplate != nil {
+		in, out := &in.SentinelTemplate, &out.SentinelTemplate
+		*out = new(KVRocksSentinelTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(KVRocksMonitoring)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(KVRocksPodTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Expose != nil {
+		in, out := &in.Expose, &out.Expose
+		*out = new(KVRocksExpose)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxReplicationLag != nil {
+		in, out := &in.MaxReplicationLag, &out.MaxReplicationLag
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Reseed != nil {
+		in, out := &in.Reseed, &out.Reseed
+		*out = new(KVRocksReseed)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVRocksSpec.
@@ -186,6 +651,61 @@ func (in *KVRocksStatus) DeepCopyInto(out *KVRocksStatus) {
 		*out = new(KVRocksShrinkMsg)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AppliedConfig != nil {
+		in, out := &in.AppliedConfig, &out.AppliedConfig
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NodeConfig != nil {
+		in, out := &in.NodeConfig, &out.NodeConfig
+		*out = make([]KVRocksNodeConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StorageSize != nil {
+		in, out := &in.StorageSize, &out.StorageSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]KVRocksVolumeStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = new(KVRocksEndpoints)
+		**out = **in
+	}
+	if in.Binding != nil {
+		in, out := &in.Binding, &out.Binding
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]KVRocksTopology, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Monitors != nil {
+		in, out := &in.Monitors, &out.Monitors
+		*out = make([]KVRocksSentinelMonitor, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVRocksStatus.
@@ -202,6 +722,16 @@ func (in *KVRocksStatus) DeepCopy() *KVRocksStatus {
 func (in *KVRocksStorage) DeepCopyInto(out *KVRocksStorage) {
 	*out = *in
 	out.Size = in.Size.DeepCopy()
+	if in.AutoScale != nil {
+		in, out := &in.AutoScale, &out.AutoScale
+		*out = new(KVRocksStorageAutoScale)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RetentionPolicy != nil {
+		in, out := &in.RetentionPolicy, &out.RetentionPolicy
+		*out = new(KVRocksPVCRetentionPolicy)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVRocksStorage.
@@ -214,6 +744,23 @@ func (in *KVRocksStorage) DeepCopy() *KVRocksStorage {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KVRocksStorageAutoScale) DeepCopyInto(out *KVRocksStorageAutoScale) {
+	*out = *in
+	out.Step = in.Step.DeepCopy()
+	out.MaxSize = in.MaxSize.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVRocksStorageAutoScale.
+func (in *KVRocksStorageAutoScale) DeepCopy() *KVRocksStorageAutoScale {
+	if in == nil {
+		return nil
+	}
+	out := new(KVRocksStorageAutoScale)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KVRocksTopoPartitions) DeepCopyInto(out *KVRocksTopoPartitions) {
 	*out = *in
@@ -251,6 +798,11 @@ func (in *KVRocksTopology) DeepCopyInto(out *KVRocksTopology) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Replication != nil {
+		in, out := &in.Replication, &out.Replication
+		*out = new(KVRocksReplication)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVRocksTopology.
@@ -263,6 +815,21 @@ func (in *KVRocksTopology) DeepCopy() *KVRocksTopology {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KVRocksVolumeStatus) DeepCopyInto(out *KVRocksVolumeStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVRocksVolumeStatus.
+func (in *KVRocksVolumeStatus) DeepCopy() *KVRocksVolumeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KVRocksVolumeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MigrateMsg) DeepCopyInto(out *MigrateMsg) {
 	*out = *in
@@ -282,3 +849,103 @@ func (in *MigrateMsg) DeepCopy() *MigrateMsg {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RocksDBConfig) DeepCopyInto(out *RocksDBConfig) {
+	*out = *in
+	if in.BlockSize != nil {
+		in, out := &in.BlockSize, &out.BlockSize
+		*out = new(int64)
+		**out = **in
+	}
+	if in.BlockCacheSizeMB != nil {
+		in, out := &in.BlockCacheSizeMB, &out.BlockCacheSizeMB
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RowCacheSizeMB != nil {
+		in, out := &in.RowCacheSizeMB, &out.RowCacheSizeMB
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxOpenFiles != nil {
+		in, out := &in.MaxOpenFiles, &out.MaxOpenFiles
+		*out = new(int32)
+		**out = **in
+	}
+	if in.WriteBufferSizeMB != nil {
+		in, out := &in.WriteBufferSizeMB, &out.WriteBufferSizeMB
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxWriteBufferNumber != nil {
+		in, out := &in.MaxWriteBufferNumber, &out.MaxWriteBufferNumber
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetFileSizeBaseMB != nil {
+		in, out := &in.TargetFileSizeBaseMB, &out.TargetFileSizeBaseMB
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxBackgroundCompactions != nil {
+		in, out := &in.MaxBackgroundCompactions, &out.MaxBackgroundCompactions
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxBackgroundFlushes != nil {
+		in, out := &in.MaxBackgroundFlushes, &out.MaxBackgroundFlushes
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxSubCompactions != nil {
+		in, out := &in.MaxSubCompactions, &out.MaxSubCompactions
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Level0SlowdownWritesTrigger != nil {
+		in, out := &in.Level0SlowdownWritesTrigger, &out.Level0SlowdownWritesTrigger
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Level0StopWritesTrigger != nil {
+		in, out := &in.Level0StopWritesTrigger, &out.Level0StopWritesTrigger
+		*out = new(int32)
+		**out = **in
+	}
+	if in.WalTTLSeconds != nil {
+		in, out := &in.WalTTLSeconds, &out.WalTTLSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.WalSizeLimitMB != nil {
+		in, out := &in.WalSizeLimitMB, &out.WalSizeLimitMB
+		*out = new(int32)
+		**out = **in
+	}
+	if in.CacheIndexAndFilterBlocks != nil {
+		in, out := &in.CacheIndexAndFilterBlocks, &out.CacheIndexAndFilterBlocks
+		*out = new(bool)
+		**out = **in
+	}
+	if in.EnablePipelinedWrite != nil {
+		in, out := &in.EnablePipelinedWrite, &out.EnablePipelinedWrite
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DisableAutoCompactions != nil {
+		in, out := &in.DisableAutoCompactions, &out.DisableAutoCompactions
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RocksDBConfig.
+func (in *RocksDBConfig) DeepCopy() *RocksDBConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RocksDBConfig)
+	in.DeepCopyInto(out)
+	return out
+}
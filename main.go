@@ -19,6 +19,7 @@ package main
 import (
 	"flag"
 	"os"
+	"time"
 
 	"go.uber.org/zap/zapcore"
 	v1 "k8s.io/api/core/v1"
@@ -37,7 +38,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/workload"
 	"github.com/RocksLabs/kvrocks-operator/pkg/controllers"
+	"github.com/RocksLabs/kvrocks-operator/pkg/controllers/common"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -59,6 +63,9 @@ func main() {
 	var probeAddr string
 	var maxConcurrentReconciles int
 	var managerNamespace string
+	var enableWebhook bool
+	var workloadBackend string
+	var nodeLostTimeout time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
@@ -66,6 +73,23 @@ func main() {
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1, "The number of maximum concurrent reconciles.")
 	flag.StringVar(&managerNamespace, "manager-namespace", v1.NamespaceAll, "manage namespace")
+	flag.BoolVar(&enableWebhook, "enable-webhook", false, "Enable the validating webhook of kvrocks.")
+	flag.StringVar(&workloadBackend, "workload-backend", string(kvrocksv1alpha1.KruiseWorkloadBackend),
+		"The default workload backend of kvrocks, Kruise or StatefulSet.")
+	flag.StringVar(&resources.SentinelImage, "sentinel-image", resources.SentinelImage,
+		"The default image of the sentinels created for kvrocks, the kvrocks image is used if it is empty.")
+	flag.StringVar(&resources.ExporterImage, "exporter-image", resources.ExporterImage, "The default image of the kvrocks exporter.")
+	flag.StringVar(&resources.ControllerImage, "controller-image", resources.ControllerImage, "The image of the kvrocks controller.")
+	flag.StringVar(&resources.EtcdImage, "etcd-image", resources.EtcdImage, "The image of etcd used by the kvrocks controller.")
+	flag.StringVar(&resources.ClusterDomain, "cluster-domain", resources.ClusterDomain, "The dns domain of the kubernetes cluster.")
+	flag.DurationVar(&nodeLostTimeout, "node-lost-timeout", common.NodeLostTimeout,
+		"How long a node can be NotReady before its kvrocks pods are replaced on other nodes.")
+	flag.DurationVar(&common.StatusRefreshInterval, "status-refresh-interval", common.StatusRefreshInterval,
+		"How often the observed status of kvrocks such as the replication health is refreshed.")
+	flag.IntVar(&resources.HistoryLimit, "operation-history-limit", resources.HistoryLimit,
+		"The max number of the operations kept in the history configMap of each kvrocks.")
+	flag.DurationVar(&common.OperationTimeout, "operation-timeout", common.OperationTimeout,
+		"How long a KVRocksOperation can run before it is failed.")
 	opts := zap.Options{
 		Development: false,
 		Level:       zapcore.InfoLevel,
@@ -76,6 +100,16 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	switch kvrocksv1alpha1.WorkloadBackendType(workloadBackend) {
+	case kvrocksv1alpha1.KruiseWorkloadBackend, kvrocksv1alpha1.StatefulSetWorkloadBackend:
+		workload.DefaultBackend = kvrocksv1alpha1.WorkloadBackendType(workloadBackend)
+	default:
+		setupLog.Error(nil, "unknown workload backend", "backend", workloadBackend)
+		os.Exit(1)
+	}
+
+	common.NodeLostTimeout = nodeLostTimeout
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
@@ -91,13 +125,20 @@ func main() {
 	}
 
 	if err = (&controllers.KVRocksReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-		Log:    ctrl.Log,
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Log:      ctrl.Log,
+		Recorder: mgr.GetEventRecorderFor("kvrocks-operator"),
 	}).SetupWithManager(mgr, maxConcurrentReconciles); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "KVRocks")
 		os.Exit(1)
 	}
+	if enableWebhook {
+		if err = (&kvrocksv1alpha1.KVRocks{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "KVRocks")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
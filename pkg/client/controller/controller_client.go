@@ -35,6 +35,10 @@ type NodeOption struct {
 	Password string `json:"password"`
 }
 
+type FailoverOption struct {
+	PreferredNodeID string `json:"preferred_node_id"`
+}
+
 type MigrationOption struct {
 	Source int `json:"source"`
 	Target int `json:"target"`
@@ -71,6 +75,7 @@ type Client struct {
 	logger     logr.Logger
 	client     *http.Client
 	controller *Controller
+	record     kvrocks.OperationRecorder
 }
 
 func NewClient(logger logr.Logger) *Client {
@@ -86,6 +91,20 @@ func NewClient(logger logr.Logger) *Client {
 	}
 }
 
+// WithRecorder returns a copy of the client which passes the mutating requests to the recorder
+func (c *Client) WithRecorder(record kvrocks.OperationRecorder) *Client {
+	client := *c
+	client.record = record
+	return &client
+}
+
+// recordOperation records the request when the method returns, the passwords are never recorded
+func (c *Client) recordOperation(command string, err *error, args ...string) {
+	if c.record != nil {
+		c.record(kvrocks.NewOperation(kvrocks.ComponentController, c.controller.EndPoint, command, *err, args...))
+	}
+}
+
 func (c *Client) SetEndPoint(namespace string, k8s *k8s.Client) error {
 	service, err := k8s.GetService(types.NamespacedName{
 		Namespace: namespace,
@@ -98,7 +117,8 @@ func (c *Client) SetEndPoint(namespace string, k8s *k8s.Client) error {
 	return nil
 }
 
-func (c *Client) CreateIfNotExistsNamespace() error {
+func (c *Client) CreateIfNotExistsNamespace() (err error) {
+	defer c.recordOperation("CreateNamespace", &err, c.controller.Namespace)
 	resp, err := c.client.Post(c.controller.EndPoint+"/namespaces", "application/json", strings.NewReader(`{"namespace": "`+c.controller.Namespace+`"}`))
 	if err != nil {
 		return err
@@ -124,7 +144,8 @@ func (c *Client) CreateIfNotExistsNamespace() error {
 	return nil
 }
 
-func (c *Client) CreateCluster(replicas int, nodes []string, password string) error {
+func (c *Client) CreateCluster(replicas int, nodes []string, password string) (err error) {
+	defer c.recordOperation("CreateCluster", &err, append([]string{strconv.Itoa(replicas)}, nodes...)...)
 	clusterOption := &ClusterOption{
 		Name:     c.controller.ClusterName,
 		Replicas: replicas,
@@ -145,7 +166,8 @@ func (c *Client) CreateCluster(replicas int, nodes []string, password string) er
 	return nil
 }
 
-func (c *Client) CreateShard(nodes []string, password string) error {
+func (c *Client) CreateShard(nodes []string, password string) (err error) {
+	defer c.recordOperation("CreateShard", &err, nodes...)
 	shardOption := &ShardOption{
 		Nodes:    nodes,
 		Password: password,
@@ -194,7 +216,8 @@ func (c *Client) GetShards() ([]ShardData, error) {
 	return shardData, nil
 }
 
-func (c *Client) DeleteShard(shardIndex int) error {
+func (c *Client) DeleteShard(shardIndex int) (err error) {
+	defer c.recordOperation("DeleteShard", &err, strconv.Itoa(shardIndex))
 	req, err := http.NewRequest("DELETE", c.controller.EndPoint+"/namespaces/"+c.controller.Namespace+"/clusters/"+c.controller.ClusterName+"/shards/"+strconv.Itoa(shardIndex), nil)
 	if err != nil {
 		return err
@@ -239,7 +262,8 @@ func (c *Client) GetNodes(shardIndex int) (*ShardData, error) {
 	return &shardData, nil
 }
 
-func (c *Client) DeleteNode(shardIndex int, nodeID string) error {
+func (c *Client) DeleteNode(shardIndex int, nodeID string) (err error) {
+	defer c.recordOperation("DeleteNode", &err, strconv.Itoa(shardIndex), nodeID)
 	req, err := http.NewRequest("DELETE", c.controller.EndPoint+"/namespaces/"+c.controller.Namespace+"/clusters/"+c.controller.ClusterName+"/shards/"+strconv.Itoa(shardIndex)+"/nodes/"+nodeID, nil)
 	if err != nil {
 		return err
@@ -254,7 +278,8 @@ func (c *Client) DeleteNode(shardIndex int, nodeID string) error {
 	return nil
 }
 
-func (c *Client) AddNode(shardIndex int, addr, role, password string) error {
+func (c *Client) AddNode(shardIndex int, addr, role, password string) (err error) {
+	defer c.recordOperation("AddNode", &err, strconv.Itoa(shardIndex), addr, role)
 	nodeOption := &NodeOption{
 		Addr:     addr,
 		Role:     role,
@@ -274,7 +299,8 @@ func (c *Client) AddNode(shardIndex int, addr, role, password string) error {
 	return nil
 }
 
-func (c *Client) FailoverShard(shardIndex int) error {
+func (c *Client) FailoverShard(shardIndex int) (err error) {
+	defer c.recordOperation("FailoverShard", &err, strconv.Itoa(shardIndex))
 	resp, err := c.client.Post(c.controller.EndPoint+"/namespaces/"+c.controller.Namespace+"/clusters/"+c.controller.ClusterName+"/shards/"+strconv.Itoa(shardIndex)+"/failover", "application/json", nil)
 	if err != nil {
 		return err
@@ -285,7 +311,27 @@ func (c *Client) FailoverShard(shardIndex int) error {
 	return nil
 }
 
-func (c *Client) MigrateSlotAndData(source, target, slot int) error {
+// FailoverShardTo prefers the given slave of the shard to the one chosen by the controller, a controller without
+// the option ignores it and the promoted node should be checked
+func (c *Client) FailoverShardTo(shardIndex int, nodeID string) (err error) {
+	defer c.recordOperation("FailoverShard", &err, strconv.Itoa(shardIndex), nodeID)
+	failoverOptionJson, err := json.Marshal(&FailoverOption{PreferredNodeID: nodeID})
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Post(c.controller.EndPoint+"/namespaces/"+c.controller.Namespace+"/clusters/"+c.controller.ClusterName+"/shards/"+strconv.Itoa(shardIndex)+"/failover", "application/json", strings.NewReader(string(failoverOptionJson)))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.New("unexpected response status code: " + strconv.Itoa(resp.StatusCode))
+	}
+	return nil
+}
+
+func (c *Client) MigrateSlotAndData(source, target, slot int) (err error) {
+	// the slot is not recorded, so that the migrations between two shards are merged in the history
+	defer c.recordOperation("MigrateSlot", &err, strconv.Itoa(source), strconv.Itoa(target))
 	migrationOption := &MigrationOption{
 		Source: source,
 		Target: target,
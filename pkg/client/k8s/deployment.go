@@ -16,6 +16,28 @@ func (c *Client) CreateIfNotExistsDeployment(deployment *appsv1.Deployment) erro
 	return nil
 }
 
+// CreateDeploymentOrUpdateTemplate updates the deployment only if the pod template rendered by the operator is changed
+func (c *Client) CreateDeploymentOrUpdateTemplate(deployment *appsv1.Deployment) error {
+	oldDeployment, err := c.GetDeployment(types.NamespacedName{
+		Namespace: deployment.Namespace,
+		Name:      deployment.Name,
+	})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return c.CreateIfNotExistsDeployment(deployment)
+		}
+		return err
+	}
+	switch CompareTemplate(&oldDeployment.ObjectMeta, &deployment.ObjectMeta, oldDeployment.Spec.Template.Spec.Containers, deployment.Spec.Template.Spec.Containers) {
+	case TemplateSame:
+		return nil
+	case TemplateChanged:
+		oldDeployment.Spec.Template = deployment.Spec.Template
+	}
+	CopyTemplateHash(&oldDeployment.ObjectMeta, &deployment.ObjectMeta)
+	return c.UpdateDeployment(oldDeployment)
+}
+
 func (c *Client) GetDeployment(key types.NamespacedName) (*appsv1.Deployment, error) {
 	var deployment appsv1.Deployment
 	if err := c.client.Get(ctx, key, &deployment); err != nil {
@@ -14,6 +14,8 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	k8sApiClient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
 )
 
 func TestCreateIfNotExistsDeployment(t *testing.T) {
@@ -199,6 +201,101 @@ func TestUpdateDeployment(t *testing.T) {
 	}
 }
 
+func TestCreateDeploymentOrUpdateTemplate(t *testing.T) {
+	ns := "unit-test"
+	newDeployment := func(hash, image string, replicas int32, env ...corev1.EnvVar) *appsv1.Deployment {
+		dep := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test",
+				Namespace: ns,
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "sentinel", Image: image, Env: env}},
+					},
+				},
+			},
+		}
+		if hash != "" {
+			dep.Annotations = map[string]string{resources.TemplateHash: hash}
+		}
+		return dep
+	}
+	env := corev1.EnvVar{Name: "TZ", Value: "UTC"}
+
+	tests := []struct {
+		name           string
+		deploy         *appsv1.Deployment
+		existingDeploy *appsv1.Deployment
+		expHash        string
+		expImage       string
+		expEnv         []corev1.EnvVar
+		expReplicas    int32
+	}{
+		{
+			name:        "The deployment should be created if it does not exist.",
+			deploy:      newDeployment("new", "kvrocks:2.4", 3),
+			expHash:     "new",
+			expImage:    "kvrocks:2.4",
+			expReplicas: 3,
+		}, {
+			name:           "The deployment should not be updated if the hash is not changed.",
+			deploy:         newDeployment("old", "kvrocks:2.5", 3),
+			existingDeploy: newDeployment("old", "kvrocks:2.4", 3),
+			expHash:        "old",
+			expImage:       "kvrocks:2.4",
+			expReplicas:    3,
+		}, {
+			name:           "The template should be rolled out if the hash is changed, the replicas should be kept.",
+			deploy:         newDeployment("new", "kvrocks:2.4", 5, env),
+			existingDeploy: newDeployment("old", "kvrocks:2.4", 3),
+			expHash:        "new",
+			expImage:       "kvrocks:2.4",
+			expEnv:         []corev1.EnvVar{env},
+			expReplicas:    3,
+		}, {
+			name:           "The deployment without hash should only be annotated if the images are not changed.",
+			deploy:         newDeployment("new", "kvrocks:2.4", 3, env),
+			existingDeploy: newDeployment("", "kvrocks:2.4", 3),
+			expHash:        "new",
+			expImage:       "kvrocks:2.4",
+			expReplicas:    3,
+		}, {
+			name:           "The deployment without hash should be updated if the images are changed.",
+			deploy:         newDeployment("new", "kvrocks:2.5", 3),
+			existingDeploy: newDeployment("", "kvrocks:2.4", 3),
+			expHash:        "new",
+			expImage:       "kvrocks:2.5",
+			expReplicas:    3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			objs := make([]k8sApiClient.Object, 0)
+			if test.existingDeploy != nil {
+				objs = append(objs, test.existingDeploy)
+			}
+			scheme := runtime.NewScheme()
+			_ = appsv1.AddToScheme(scheme)
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+			c := NewK8sClient(fakeClient, ctrl.Log.WithName("deployment-test"))
+
+			assert.NoError(c.CreateDeploymentOrUpdateTemplate(test.deploy))
+			dep := &appsv1.Deployment{}
+			assert.NoError(fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: ns, Name: "test"}, dep))
+			assert.Equal(test.expHash, dep.Annotations[resources.TemplateHash])
+			assert.Equal(test.expImage, dep.Spec.Template.Spec.Containers[0].Image)
+			assert.Equal(test.expEnv, dep.Spec.Template.Spec.Containers[0].Env)
+			assert.Equal(test.expReplicas, *dep.Spec.Replicas)
+		})
+	}
+}
+
 func TestListDeploymentPods(t *testing.T) {
 	ns := "unit-test"
 	testDeployment := &appsv1.Deployment{
@@ -0,0 +1,47 @@
+package k8s
+
+import (
+	"sort"
+
+	k8sApiClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+)
+
+// ListKVRocksOperations returns the operations on the instance, oldest first
+func (c *Client) ListKVRocksOperations(namespace, instance string) ([]kvrocksv1alpha1.KVRocksOperation, error) {
+	var list kvrocksv1alpha1.KVRocksOperationList
+	if err := c.client.List(ctx, &list, k8sApiClient.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	var operations []kvrocksv1alpha1.KVRocksOperation
+	for _, op := range list.Items {
+		if op.Spec.Instance == instance {
+			operations = append(operations, op)
+		}
+	}
+	sort.SliceStable(operations, func(i, j int) bool {
+		ti, tj := operations[i].CreationTimestamp, operations[j].CreationTimestamp
+		if ti.Equal(&tj) {
+			return operations[i].Name < operations[j].Name
+		}
+		return ti.Before(&tj)
+	})
+	return operations, nil
+}
+
+func (c *Client) CreateKVRocksOperation(op *kvrocksv1alpha1.KVRocksOperation) error {
+	if err := c.client.Create(ctx, op); err != nil {
+		return err
+	}
+	c.logger.V(1).Info("create kvrocks operation successfully", "operation", op.Name, "type", op.Spec.Type)
+	return nil
+}
+
+func (c *Client) UpdateKVRocksOperation(op *kvrocksv1alpha1.KVRocksOperation) error {
+	if err := c.client.Update(ctx, op); err != nil {
+		return err
+	}
+	c.logger.V(1).Info("update kvrocks operation successfully", "operation", op.Name, "phase", op.Status.Phase)
+	return nil
+}
@@ -0,0 +1,175 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sApiClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestOperation(name, instance string, created time.Time) *kvrocksv1alpha1.KVRocksOperation {
+	return &kvrocksv1alpha1.KVRocksOperation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "unit-test",
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: kvrocksv1alpha1.KVRocksOperationSpec{
+			Instance: instance,
+			Type:     kvrocksv1alpha1.SwitchoverOperation,
+		},
+	}
+}
+
+func TestListKVRocksOperations(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+
+	tests := []struct {
+		name               string
+		existingOperations []*kvrocksv1alpha1.KVRocksOperation
+		expNames           []string
+	}{
+		{
+			name:               "No operation should return an empty list.",
+			existingOperations: nil,
+			expNames:           nil,
+		}, {
+			name: "Operations of the instance should be returned oldest first.",
+			existingOperations: []*kvrocksv1alpha1.KVRocksOperation{
+				newTestOperation("op-b", "test", now),
+				newTestOperation("op-a", "test", now.Add(time.Minute)),
+				newTestOperation("op-c", "other", now.Add(-time.Minute)),
+			},
+			expNames: []string{"op-b", "op-a"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var objs []k8sApiClient.Object
+			for _, op := range test.existingOperations {
+				objs = append(objs, op)
+			}
+			scheme := runtime.NewScheme()
+			_ = kvrocksv1alpha1.AddToScheme(scheme)
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+			c := NewK8sClient(fakeClient, ctrl.Log.WithName("kvrocks-test"))
+
+			operations, err := c.ListKVRocksOperations("unit-test", "test")
+			assert.NoError(err)
+			var names []string
+			for _, op := range operations {
+				names = append(names, op.Name)
+			}
+			assert.Equal(test.expNames, names)
+		})
+	}
+}
+
+func TestUpdateKVRocksOperation(t *testing.T) {
+	testOperation := newTestOperation("op", "test", time.Now())
+
+	tests := []struct {
+		name              string
+		operation         *kvrocksv1alpha1.KVRocksOperation
+		existingOperation *kvrocksv1alpha1.KVRocksOperation
+		expErr            bool
+	}{
+		{
+			name:              "Operation status should be updated.",
+			operation:         testOperation.DeepCopy(),
+			existingOperation: testOperation.DeepCopy(),
+			expErr:            false,
+		}, {
+			name:              "A non existent operation should return an error.",
+			operation:         testOperation.DeepCopy(),
+			existingOperation: nil,
+			expErr:            true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var objs []k8sApiClient.Object
+			if test.existingOperation != nil {
+				objs = append(objs, test.existingOperation)
+			}
+			scheme := runtime.NewScheme()
+			_ = kvrocksv1alpha1.AddToScheme(scheme)
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+			c := NewK8sClient(fakeClient, ctrl.Log.WithName("kvrocks-test"))
+
+			op := test.operation
+			_ = fakeClient.Get(ctx, types.NamespacedName{Namespace: op.Namespace, Name: op.Name}, op)
+			op.Status.Phase = kvrocksv1alpha1.OperationRunning
+			err := c.UpdateKVRocksOperation(op)
+			if test.expErr {
+				assert.Error(err)
+				return
+			}
+			assert.NoError(err)
+			var updated kvrocksv1alpha1.KVRocksOperation
+			assert.NoError(fakeClient.Get(ctx, types.NamespacedName{Namespace: op.Namespace, Name: op.Name}, &updated))
+			assert.Equal(kvrocksv1alpha1.OperationRunning, updated.Status.Phase)
+		})
+	}
+}
+
+func TestCreateKVRocksOperation(t *testing.T) {
+	testOperation := newTestOperation("op", "test", time.Now())
+
+	tests := []struct {
+		name              string
+		operation         *kvrocksv1alpha1.KVRocksOperation
+		existingOperation *kvrocksv1alpha1.KVRocksOperation
+		expErr            bool
+	}{
+		{
+			name:              "Operation should be created.",
+			operation:         testOperation.DeepCopy(),
+			existingOperation: nil,
+			expErr:            false,
+		}, {
+			name:              "An existing operation should return an error.",
+			operation:         testOperation.DeepCopy(),
+			existingOperation: testOperation.DeepCopy(),
+			expErr:            true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var objs []k8sApiClient.Object
+			if test.existingOperation != nil {
+				objs = append(objs, test.existingOperation)
+			}
+			scheme := runtime.NewScheme()
+			_ = kvrocksv1alpha1.AddToScheme(scheme)
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+			c := NewK8sClient(fakeClient, ctrl.Log.WithName("kvrocks-test"))
+
+			err := c.CreateKVRocksOperation(test.operation)
+			if test.expErr {
+				assert.Error(err)
+				return
+			}
+			assert.NoError(err)
+			operations, err := c.ListKVRocksOperations("unit-test", "test")
+			assert.NoError(err)
+			assert.Len(operations, 1)
+		})
+	}
+}
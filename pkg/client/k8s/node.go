@@ -0,0 +1,14 @@
+package k8s
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func (c *Client) GetNode(name string) (*corev1.Node, error) {
+	var node corev1.Node
+	if err := c.client.Get(ctx, types.NamespacedName{Name: name}, &node); err != nil {
+		return nil, err
+	}
+	return &node, nil
+}
@@ -0,0 +1,59 @@
+package k8s
+
+import (
+	"reflect"
+
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func (c *Client) GetPDB(key types.NamespacedName) (*policyv1.PodDisruptionBudget, error) {
+	var pdb policyv1.PodDisruptionBudget
+	if err := c.client.Get(ctx, key, &pdb); err != nil {
+		return nil, err
+	}
+	return &pdb, nil
+}
+
+// CreateOrUpdatePDB updates the pdb only if its spec changes, e.g. the sentinel replicas are scaled
+func (c *Client) CreateOrUpdatePDB(pdb *policyv1.PodDisruptionBudget) error {
+	oldPDB, err := c.GetPDB(types.NamespacedName{
+		Namespace: pdb.Namespace,
+		Name:      pdb.Name,
+	})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			if err = c.client.Create(ctx, pdb); err != nil && !errors.IsAlreadyExists(err) {
+				return err
+			}
+			c.logger.V(1).Info("pdb create successfully", "pdb", pdb.Name)
+			return nil
+		}
+		return err
+	}
+	if reflect.DeepEqual(oldPDB.Spec, pdb.Spec) {
+		return nil
+	}
+	pdb.ResourceVersion = oldPDB.ResourceVersion
+	if err = c.client.Update(ctx, pdb); err != nil {
+		return err
+	}
+	c.logger.V(1).Info("pdb update successfully", "pdb", pdb.Name)
+	return nil
+}
+
+func (c *Client) DeletePDBIfExists(key types.NamespacedName) error {
+	pdb, err := c.GetPDB(key)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if err = c.client.Delete(ctx, pdb); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	c.logger.V(1).Info("pdb delete successfully", "pdb", pdb.Name)
+	return nil
+}
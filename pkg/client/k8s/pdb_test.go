@@ -0,0 +1,98 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sApiClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCreateOrUpdatePDB(t *testing.T) {
+	ns := "unit-test"
+	minAvailable := intstr.FromInt(2)
+	testPDB := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: ns,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable: &minAvailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": "test"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		pdb             *policyv1.PodDisruptionBudget
+		existingPDB     *policyv1.PodDisruptionBudget
+		expMinAvailable int
+	}{
+		{
+			name:            "A new pdb should be created.",
+			pdb:             testPDB.DeepCopy(),
+			existingPDB:     nil,
+			expMinAvailable: 2,
+		}, {
+			name: "An existent pdb should be updated if its spec changes.",
+			pdb: func() *policyv1.PodDisruptionBudget {
+				pdb := testPDB.DeepCopy()
+				value := intstr.FromInt(3)
+				pdb.Spec.MinAvailable = &value
+				return pdb
+			}(),
+			existingPDB:     testPDB.DeepCopy(),
+			expMinAvailable: 3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			objs := []k8sApiClient.Object{}
+			if test.existingPDB != nil {
+				objs = append(objs, test.existingPDB)
+			}
+			scheme := runtime.NewScheme()
+			_ = policyv1.AddToScheme(scheme)
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+			c := NewK8sClient(fakeClient, ctrl.Log.WithName("pdb-test"))
+
+			err := c.CreateOrUpdatePDB(test.pdb)
+			assert.NoError(err)
+			pdb, err := c.GetPDB(types.NamespacedName{Namespace: ns, Name: test.pdb.Name})
+			assert.NoError(err)
+			assert.Equal(test.expMinAvailable, pdb.Spec.MinAvailable.IntValue())
+		})
+	}
+}
+
+func TestDeletePDBIfExists(t *testing.T) {
+	ns := "unit-test"
+	testPDB := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: ns,
+		},
+	}
+	scheme := runtime.NewScheme()
+	_ = policyv1.AddToScheme(scheme)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(testPDB).Build()
+	c := NewK8sClient(fakeClient, ctrl.Log.WithName("pdb-test"))
+	key := types.NamespacedName{Namespace: ns, Name: "test"}
+
+	assert.NoError(t, c.DeletePDBIfExists(key))
+	_, err := c.GetPDB(key)
+	assert.Error(t, err)
+	// deleting a non existent pdb is not an error
+	assert.NoError(t, c.DeletePDBIfExists(key))
+}
@@ -15,6 +15,26 @@ func (c *Client) GetPod(key types.NamespacedName) (*corev1.Pod, error) {
 	return &pod, nil
 }
 
+func (c *Client) ListPods(namespace string, labels map[string]string) (*corev1.PodList, error) {
+	var pods corev1.PodList
+	if err := c.client.List(ctx, &pods, k8sApiClient.InNamespace(namespace), k8sApiClient.MatchingLabels(labels)); err != nil {
+		return nil, err
+	}
+	return &pods, nil
+}
+
+// PodNodeNameIndex is the field index of pods by the node they are scheduled to
+const PodNodeNameIndex = "spec.nodeName"
+
+// ListNodePods lists the pods of all namespaces on the node by the field index
+func (c *Client) ListNodePods(nodeName string) (*corev1.PodList, error) {
+	var pods corev1.PodList
+	if err := c.client.List(ctx, &pods, k8sApiClient.MatchingFields{PodNodeNameIndex: nodeName}); err != nil {
+		return nil, err
+	}
+	return &pods, nil
+}
+
 func (c *Client) UpdatePod(pod *corev1.Pod) error {
 	if err := c.client.Update(ctx, pod); err != nil {
 		return err
@@ -23,6 +43,22 @@ func (c *Client) UpdatePod(pod *corev1.Pod) error {
 	return nil
 }
 
+func (c *Client) UpdatePodStatus(pod *corev1.Pod) error {
+	if err := c.client.Status().Update(ctx, pod); err != nil {
+		return err
+	}
+	c.logger.V(1).Info("update pod status successfully", "pod", pod.Name)
+	return nil
+}
+
+func (c *Client) DeletePod(pod *corev1.Pod) error {
+	if err := c.client.Delete(ctx, pod); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	c.logger.V(1).Info("delete pod successfully", "pod", pod.Name)
+	return nil
+}
+
 func (c *Client) DeletePodImmediately(podName, namespace string) error {
 	pod, err := c.GetPod(types.NamespacedName{
 		Namespace: namespace,
@@ -140,6 +140,65 @@ func TestUpdatePod(t *testing.T) {
 	}
 }
 
+func TestUpdatePodStatus(t *testing.T) {
+	ns := "unit-test"
+	testPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: ns,
+		},
+	}
+
+	tests := []struct {
+		name        string
+		pod         *corev1.Pod
+		existingPod *corev1.Pod
+		expErr      bool
+	}{
+		{
+			name:        "The pod conditions should be updated.",
+			pod:         testPod.DeepCopy(),
+			existingPod: testPod.DeepCopy(),
+			expErr:      false,
+		}, {
+			name:        "A non existent pod should return an error.",
+			pod:         testPod.DeepCopy(),
+			existingPod: nil,
+			expErr:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var objs []k8sApiClient.Object
+			if test.existingPod != nil {
+				objs = append(objs, test.existingPod)
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build()
+			c := NewK8sClient(fakeClient, ctrl.Log.WithName("pod-test"))
+
+			test.pod.Status.Conditions = []corev1.PodCondition{{
+				Type:   "kvrocks/replication-ready",
+				Status: corev1.ConditionTrue,
+			}}
+			err := c.UpdatePodStatus(test.pod)
+			if test.expErr {
+				assert.Error(err)
+			} else {
+				assert.NoError(err)
+				updatedPod, err := c.GetPod(types.NamespacedName{
+					Namespace: test.pod.Namespace,
+					Name:      test.pod.Name,
+				})
+				assert.NoError(err)
+				assert.Len(updatedPod.Status.Conditions, 1)
+			}
+		})
+	}
+}
+
 func TestDeletePodImmediately(t *testing.T) {
 	ns := "unit-test"
 	testPod := &corev1.Pod{
@@ -193,3 +252,123 @@ func TestDeletePodImmediately(t *testing.T) {
 		})
 	}
 }
+
+func TestDeletePod(t *testing.T) {
+	ns := "unit-test"
+	testPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: ns,
+		},
+	}
+
+	tests := []struct {
+		name        string
+		pod         *corev1.Pod
+		existingPod *corev1.Pod
+		expErr      bool
+	}{
+		{
+			name:        "A pod should be deleted.",
+			pod:         testPod.DeepCopy(),
+			existingPod: testPod.DeepCopy(),
+			expErr:      false,
+		}, {
+			name:        "A non existent pod should not return an error.",
+			pod:         testPod.DeepCopy(),
+			existingPod: nil,
+			expErr:      false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var objs []k8sApiClient.Object
+			if test.existingPod != nil {
+				objs = append(objs, test.existingPod)
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build()
+			c := NewK8sClient(fakeClient, ctrl.Log.WithName("pod-test"))
+
+			err := c.DeletePod(test.pod)
+			if test.expErr {
+				assert.Error(err)
+			} else {
+				assert.NoError(err)
+
+				pod, err := c.GetPod(types.NamespacedName{
+					Namespace: test.pod.Namespace,
+					Name:      test.pod.Name,
+				})
+				assert.Error(err)
+				assert.True(kubeerrors.IsNotFound(err))
+				assert.Nil(pod)
+			}
+		})
+	}
+}
+
+func TestListNodePods(t *testing.T) {
+	newPod := func(name, ns, nodeName string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: ns,
+			},
+			Spec: corev1.PodSpec{
+				NodeName: nodeName,
+			},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		nodeName     string
+		existingPods []*corev1.Pod
+		expPods      []string
+	}{
+		{
+			name:     "The pods of all namespaces on the node should be returned.",
+			nodeName: "node-1",
+			existingPods: []*corev1.Pod{
+				newPod("a", "ns-1", "node-1"),
+				newPod("b", "ns-2", "node-1"),
+				newPod("c", "ns-1", "node-2"),
+			},
+			expPods: []string{"a", "b"},
+		}, {
+			name:     "No pod should be returned for a node without pods.",
+			nodeName: "node-3",
+			existingPods: []*corev1.Pod{
+				newPod("a", "ns-1", "node-1"),
+			},
+			expPods: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var objs []k8sApiClient.Object
+			for _, pod := range test.existingPods {
+				objs = append(objs, pod)
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).
+				WithIndex(&corev1.Pod{}, PodNodeNameIndex, func(o k8sApiClient.Object) []string {
+					return []string{o.(*corev1.Pod).Spec.NodeName}
+				}).Build()
+			c := NewK8sClient(fakeClient, ctrl.Log.WithName("pod-test"))
+
+			pods, err := c.ListNodePods(test.nodeName)
+			assert.NoError(err)
+			var names []string
+			for _, pod := range pods.Items {
+				names = append(names, pod.Name)
+			}
+			assert.ElementsMatch(test.expPods, names)
+		})
+	}
+}
@@ -47,3 +47,27 @@ func (c *Client) DeletePVCByPod(podName string, namespace string) error {
 	c.logger.V(1).Info("delete pvc successfully", "pvc", pvc.Name)
 	return nil
 }
+
+func (c *Client) UpdatePVC(pvc *corev1.PersistentVolumeClaim) error {
+	if err := c.client.Update(ctx, pvc); err != nil {
+		return err
+	}
+	c.logger.V(1).Info("update pvc successfully", "pvc", pvc.Name)
+	return nil
+}
+
+func (c *Client) GetPVC(key types.NamespacedName) (*corev1.PersistentVolumeClaim, error) {
+	var pvc corev1.PersistentVolumeClaim
+	if err := c.client.Get(ctx, key, &pvc); err != nil {
+		return nil, err
+	}
+	return &pvc, nil
+}
+
+func (c *Client) GetPV(name string) (*corev1.PersistentVolume, error) {
+	var pv corev1.PersistentVolume
+	if err := c.client.Get(ctx, types.NamespacedName{Name: name}, &pv); err != nil {
+		return nil, err
+	}
+	return &pv, nil
+}
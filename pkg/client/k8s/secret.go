@@ -0,0 +1,50 @@
+package k8s
+
+import (
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func (c *Client) GetSecret(key types.NamespacedName) (*corev1.Secret, error) {
+	var secret corev1.Secret
+	if err := c.client.Get(ctx, key, &secret); err != nil {
+		return nil, err
+	}
+	return &secret, nil
+}
+
+func (c *Client) CreateIfNotExistsSecret(secret *corev1.Secret) error {
+	if err := c.client.Create(ctx, secret); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	c.logger.V(1).Info("secret create successfully", "secret", secret.Name)
+	return nil
+}
+
+// CreateOrUpdateSecret updates the secret only if its data or labels change
+func (c *Client) CreateOrUpdateSecret(secret *corev1.Secret) error {
+	oldSecret, err := c.GetSecret(types.NamespacedName{
+		Namespace: secret.Namespace,
+		Name:      secret.Name,
+	})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return c.CreateIfNotExistsSecret(secret)
+		}
+		return err
+	}
+	if reflect.DeepEqual(oldSecret.Data, secret.Data) && reflect.DeepEqual(oldSecret.Labels, secret.Labels) {
+		return nil
+	}
+	secret.ResourceVersion = oldSecret.ResourceVersion
+	// the type of a secret is immutable
+	secret.Type = oldSecret.Type
+	if err = c.client.Update(ctx, secret); err != nil {
+		return err
+	}
+	c.logger.V(1).Info("secret update successfully", "secret", secret.Name)
+	return nil
+}
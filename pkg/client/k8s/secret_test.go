@@ -0,0 +1,78 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sApiClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCreateOrUpdateSecret(t *testing.T) {
+	ns := "unit-test"
+	testSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-connection",
+			Namespace: ns,
+		},
+		Type: "servicebinding.io/redis",
+		Data: map[string][]byte{
+			"host": []byte("test.unit-test.svc"),
+		},
+	}
+
+	tests := []struct {
+		name           string
+		secret         *corev1.Secret
+		existingSecret *corev1.Secret
+		expHost        string
+	}{
+		{
+			name:           "A new secret should be created.",
+			secret:         testSecret.DeepCopy(),
+			existingSecret: nil,
+			expHost:        "test.unit-test.svc",
+		}, {
+			name: "An existent secret should be updated if its data changes.",
+			secret: func() *corev1.Secret {
+				secret := testSecret.DeepCopy()
+				secret.Data["host"] = []byte("test2.unit-test.svc")
+				return secret
+			}(),
+			existingSecret: testSecret.DeepCopy(),
+			expHost:        "test2.unit-test.svc",
+		}, {
+			name:           "An existent secret with the same data should not be changed.",
+			secret:         testSecret.DeepCopy(),
+			existingSecret: testSecret.DeepCopy(),
+			expHost:        "test.unit-test.svc",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var objs []k8sApiClient.Object
+			if test.existingSecret != nil {
+				objs = append(objs, test.existingSecret)
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build()
+			c := NewK8sClient(fakeClient, ctrl.Log.WithName("secret-test"))
+
+			err := c.CreateOrUpdateSecret(test.secret)
+			assert.NoError(err)
+
+			secret := &corev1.Secret{}
+			err = fakeClient.Get(context.TODO(), k8sApiClient.ObjectKey{Namespace: ns, Name: test.secret.Name}, secret)
+			assert.NoError(err)
+			assert.Equal(test.expHost, string(secret.Data["host"]))
+			assert.Equal(testSecret.Type, secret.Type)
+		})
+	}
+}
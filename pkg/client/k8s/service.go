@@ -1,9 +1,13 @@
 package k8s
 
 import (
+	"reflect"
+
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	k8sApiClient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 func (c *Client) CreateIfNotExistsService(service *corev1.Service) error {
@@ -33,3 +37,76 @@ func (c *Client) DeleteService(key types.NamespacedName) error {
 	c.logger.V(1).Info("service delete successfully", "service", service.Name)
 	return nil
 }
+
+// CreateOrUpdateService updates the type, ports, selector and annotations of the service, the allocated cluster ip and
+// node ports are kept
+func (c *Client) CreateOrUpdateService(service *corev1.Service) error {
+	oldService, err := c.GetService(types.NamespacedName{
+		Namespace: service.Namespace,
+		Name:      service.Name,
+	})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return c.CreateIfNotExistsService(service)
+		}
+		return err
+	}
+	if oldService.Spec.Type == service.Spec.Type &&
+		samePorts(oldService.Spec.Ports, service.Spec.Ports) &&
+		reflect.DeepEqual(oldService.Spec.Selector, service.Spec.Selector) &&
+		reflect.DeepEqual(oldService.Annotations, service.Annotations) {
+		return nil
+	}
+	service.ResourceVersion = oldService.ResourceVersion
+	service.Spec.ClusterIP = oldService.Spec.ClusterIP
+	service.Spec.ClusterIPs = oldService.Spec.ClusterIPs
+	if service.Spec.Type != corev1.ServiceTypeClusterIP {
+		for i := range service.Spec.Ports {
+			for _, port := range oldService.Spec.Ports {
+				if port.Name == service.Spec.Ports[i].Name {
+					service.Spec.Ports[i].NodePort = port.NodePort
+				}
+			}
+		}
+	}
+	if err = c.client.Update(ctx, service); err != nil {
+		return err
+	}
+	c.logger.V(1).Info("service update successfully", "service", service.Name)
+	return nil
+}
+
+// samePorts compares the ports without the node ports and the fields defaulted by the api server
+func samePorts(oldPorts, newPorts []corev1.ServicePort) bool {
+	if len(oldPorts) != len(newPorts) {
+		return false
+	}
+	for i := range newPorts {
+		oldPort, newPort := oldPorts[i], newPorts[i]
+		if newPort.Protocol == "" {
+			newPort.Protocol = corev1.ProtocolTCP
+		}
+		if newPort.TargetPort.IntValue() == 0 && newPort.TargetPort.StrVal == "" {
+			newPort.TargetPort = intstr.FromInt(int(newPort.Port))
+		}
+		if oldPort.Protocol == "" {
+			oldPort.Protocol = corev1.ProtocolTCP
+		}
+		if oldPort.TargetPort.IntValue() == 0 && oldPort.TargetPort.StrVal == "" {
+			oldPort.TargetPort = intstr.FromInt(int(oldPort.Port))
+		}
+		if oldPort.Name != newPort.Name || oldPort.Protocol != newPort.Protocol || oldPort.Port != newPort.Port ||
+			oldPort.TargetPort != newPort.TargetPort {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Client) ListServices(namespace string, labels map[string]string) (*corev1.ServiceList, error) {
+	var services corev1.ServiceList
+	if err := c.client.List(ctx, &services, k8sApiClient.InNamespace(namespace), k8sApiClient.MatchingLabels(labels)); err != nil {
+		return nil, err
+	}
+	return &services, nil
+}
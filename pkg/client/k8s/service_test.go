@@ -8,6 +8,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	k8sApiClient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -70,3 +71,128 @@ func TestCreateIfNotExistsService(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateOrUpdateService(t *testing.T) {
+	ns := "unit-test"
+	testService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-service",
+			Namespace: ns,
+		},
+		Spec: corev1.ServiceSpec{
+			Type:      corev1.ServiceTypeClusterIP,
+			ClusterIP: "10.0.0.1",
+			Ports:     []corev1.ServicePort{{Name: "kvrocks", Port: 6379}},
+		},
+	}
+	nodePortService := testService.DeepCopy()
+	nodePortService.Spec.Type = corev1.ServiceTypeNodePort
+	nodePortService.Spec.Ports[0].NodePort = 30001
+
+	tests := []struct {
+		name            string
+		service         *corev1.Service
+		existingService *corev1.Service
+		expType         corev1.ServiceType
+		expPort         int32
+		expNodePort     int32
+	}{
+		{
+			name:            "Service does not exist, should be created.",
+			service:         testService.DeepCopy(),
+			existingService: nil,
+			expType:         corev1.ServiceTypeClusterIP,
+			expPort:         6379,
+		}, {
+			name: "Service type changes, should be updated.",
+			service: func() *corev1.Service {
+				service := testService.DeepCopy()
+				service.Spec.Type = corev1.ServiceTypeNodePort
+				service.Spec.ClusterIP = ""
+				return service
+			}(),
+			existingService: testService.DeepCopy(),
+			expType:         corev1.ServiceTypeNodePort,
+			expPort:         6379,
+		}, {
+			name: "Node port service is updated, the node port should be kept.",
+			service: func() *corev1.Service {
+				service := testService.DeepCopy()
+				service.Spec.Type = corev1.ServiceTypeLoadBalancer
+				service.Spec.ClusterIP = ""
+				return service
+			}(),
+			existingService: nodePortService.DeepCopy(),
+			expType:         corev1.ServiceTypeLoadBalancer,
+			expPort:         6379,
+			expNodePort:     30001,
+		}, {
+			name: "Service port changes, should be updated.",
+			service: func() *corev1.Service {
+				service := testService.DeepCopy()
+				service.Spec.Ports[0].Port = 6380
+				return service
+			}(),
+			existingService: testService.DeepCopy(),
+			expType:         corev1.ServiceTypeClusterIP,
+			expPort:         6380,
+		}, {
+			name:    "Ports defaulted by the api server should not be taken as changed.",
+			service: testService.DeepCopy(),
+			existingService: func() *corev1.Service {
+				service := testService.DeepCopy()
+				service.Spec.Ports[0].Protocol = corev1.ProtocolTCP
+				service.Spec.Ports[0].TargetPort = intstr.FromInt(6379)
+				return service
+			}(),
+			expType: corev1.ServiceTypeClusterIP,
+			expPort: 6379,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			objs := []k8sApiClient.Object{}
+			if test.existingService != nil {
+				objs = append(objs, test.existingService)
+			}
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+			c := NewK8sClient(fakeClient, ctrl.Log.WithName("service-test"))
+
+			err := c.CreateOrUpdateService(test.service)
+			assert.NoError(err)
+			service := &corev1.Service{}
+			err = fakeClient.Get(ctx, types.NamespacedName{
+				Namespace: test.service.Namespace,
+				Name:      test.service.Name,
+			}, service)
+			assert.NoError(err)
+			assert.Equal(test.expType, service.Spec.Type)
+			assert.Equal(testService.Spec.ClusterIP, service.Spec.ClusterIP)
+			assert.Equal(test.expPort, service.Spec.Ports[0].Port)
+			assert.Equal(test.expNodePort, service.Spec.Ports[0].NodePort)
+		})
+	}
+}
+
+func TestListServices(t *testing.T) {
+	ns := "unit-test"
+	labels := map[string]string{"kvrocks/expose": "pod"}
+	objs := []k8sApiClient.Object{
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "test-0", Namespace: ns, Labels: labels}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "test-1", Namespace: ns, Labels: labels}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: ns}},
+	}
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	c := NewK8sClient(fakeClient, ctrl.Log.WithName("service-test"))
+
+	services, err := c.ListServices(ns, labels)
+	assert.NoError(t, err)
+	assert.Len(t, services.Items, 2)
+}
@@ -5,8 +5,11 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	k8sApiClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
 )
 
 func (c *Client) CreateIfNotExistsStatefulSet(sts *kruise.StatefulSet) error {
@@ -61,27 +64,99 @@ func (c *Client) CreateOrUpdateStatefulSet(sts *kruise.StatefulSet) error {
 		return err
 	}
 	sts.ResourceVersion = oldSts.ResourceVersion
+	// volumeClaimTemplates and serviceName are immutable, they are changed by recreating the statefulSet
+	sts.Spec.VolumeClaimTemplates = oldSts.Spec.VolumeClaimTemplates
+	sts.Spec.ServiceName = oldSts.Spec.ServiceName
+	resources.KeepReadinessGates(&sts.Spec.Template, oldSts.Spec.Template.Spec.ReadinessGates)
 	return c.UpdateStatefulSet(sts)
 }
 
-func (c *Client) CreateStatefulSetOrUpdateImage(sts *kruise.StatefulSet) error {
+// CreateStatefulSetOrUpdateTemplate updates the statefulSet only if the pod template rendered by the operator is
+// changed, the replicas and the reserved ordinals are managed by the handlers
+func (c *Client) CreateStatefulSetOrUpdateTemplate(sts *kruise.StatefulSet) error {
 	oldSts, err := c.GetStatefulSet(types.NamespacedName{
 		Namespace: sts.Namespace,
 		Name:      sts.Name,
 	})
 	if err != nil {
 		if errors.IsNotFound(err) {
+			// the statefulSet recreated with orphan pods keeps their readiness gates
+			pods, err := c.ListPods(sts.Namespace, sts.Spec.Selector.MatchLabels)
+			if err != nil {
+				return err
+			}
+			if len(pods.Items) != 0 {
+				resources.KeepReadinessGates(&sts.Spec.Template, pods.Items[0].Spec.ReadinessGates)
+			}
 			return c.CreateIfNotExistsStatefulSet(sts)
 		}
 		return err
 	}
-	if oldSts.Spec.Template.Spec.Containers[0].Image == sts.Spec.Template.Spec.Containers[0].Image && oldSts.Spec.Template.Spec.Containers[1].Image == sts.Spec.Template.Spec.Containers[1].Image {
+	switch CompareTemplate(&oldSts.ObjectMeta, &sts.ObjectMeta, oldSts.Spec.Template.Spec.Containers, sts.Spec.Template.Spec.Containers) {
+	case TemplateSame:
 		return nil
+	case TemplateUnhashed:
+		CopyTemplateHash(&oldSts.ObjectMeta, &sts.ObjectMeta)
+		return c.UpdateStatefulSet(oldSts)
 	}
 	sts.ResourceVersion = oldSts.ResourceVersion
+	// volumeClaimTemplates and serviceName are immutable, they are changed by recreating the statefulSet
+	sts.Spec.VolumeClaimTemplates = oldSts.Spec.VolumeClaimTemplates
+	sts.Spec.ServiceName = oldSts.Spec.ServiceName
+	resources.KeepReadinessGates(&sts.Spec.Template, oldSts.Spec.Template.Spec.ReadinessGates)
 	return c.UpdateStatefulSet(sts)
 }
 
+type TemplateComparison int
+
+const (
+	// TemplateSame means the pod template is not changed
+	TemplateSame TemplateComparison = iota
+	// TemplateChanged means the pod template is changed and should be rolled out
+	TemplateChanged
+	// TemplateUnhashed means the workload is created before the template hash with the same images, it is only
+	// annotated with the hash so that upgrading the operator does not restart the pods
+	TemplateUnhashed
+)
+
+// CompareTemplate compares the template hash of the existing workload with that of the rendered one
+func CompareTemplate(oldMeta, meta *metav1.ObjectMeta, oldContainers, containers []corev1.Container) TemplateComparison {
+	hash, ok := oldMeta.Annotations[resources.TemplateHash]
+	switch {
+	case ok && hash == meta.Annotations[resources.TemplateHash]:
+		return TemplateSame
+	case !ok && SameImages(oldContainers, containers):
+		return TemplateUnhashed
+	}
+	return TemplateChanged
+}
+
+// CopyTemplateHash annotates the existing workload with the template hash of the rendered one
+func CopyTemplateHash(oldMeta, meta *metav1.ObjectMeta) {
+	hash, ok := meta.Annotations[resources.TemplateHash]
+	if !ok {
+		return
+	}
+	if oldMeta.Annotations == nil {
+		oldMeta.Annotations = map[string]string{}
+	}
+	oldMeta.Annotations[resources.TemplateHash] = hash
+}
+
+// SameImages checks if the containers have the same names and images, adding or removing a container such as the
+// exporter also needs to update the statefulSet
+func SameImages(oldContainers, containers []corev1.Container) bool {
+	if len(oldContainers) != len(containers) {
+		return false
+	}
+	for i := range containers {
+		if oldContainers[i].Name != containers[i].Name || oldContainers[i].Image != containers[i].Image {
+			return false
+		}
+	}
+	return true
+}
+
 func (c *Client) ListStatefulSets(namespace string, labels map[string]string) (*kruise.StatefulSetList, error) {
 	var stsList kruise.StatefulSetList
 	if err := c.client.List(ctx, &stsList, k8sApiClient.InNamespace(namespace), k8sApiClient.MatchingLabels(labels)); err != nil {
@@ -101,6 +176,15 @@ func (c *Client) DeleteStatefulSetIfExists(key types.NamespacedName) error {
 	return c.client.Delete(ctx, sts)
 }
 
+// DeleteStatefulSetOrphan deletes the statefulSet and keeps its pods
+func (c *Client) DeleteStatefulSetOrphan(sts *kruise.StatefulSet) error {
+	if err := c.client.Delete(ctx, sts, k8sApiClient.PropagationPolicy(metav1.DeletePropagationOrphan)); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	c.logger.V(1).Info("delete statefulSet with orphan pods successfully", "statefulSet", sts.Name)
+	return nil
+}
+
 // Native StatefulSet
 func (c *Client) CreateIfNotExistsNativeStatefulSet(sts *appsv1.StatefulSet) error {
 	if err := c.client.Create(ctx, sts); err != nil && !errors.IsAlreadyExists(err) {
@@ -110,6 +194,29 @@ func (c *Client) CreateIfNotExistsNativeStatefulSet(sts *appsv1.StatefulSet) err
 	return nil
 }
 
+// CreateNativeStatefulSetOrUpdateTemplate updates the native statefulSet only if the pod template rendered by the
+// operator is changed
+func (c *Client) CreateNativeStatefulSetOrUpdateTemplate(sts *appsv1.StatefulSet) error {
+	oldSts, err := c.GetNativeStatefulSet(types.NamespacedName{
+		Namespace: sts.Namespace,
+		Name:      sts.Name,
+	})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return c.CreateIfNotExistsNativeStatefulSet(sts)
+		}
+		return err
+	}
+	switch CompareTemplate(&oldSts.ObjectMeta, &sts.ObjectMeta, oldSts.Spec.Template.Spec.Containers, sts.Spec.Template.Spec.Containers) {
+	case TemplateSame:
+		return nil
+	case TemplateChanged:
+		oldSts.Spec.Template = sts.Spec.Template
+	}
+	CopyTemplateHash(&oldSts.ObjectMeta, &sts.ObjectMeta)
+	return c.UpdateNativeStatefulSet(oldSts)
+}
+
 func (c *Client) GetNativeStatefulSet(key types.NamespacedName) (*appsv1.StatefulSet, error) {
 	var sts appsv1.StatefulSet
 	if err := c.client.Get(ctx, key, &sts); err != nil {
@@ -128,3 +235,28 @@ func (c *Client) DeleteNativeStatefulSet(key types.NamespacedName) error {
 	}
 	return nil
 }
+
+func (c *Client) UpdateNativeStatefulSet(sts *appsv1.StatefulSet) error {
+	if err := c.client.Update(ctx, sts); err != nil {
+		return err
+	}
+	c.logger.V(1).Info("update statefulSet successfully", "statefulSet", sts.Name)
+	return nil
+}
+
+func (c *Client) ListNativeStatefulSets(namespace string, labels map[string]string) (*appsv1.StatefulSetList, error) {
+	var stsList appsv1.StatefulSetList
+	if err := c.client.List(ctx, &stsList, k8sApiClient.InNamespace(namespace), k8sApiClient.MatchingLabels(labels)); err != nil {
+		return nil, err
+	}
+	return &stsList, nil
+}
+
+// DeleteNativeStatefulSetOrphan deletes the statefulSet and keeps its pods
+func (c *Client) DeleteNativeStatefulSetOrphan(sts *appsv1.StatefulSet) error {
+	if err := c.client.Delete(ctx, sts, k8sApiClient.PropagationPolicy(metav1.DeletePropagationOrphan)); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	c.logger.V(1).Info("delete statefulSet with orphan pods successfully", "statefulSet", sts.Name)
+	return nil
+}
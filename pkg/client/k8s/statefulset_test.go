@@ -14,6 +14,8 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	k8sApiClient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
 )
 
 func TestCreateIfNotExistsStatefulSet(t *testing.T) {
@@ -341,6 +343,140 @@ func TestCreateOrUpdateStatefulSet(t *testing.T) {
 	}
 }
 
+func TestCreateStatefulSetOrUpdateTemplate(t *testing.T) {
+	ns := "unit-test"
+	replicas := int32(3)
+	labels := map[string]string{"app": "test"}
+	newSTS := func(hash, image string, env ...corev1.EnvVar) *kruise.StatefulSet {
+		sts := &kruise.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test",
+				Namespace: ns,
+			},
+			Spec: kruise.StatefulSetSpec{
+				Replicas: &replicas,
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "kvrocks", Image: image, Env: env}},
+					},
+				},
+			},
+		}
+		if hash != "" {
+			sts.Annotations = map[string]string{resources.TemplateHash: hash}
+		}
+		return sts
+	}
+	env := corev1.EnvVar{Name: "TZ", Value: "UTC"}
+	gate := corev1.PodReadinessGate{ConditionType: resources.ReplicationReadyGate}
+	withGate := func(sts *kruise.StatefulSet) *kruise.StatefulSet {
+		sts.Spec.Template.Spec.ReadinessGates = []corev1.PodReadinessGate{gate}
+		return sts
+	}
+	pod := func(gates ...corev1.PodReadinessGate) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-0", Namespace: ns, Labels: labels},
+			Spec:       corev1.PodSpec{ReadinessGates: gates},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		sts        *kruise.StatefulSet
+		exitingSTS *kruise.StatefulSet
+		pod        *corev1.Pod
+		expHash    string
+		expImage   string
+		expEnv     []corev1.EnvVar
+		expGates   []corev1.PodReadinessGate
+	}{
+		{
+			name:     "The statefulSet should be created if it does not exist.",
+			sts:      newSTS("new", "kvrocks:2.4"),
+			expHash:  "new",
+			expImage: "kvrocks:2.4",
+		}, {
+			name:       "The statefulSet should not be updated if the hash is not changed.",
+			sts:        newSTS("old", "kvrocks:2.5"),
+			exitingSTS: newSTS("old", "kvrocks:2.4"),
+			expHash:    "old",
+			expImage:   "kvrocks:2.4",
+		}, {
+			name:       "The template should be rolled out if the hash is changed without changing the images.",
+			sts:        newSTS("new", "kvrocks:2.4", env),
+			exitingSTS: newSTS("old", "kvrocks:2.4"),
+			expHash:    "new",
+			expImage:   "kvrocks:2.4",
+			expEnv:     []corev1.EnvVar{env},
+		}, {
+			name:       "The statefulSet without hash should only be annotated if the images are not changed.",
+			sts:        newSTS("new", "kvrocks:2.4", env),
+			exitingSTS: newSTS("", "kvrocks:2.4"),
+			expHash:    "new",
+			expImage:   "kvrocks:2.4",
+		}, {
+			name:       "The statefulSet without hash should be updated if the images are changed.",
+			sts:        newSTS("new", "kvrocks:2.5"),
+			exitingSTS: newSTS("", "kvrocks:2.4"),
+			expHash:    "new",
+			expImage:   "kvrocks:2.5",
+		}, {
+			name:     "A new statefulSet should get the readiness gates.",
+			sts:      withGate(newSTS("new", "kvrocks:2.4")),
+			expHash:  "new",
+			expImage: "kvrocks:2.4",
+			expGates: []corev1.PodReadinessGate{gate},
+		}, {
+			name:       "A readiness gate should not be added to an existing statefulSet.",
+			sts:        withGate(newSTS("new", "kvrocks:2.5")),
+			exitingSTS: newSTS("old", "kvrocks:2.4"),
+			expHash:    "new",
+			expImage:   "kvrocks:2.5",
+		}, {
+			name:       "The readiness gates of an existing statefulSet should be kept.",
+			sts:        withGate(newSTS("new", "kvrocks:2.5")),
+			exitingSTS: withGate(newSTS("old", "kvrocks:2.4")),
+			expHash:    "new",
+			expImage:   "kvrocks:2.5",
+			expGates:   []corev1.PodReadinessGate{gate},
+		}, {
+			name:     "A statefulSet recreated with orphan pods should keep their readiness gates.",
+			sts:      withGate(newSTS("new", "kvrocks:2.4")),
+			pod:      pod(),
+			expHash:  "new",
+			expImage: "kvrocks:2.4",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			objs := make([]k8sApiClient.Object, 0)
+			if test.exitingSTS != nil {
+				objs = append(objs, test.exitingSTS)
+			}
+			if test.pod != nil {
+				objs = append(objs, test.pod)
+			}
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			_ = kruise.AddToScheme(scheme)
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+			c := NewK8sClient(fakeClient, ctrl.Log.WithName("statefulset-test"))
+
+			assert.NoError(c.CreateStatefulSetOrUpdateTemplate(test.sts))
+			sts := &kruise.StatefulSet{}
+			assert.NoError(fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: ns, Name: "test"}, sts))
+			assert.Equal(test.expHash, sts.Annotations[resources.TemplateHash])
+			assert.Equal(test.expImage, sts.Spec.Template.Spec.Containers[0].Image)
+			assert.Equal(test.expEnv, sts.Spec.Template.Spec.Containers[0].Env)
+			assert.Equal(test.expGates, sts.Spec.Template.Spec.ReadinessGates)
+		})
+	}
+}
+
 func TestListStatefulSets(t *testing.T) {
 	ns := "unit-test"
 	labels := map[string]string{
@@ -464,3 +600,38 @@ func TestDeleteStatefulSetIfExists(t *testing.T) {
 		})
 	}
 }
+
+func TestSameImages(t *testing.T) {
+	kvrocks := corev1.Container{Name: "kvrocks", Image: "kvrocks:2.4"}
+	exporter := corev1.Container{Name: "kvrocks-exporter", Image: "exporter:1.0"}
+
+	tests := []struct {
+		name          string
+		oldContainers []corev1.Container
+		containers    []corev1.Container
+		expSame       bool
+	}{
+		{
+			name:          "The same containers should be the same.",
+			oldContainers: []corev1.Container{kvrocks, exporter},
+			containers:    []corev1.Container{kvrocks, exporter},
+			expSame:       true,
+		}, {
+			name:          "A changed image should not be the same.",
+			oldContainers: []corev1.Container{kvrocks, exporter},
+			containers:    []corev1.Container{{Name: "kvrocks", Image: "kvrocks:2.5"}, exporter},
+			expSame:       false,
+		}, {
+			name:          "A removed container should not be the same.",
+			oldContainers: []corev1.Container{kvrocks, exporter},
+			containers:    []corev1.Container{kvrocks},
+			expSame:       false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expSame, SameImages(test.oldContainers, test.containers))
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package k8s
+
+import (
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+
+func (c *Client) GetStorageClass(name string) (*storagev1.StorageClass, error) {
+	var class storagev1.StorageClass
+	if err := c.client.Get(ctx, types.NamespacedName{Name: name}, &class); err != nil {
+		return nil, err
+	}
+	return &class, nil
+}
+
+// GetDefaultStorageClass returns nil if there is no default storage class
+func (c *Client) GetDefaultStorageClass() (*storagev1.StorageClass, error) {
+	var classList storagev1.StorageClassList
+	if err := c.client.List(ctx, &classList); err != nil {
+		return nil, err
+	}
+	for i := range classList.Items {
+		if classList.Items[i].Annotations[defaultStorageClassAnnotation] == "true" {
+			return &classList.Items[i], nil
+		}
+	}
+	return nil, nil
+}
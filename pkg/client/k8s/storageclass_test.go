@@ -0,0 +1,64 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sApiClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetDefaultStorageClass(t *testing.T) {
+	testClass := &storagev1.StorageClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "standard",
+		},
+		Provisioner: "test",
+	}
+	defaultClass := testClass.DeepCopy()
+	defaultClass.Name = "default"
+	defaultClass.Annotations = map[string]string{defaultStorageClassAnnotation: "true"}
+
+	tests := []struct {
+		name            string
+		existingClasses []*storagev1.StorageClass
+		expClass        string
+	}{
+		{
+			name:            "The default storage class should be returned.",
+			existingClasses: []*storagev1.StorageClass{testClass.DeepCopy(), defaultClass.DeepCopy()},
+			expClass:        "default",
+		}, {
+			name:            "Nil should be returned if there is no default storage class.",
+			existingClasses: []*storagev1.StorageClass{testClass.DeepCopy()},
+			expClass:        "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var objs []k8sApiClient.Object
+			for _, class := range test.existingClasses {
+				objs = append(objs, class)
+			}
+			scheme := runtime.NewScheme()
+			_ = storagev1.AddToScheme(scheme)
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+			c := NewK8sClient(fakeClient, ctrl.Log.WithName("storageclass-test"))
+
+			class, err := c.GetDefaultStorageClass()
+			assert.NoError(err)
+			if test.expClass == "" {
+				assert.Nil(class)
+			} else {
+				assert.Equal(test.expClass, class.Name)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package k8s
+
+import (
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// IsKindInstalled checks if the kind is served by the api server, e.g. the CRDs of an optional operator are installed
+func (c *Client) IsKindInstalled(gvk schema.GroupVersionKind) (bool, error) {
+	if _, err := c.client.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// CreateOrUpdateUnstructured updates the object only if its labels or spec change
+func (c *Client) CreateOrUpdateUnstructured(obj *unstructured.Unstructured) error {
+	oldObj := &unstructured.Unstructured{}
+	oldObj.SetGroupVersionKind(obj.GroupVersionKind())
+	err := c.client.Get(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}, oldObj)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			if err = c.client.Create(ctx, obj); err != nil && !errors.IsAlreadyExists(err) {
+				return err
+			}
+			c.logger.V(1).Info("object create successfully", "kind", obj.GetKind(), "name", obj.GetName())
+			return nil
+		}
+		return err
+	}
+	if reflect.DeepEqual(oldObj.GetLabels(), obj.GetLabels()) && reflect.DeepEqual(oldObj.Object["spec"], obj.Object["spec"]) {
+		return nil
+	}
+	obj.SetResourceVersion(oldObj.GetResourceVersion())
+	if err = c.client.Update(ctx, obj); err != nil {
+		return err
+	}
+	c.logger.V(1).Info("object update successfully", "kind", obj.GetKind(), "name", obj.GetName())
+	return nil
+}
+
+func (c *Client) DeleteUnstructuredIfExists(gvk schema.GroupVersionKind, key types.NamespacedName) error {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetNamespace(key.Namespace)
+	obj.SetName(key.Name)
+	if err := c.client.Delete(ctx, obj); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	c.logger.V(1).Info("object delete successfully", "kind", gvk.Kind, "name", key.Name)
+	return nil
+}
@@ -0,0 +1,147 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sApiClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var testGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PodMonitor"}
+
+func newTestObject(port string, labels map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"podMetricsEndpoints": []interface{}{
+				map[string]interface{}{"port": port},
+			},
+		},
+	}}
+	obj.SetGroupVersionKind(testGVK)
+	obj.SetNamespace("unit-test")
+	obj.SetName("test")
+	obj.SetLabels(labels)
+	return obj
+}
+
+func TestIsKindInstalled(t *testing.T) {
+	tests := []struct {
+		name         string
+		registered   bool
+		expInstalled bool
+	}{
+		{
+			name:         "A registered kind should be installed.",
+			registered:   true,
+			expInstalled: true,
+		}, {
+			name:         "An unknown kind should not be installed.",
+			registered:   false,
+			expInstalled: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			mapper := meta.NewDefaultRESTMapper(nil)
+			if test.registered {
+				mapper.Add(testGVK, meta.RESTScopeNamespace)
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRESTMapper(mapper).Build()
+			c := NewK8sClient(fakeClient, ctrl.Log.WithName("unstructured-test"))
+
+			installed, err := c.IsKindInstalled(testGVK)
+			assert.NoError(err)
+			assert.Equal(test.expInstalled, installed)
+		})
+	}
+}
+
+func TestCreateOrUpdateUnstructured(t *testing.T) {
+	tests := []struct {
+		name        string
+		obj         *unstructured.Unstructured
+		existingObj *unstructured.Unstructured
+		expPort     string
+		expLabels   map[string]string
+	}{
+		{
+			name:      "A non existent object should be created.",
+			obj:       newTestObject("exporter", map[string]string{"a": "b"}),
+			expPort:   "exporter",
+			expLabels: map[string]string{"a": "b"},
+		}, {
+			name:        "The changed spec and labels should be updated.",
+			obj:         newTestObject("exporter", map[string]string{"a": "c"}),
+			existingObj: newTestObject("metrics", map[string]string{"a": "b"}),
+			expPort:     "exporter",
+			expLabels:   map[string]string{"a": "c"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var objs []k8sApiClient.Object
+			if test.existingObj != nil {
+				objs = append(objs, test.existingObj)
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build()
+			c := NewK8sClient(fakeClient, ctrl.Log.WithName("unstructured-test"))
+
+			assert.NoError(c.CreateOrUpdateUnstructured(test.obj))
+
+			obj := &unstructured.Unstructured{}
+			obj.SetGroupVersionKind(testGVK)
+			assert.NoError(fakeClient.Get(ctx, types.NamespacedName{Namespace: "unit-test", Name: "test"}, obj))
+			endpoints, _, _ := unstructured.NestedSlice(obj.Object, "spec", "podMetricsEndpoints")
+			assert.Equal(test.expPort, endpoints[0].(map[string]interface{})["port"])
+			assert.Equal(test.expLabels, obj.GetLabels())
+		})
+	}
+}
+
+func TestDeleteUnstructuredIfExists(t *testing.T) {
+	tests := []struct {
+		name        string
+		existingObj *unstructured.Unstructured
+	}{
+		{
+			name:        "An existing object should be deleted.",
+			existingObj: newTestObject("exporter", nil),
+		}, {
+			name:        "A non existent object should not return an error.",
+			existingObj: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var objs []k8sApiClient.Object
+			if test.existingObj != nil {
+				objs = append(objs, test.existingObj)
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build()
+			c := NewK8sClient(fakeClient, ctrl.Log.WithName("unstructured-test"))
+
+			key := types.NamespacedName{Namespace: "unit-test", Name: "test"}
+			assert.NoError(c.DeleteUnstructuredIfExists(testGVK, key))
+
+			obj := &unstructured.Unstructured{}
+			obj.SetGroupVersionKind(testGVK)
+			assert.Error(fakeClient.Get(ctx, key, obj))
+		})
+	}
+}
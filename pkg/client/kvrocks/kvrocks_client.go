@@ -32,11 +32,17 @@ const (
 	ControllerServiceName    = "controller-service"
 	ControllerPort           = 9379
 	ControllerDeploymentName = "kvrocks-controller"
+
+	// AnnounceIP and AnnouncePort are the configs of the address a slave reports to its master, they do not change
+	// the addresses in CLUSTER NODES
+	AnnounceIP   = "slave-announce-ip"
+	AnnouncePort = "slave-announce-port"
 )
 
 const ErrPassword = "ERR invalid password"
 
 type Node struct {
+	// IP is the address of the node, the stable dns name of the pod if it has one
 	IP       string
 	Role     string
 	PodIndex int
@@ -46,6 +52,8 @@ type Node struct {
 	Expected int
 	Failover bool
 	Migrate  []MigrateMsg
+	// Announce is the external address of the node in host:port if it is exposed
+	Announce string
 }
 
 type MigrateMsg struct {
@@ -53,6 +61,24 @@ type MigrateMsg struct {
 	Slots []int
 }
 
+// DiskUsage is the disk usage of a node in bytes
+type DiskUsage struct {
+	Capacity    int64
+	Used        int64
+	DBSize      int64
+	UsedPercent int
+}
+
+// ReplicationInfo is the replication section of INFO
+type ReplicationInfo struct {
+	Role string
+	// Offset is slave_repl_offset of a slave or master_repl_offset of a master
+	Offset int64
+	// LinkStatus is master_link_status of a slave
+	LinkStatus     string
+	SyncInProgress bool
+}
+
 type client struct {
 	logger logr.Logger
 }
@@ -68,14 +94,18 @@ type Client interface {
 	ChangePassword(ip string, password string, newPassword string) error
 	ClusterNodeInfo(ip string, password string) (*Node, error)
 	CreateMonitor(sentinelIP string, password string, master string, ip string, kvPass string) error
+	FailoverMonitor(sentinelIP string, sentinelPassword string, master string) error
 	GetConfig(ip string, password string, key string) (*string, error)
+	GetDiskUsage(ip string, password string) (*DiskUsage, error)
 	GetMaster(ip string, password string) (string, error)
 	GetMasterFromSentinel(sentinelIP string, sentinelPassword string, master string) (string, error)
 	GetOffset(ip string, password string) (int, error)
+	GetReplicationInfo(ip string, password string) (*ReplicationInfo, error)
 	NodeInfo(ip string, password string) (node Node, err error)
 	Ping(ip string, password string) bool
 	RemoveMonitor(sentinelIP string, password string, master string) error
 	ResetMonitor(sentinelIP string, sentinelPassword string, master string, password string) error
+	RewriteConfig(ip string, password string) error
 	SetConfig(ip string, password string, key string, value string) error
 	SlaveOf(slaveIP string, masterIP string, password string) error
 	SubOdownMsg(ip string, password string) (*redisClient.PubSub, func())
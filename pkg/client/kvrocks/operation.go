@@ -0,0 +1,133 @@
+package kvrocks
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	ComponentKVRocks    = "kvrocks"
+	ComponentSentinel   = "sentinel"
+	ComponentController = "controller"
+)
+
+const redacted = "******"
+
+// Operation is a mutating command sent to kvrocks, sentinel or the controller, it never contains the passwords.
+// Count is the number of the same consecutive commands merged into it, FirstTime and Time are the times of the first
+// and the last one
+type Operation struct {
+	Time      time.Time  `json:"time"`
+	FirstTime *time.Time `json:"firstTime,omitempty"`
+	Component string     `json:"component"`
+	Target    string     `json:"target"`
+	Command   string     `json:"command"`
+	Args      []string   `json:"args,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	Count     int        `json:"count,omitempty"`
+}
+
+// OperationRecorder receives the operations after they are sent
+type OperationRecorder func(op Operation)
+
+// NewOperation returns the operation with the error of the command
+func NewOperation(component, target, command string, err error, args ...string) Operation {
+	op := Operation{
+		Time:      time.Now().UTC(),
+		Component: component,
+		Target:    target,
+		Command:   command,
+		Args:      args,
+	}
+	if err != nil {
+		op.Error = err.Error()
+	}
+	return op
+}
+
+// SameAs checks if the operations are the same command with the same result
+func (op *Operation) SameAs(other *Operation) bool {
+	if op.Component != other.Component || op.Target != other.Target || op.Command != other.Command ||
+		op.Error != other.Error || len(op.Args) != len(other.Args) {
+		return false
+	}
+	for i := range op.Args {
+		if op.Args[i] != other.Args[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// RedactConfig hides the value of the config keys holding secrets
+func RedactConfig(key, value string) string {
+	if strings.Contains(strings.ToLower(key), "pass") || strings.Contains(strings.ToLower(key), "auth") {
+		return redacted
+	}
+	return value
+}
+
+// recordingClient records the mutating commands of the wrapped client
+type recordingClient struct {
+	Client
+	record OperationRecorder
+}
+
+// NewRecordingClient returns the client which passes the mutating commands to the recorder
+func NewRecordingClient(client Client, record OperationRecorder) Client {
+	return &recordingClient{Client: client, record: record}
+}
+
+func (c *recordingClient) ChangeMyselfToMaster(ip string, password string) error {
+	err := c.Client.ChangeMyselfToMaster(ip, password)
+	c.record(NewOperation(ComponentKVRocks, ip, "SLAVEOF", err, "NO", "ONE"))
+	return err
+}
+
+func (c *recordingClient) ChangePassword(ip string, password string, newPassword string) error {
+	err := c.Client.ChangePassword(ip, password, newPassword)
+	c.record(NewOperation(ComponentKVRocks, ip, "CONFIG SET", err, "requirepass", redacted))
+	return err
+}
+
+func (c *recordingClient) CreateMonitor(sentinelIP string, password string, master string, ip string, kvPass string) error {
+	err := c.Client.CreateMonitor(sentinelIP, password, master, ip, kvPass)
+	c.record(NewOperation(ComponentSentinel, sentinelIP, "SENTINEL MONITOR", err, master, ip))
+	return err
+}
+
+func (c *recordingClient) FailoverMonitor(sentinelIP string, sentinelPassword string, master string) error {
+	err := c.Client.FailoverMonitor(sentinelIP, sentinelPassword, master)
+	c.record(NewOperation(ComponentSentinel, sentinelIP, "SENTINEL FAILOVER", err, master))
+	return err
+}
+
+func (c *recordingClient) RemoveMonitor(sentinelIP string, password string, master string) error {
+	err := c.Client.RemoveMonitor(sentinelIP, password, master)
+	c.record(NewOperation(ComponentSentinel, sentinelIP, "SENTINEL REMOVE", err, master))
+	return err
+}
+
+func (c *recordingClient) ResetMonitor(sentinelIP string, sentinelPassword string, master string, password string) error {
+	err := c.Client.ResetMonitor(sentinelIP, sentinelPassword, master, password)
+	c.record(NewOperation(ComponentSentinel, sentinelIP, "SENTINEL RESET", err, master))
+	return err
+}
+
+func (c *recordingClient) RewriteConfig(ip string, password string) error {
+	err := c.Client.RewriteConfig(ip, password)
+	c.record(NewOperation(ComponentKVRocks, ip, "CONFIG REWRITE", err))
+	return err
+}
+
+func (c *recordingClient) SetConfig(ip string, password string, key string, value string) error {
+	err := c.Client.SetConfig(ip, password, key, value)
+	c.record(NewOperation(ComponentKVRocks, ip, "CONFIG SET", err, key, RedactConfig(key, value)))
+	return err
+}
+
+func (c *recordingClient) SlaveOf(slaveIP string, masterIP string, password string) error {
+	err := c.Client.SlaveOf(slaveIP, masterIP, password)
+	c.record(NewOperation(ComponentKVRocks, slaveIP, "SLAVEOF", err, masterIP))
+	return err
+}
@@ -0,0 +1,89 @@
+package kvrocks
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactConfig(t *testing.T) {
+	tests := []struct {
+		key      string
+		value    string
+		expValue string
+	}{
+		{key: "requirepass", value: "secret", expValue: redacted},
+		{key: "masterauth", value: "secret", expValue: redacted},
+		{key: "RequirePass", value: "secret", expValue: redacted},
+		{key: "maxclients", value: "100", expValue: "100"},
+		{key: "slave-announce-ip", value: "10.0.0.1", expValue: "10.0.0.1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.key, func(t *testing.T) {
+			assert.Equal(t, test.expValue, RedactConfig(test.key, test.value))
+		})
+	}
+}
+
+func TestOperationSameAs(t *testing.T) {
+	op := NewOperation(ComponentKVRocks, "10.0.0.1", "CONFIG SET", nil, "maxclients", "100")
+
+	tests := []struct {
+		name    string
+		other   Operation
+		expSame bool
+	}{
+		{
+			name:    "The same command at another time should be the same.",
+			other:   NewOperation(ComponentKVRocks, "10.0.0.1", "CONFIG SET", nil, "maxclients", "100"),
+			expSame: true,
+		}, {
+			name:  "A command to another target should not be the same.",
+			other: NewOperation(ComponentKVRocks, "10.0.0.2", "CONFIG SET", nil, "maxclients", "100"),
+		}, {
+			name:  "A command with other args should not be the same.",
+			other: NewOperation(ComponentKVRocks, "10.0.0.1", "CONFIG SET", nil, "maxclients", "200"),
+		}, {
+			name:  "A failed command should not be the same as a succeeded one.",
+			other: NewOperation(ComponentKVRocks, "10.0.0.1", "CONFIG SET", errors.New("timeout"), "maxclients", "100"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expSame, op.SameAs(&test.other))
+		})
+	}
+}
+
+// stubClient answers the commands used by the tests without connecting to kvrocks
+type stubClient struct {
+	Client
+}
+
+func (c *stubClient) ChangePassword(ip string, password string, newPassword string) error {
+	return errors.New("timeout")
+}
+
+func (c *stubClient) SetConfig(ip string, password string, key string, value string) error {
+	return nil
+}
+
+func TestRecordingClient(t *testing.T) {
+	assert := assert.New(t)
+
+	var operations []Operation
+	client := NewRecordingClient(&stubClient{}, func(op Operation) {
+		operations = append(operations, op)
+	})
+	_ = client.ChangePassword("10.0.0.1", "old", "new")
+	_ = client.SetConfig("10.0.0.1", "old", "masterauth", "new")
+	if assert.Len(operations, 2) {
+		assert.Equal([]string{"requirepass", redacted}, operations[0].Args)
+		assert.Equal([]string{"masterauth", redacted}, operations[1].Args)
+		assert.Equal("timeout", operations[0].Error)
+		assert.Empty(operations[1].Error)
+	}
+}
@@ -35,6 +35,13 @@ func (s *client) CreateMonitor(sentinelIP, password, master, ip, kvPass string)
 	c := kvrocksSentinelClient(sentinelIP, password)
 	defer c.Close()
 	var err error
+	// sentinels created before the config file enables hostnames
+	for _, option := range []string{"resolve-hostnames", "announce-hostnames"} {
+		cmd := redis.NewStatusCmd(ctx, "sentinel", "config", "set", option, "yes")
+		if err = c.Process(ctx, cmd); err != nil {
+			return err
+		}
+	}
 	if err = c.Monitor(ctx, master, ip, strconv.Itoa(KVRocksPort), strconv.Itoa(Quorum)).Err(); err != nil {
 		return err
 	}
@@ -66,6 +73,17 @@ func (s *client) ResetMonitor(sentinelIP, sentinelPassword, master, password str
 	return nil
 }
 
+// FailoverMonitor forces the sentinel to fail over the master without asking the other sentinels
+func (s *client) FailoverMonitor(sentinelIP, sentinelPassword, master string) error {
+	c := kvrocksSentinelClient(sentinelIP, sentinelPassword)
+	defer c.Close()
+	if err := c.Failover(ctx, master).Err(); err != nil {
+		return err
+	}
+	s.logger.V(1).Info("sentinel failover master successfully", "master", master)
+	return nil
+}
+
 // SubOdownMsg subscribes the odown message from sentinel
 func (s *client) SubOdownMsg(ip, password string) (*redis.PubSub, func()) {
 	c := kvrocksSentinelClient(ip, password)
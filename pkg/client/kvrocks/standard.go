@@ -53,6 +53,17 @@ func (s *client) SetConfig(ip, password string, key, value string) error {
 	return nil
 }
 
+// RewriteConfig persists the running config to the config file
+func (s *client) RewriteConfig(ip, password string) error {
+	c := kvrocksClient(ip, password)
+	defer c.Close()
+	if err := c.ConfigRewrite(ctx).Err(); err != nil {
+		return err
+	}
+	s.logger.V(1).Info("kvrocks rewrite config successfully", "ip", ip)
+	return nil
+}
+
 // ChangePassword changes the password
 func (s *client) ChangePassword(ip, password, newPassword string) error {
 	c := kvrocksClient(ip, password)
@@ -87,15 +98,22 @@ func (s *client) GetMaster(ip, password string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	match := regexp.MustCompile(`master_host:([0-9a-zA-Z\.]+)`).FindStringSubmatch(info)
-	if len(match) == 0 {
-		return "", nil
-	}
-	master := match[1]
+	master := parseMasterHost(info)
 	s.logger.V(1).Info("get master", "masterIP", master, "slaveIP", ip)
 	return master, nil
 }
 
+var masterHostRegexp = regexp.MustCompile(`master_host:([0-9a-zA-Z\.\-]+)`)
+
+// parseMasterHost returns the master_host of INFO replication, which is an ip or the dns name of the master pod
+func parseMasterHost(info string) string {
+	match := masterHostRegexp.FindStringSubmatch(info)
+	if len(match) == 0 {
+		return ""
+	}
+	return match[1]
+}
+
 // SlaveOf sets the slave of the specified master
 func (s *client) SlaveOf(slaveIP, masterIP, password string) error {
 	c := kvrocksClient(slaveIP, password)
@@ -107,7 +125,7 @@ func (s *client) SlaveOf(slaveIP, masterIP, password string) error {
 	return nil
 }
 
-// GetOffset returns the replication offset
+// GetOffset returns the replication offset, slave_repl_offset of a slave or master_repl_offset of a master
 func (s *client) GetOffset(ip, password string) (int, error) {
 	c := kvrocksClient(ip, password)
 	defer c.Close()
@@ -115,14 +133,83 @@ func (s *client) GetOffset(ip, password string) (int, error) {
 	if err != nil {
 		return -1, err
 	}
+	masterOffset := -1
 	lines := strings.Split(msg, "\r\n")
 	for _, line := range lines {
 		if strings.HasPrefix(line, "slave_repl_offset") {
 			offset, _ := strconv.Atoi(strings.Split(line, ":")[1])
 			return offset, nil
 		}
+		if strings.HasPrefix(line, "master_repl_offset") {
+			masterOffset, _ = strconv.Atoi(strings.Split(line, ":")[1])
+		}
+	}
+	return masterOffset, nil
+}
+
+// GetReplicationInfo returns the role, offset and master link of the node
+func (s *client) GetReplicationInfo(ip, password string) (*ReplicationInfo, error) {
+	c := kvrocksClient(ip, password)
+	defer c.Close()
+	msg, err := c.Info(ctx, "replication").Result()
+	if err != nil {
+		return nil, err
+	}
+	info := &ReplicationInfo{}
+	for _, line := range strings.Split(msg, "\r\n") {
+		fields := strings.SplitN(line, ":", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		switch fields[0] {
+		case "role":
+			info.Role = fields[1]
+		case "master_link_status":
+			info.LinkStatus = fields[1]
+		case "master_sync_in_progress":
+			info.SyncInProgress = fields[1] == "1"
+		case "slave_repl_offset":
+			info.Offset, _ = strconv.ParseInt(fields[1], 10, 64)
+		case "master_repl_offset":
+			// a slave reports its own offset as slave_repl_offset
+			if info.Role != RoleSlaver {
+				info.Offset, _ = strconv.ParseInt(fields[1], 10, 64)
+			}
+		}
+	}
+	return info, nil
+}
+
+// GetDiskUsage returns the disk usage reported by INFO
+func (s *client) GetDiskUsage(ip, password string) (*DiskUsage, error) {
+	c := kvrocksClient(ip, password)
+	defer c.Close()
+	msg, err := c.Info(ctx).Result()
+	if err != nil {
+		return nil, err
+	}
+	usage := &DiskUsage{}
+	for _, line := range strings.Split(msg, "\r\n") {
+		fields := strings.SplitN(line, ":", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		value := strings.TrimSuffix(fields[1], "%")
+		switch fields[0] {
+		case "disk_capacity":
+			usage.Capacity, _ = strconv.ParseInt(value, 10, 64)
+		case "used_disk_size":
+			usage.Used, _ = strconv.ParseInt(value, 10, 64)
+		case "used_db_size":
+			usage.DBSize, _ = strconv.ParseInt(value, 10, 64)
+		case "used_disk_percent":
+			usage.UsedPercent, _ = strconv.Atoi(value)
+		}
+	}
+	if usage.Capacity > 0 {
+		usage.UsedPercent = int(usage.Used * 100 / usage.Capacity)
 	}
-	return -1, nil
+	return usage, nil
 }
 
 // Ping checks if the node is alive
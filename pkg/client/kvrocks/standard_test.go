@@ -0,0 +1,34 @@
+package kvrocks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMasterHost(t *testing.T) {
+	tests := []struct {
+		name    string
+		info    string
+		expHost string
+	}{
+		{
+			name:    "The ip of the master should be parsed.",
+			info:    "# Replication\r\nrole:slave\r\nmaster_host:10.0.0.1\r\nmaster_port:6379\r\n",
+			expHost: "10.0.0.1",
+		}, {
+			name:    "The dns name of the master should be parsed with its dashes.",
+			info:    "# Replication\r\nrole:slave\r\nmaster_host:test-0-0.test-0-headless.unit-test.svc.cluster.local\r\nmaster_port:6379\r\n",
+			expHost: "test-0-0.test-0-headless.unit-test.svc.cluster.local",
+		}, {
+			name: "A master should have no master host.",
+			info: "# Replication\r\nrole:master\r\nconnected_slaves:1\r\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expHost, parseMasterHost(test.info))
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package workload
+
+import (
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/k8s"
+)
+
+// kruiseBackend manages the OpenKruise advanced statefulSets
+type kruiseBackend struct {
+	*k8s.Client
+}
+
+func (b *kruiseBackend) SupportReserveOrdinals() bool {
+	return true
+}
@@ -0,0 +1,192 @@
+package workload
+
+import (
+	"github.com/openkruise/kruise-api/apps/pub"
+	kruise "github.com/openkruise/kruise-api/apps/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/k8s"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+// statefulSetBackend manages the native apps/v1 statefulSets, pods are updated by rolling update and
+// scaling down removes the pods of the largest ordinals
+type statefulSetBackend struct {
+	k8s *k8s.Client
+}
+
+func (b *statefulSetBackend) SupportReserveOrdinals() bool {
+	return false
+}
+
+func (b *statefulSetBackend) CreateIfNotExistsStatefulSet(sts *kruise.StatefulSet) error {
+	return b.k8s.CreateIfNotExistsNativeStatefulSet(toNative(sts))
+}
+
+func (b *statefulSetBackend) CreateOrUpdateStatefulSet(sts *kruise.StatefulSet) error {
+	oldSts, err := b.k8s.GetNativeStatefulSet(types.NamespacedName{
+		Namespace: sts.Namespace,
+		Name:      sts.Name,
+	})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return b.CreateIfNotExistsStatefulSet(sts)
+		}
+		return err
+	}
+	return b.update(oldSts, sts)
+}
+
+func (b *statefulSetBackend) CreateStatefulSetOrUpdateTemplate(sts *kruise.StatefulSet) error {
+	oldSts, err := b.k8s.GetNativeStatefulSet(types.NamespacedName{
+		Namespace: sts.Namespace,
+		Name:      sts.Name,
+	})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// the statefulSet recreated with orphan pods keeps their readiness gates
+			pods, err := b.k8s.ListPods(sts.Namespace, sts.Spec.Selector.MatchLabels)
+			if err != nil {
+				return err
+			}
+			if len(pods.Items) != 0 {
+				resources.KeepReadinessGates(&sts.Spec.Template, pods.Items[0].Spec.ReadinessGates)
+			}
+			return b.CreateIfNotExistsStatefulSet(sts)
+		}
+		return err
+	}
+	switch k8s.CompareTemplate(&oldSts.ObjectMeta, &sts.ObjectMeta, oldSts.Spec.Template.Spec.Containers, sts.Spec.Template.Spec.Containers) {
+	case k8s.TemplateSame:
+		return nil
+	case k8s.TemplateUnhashed:
+		k8s.CopyTemplateHash(&oldSts.ObjectMeta, &sts.ObjectMeta)
+		return b.k8s.UpdateNativeStatefulSet(oldSts)
+	}
+	return b.update(oldSts, sts)
+}
+
+func (b *statefulSetBackend) DeleteStatefulSetIfExists(key types.NamespacedName) error {
+	if err := b.k8s.DeleteNativeStatefulSet(key); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (b *statefulSetBackend) DeleteStatefulSetOrphan(sts *kruise.StatefulSet) error {
+	return b.k8s.DeleteNativeStatefulSetOrphan(toNative(sts))
+}
+
+func (b *statefulSetBackend) GetStatefulSet(key types.NamespacedName) (*kruise.StatefulSet, error) {
+	sts, err := b.k8s.GetNativeStatefulSet(key)
+	if err != nil {
+		return nil, err
+	}
+	return fromNative(sts), nil
+}
+
+func (b *statefulSetBackend) ListStatefulSetPVC(key types.NamespacedName) (*corev1.PersistentVolumeClaimList, error) {
+	sts, err := b.k8s.GetNativeStatefulSet(key)
+	if err != nil {
+		return nil, err
+	}
+	return b.k8s.ListPVC(key.Namespace, sts.Spec.Selector.MatchLabels)
+}
+
+func (b *statefulSetBackend) ListStatefulSetPods(key types.NamespacedName) (*corev1.PodList, error) {
+	sts, err := b.k8s.GetNativeStatefulSet(key)
+	if err != nil {
+		return nil, err
+	}
+	return b.k8s.ListPods(key.Namespace, sts.Spec.Selector.MatchLabels)
+}
+
+func (b *statefulSetBackend) ListStatefulSets(namespace string, labels map[string]string) (*kruise.StatefulSetList, error) {
+	stsList, err := b.k8s.ListNativeStatefulSets(namespace, labels)
+	if err != nil {
+		return nil, err
+	}
+	result := &kruise.StatefulSetList{}
+	for i := range stsList.Items {
+		result.Items = append(result.Items, *fromNative(&stsList.Items[i]))
+	}
+	return result, nil
+}
+
+func (b *statefulSetBackend) UpdateStatefulSet(sts *kruise.StatefulSet) error {
+	oldSts, err := b.k8s.GetNativeStatefulSet(types.NamespacedName{
+		Namespace: sts.Namespace,
+		Name:      sts.Name,
+	})
+	if err != nil {
+		return err
+	}
+	return b.update(oldSts, sts)
+}
+
+// update changes the mutable fields of the native statefulSet
+func (b *statefulSetBackend) update(oldSts *appsv1.StatefulSet, sts *kruise.StatefulSet) error {
+	native := toNative(sts)
+	oldSts.Labels = native.Labels
+	k8s.CopyTemplateHash(&oldSts.ObjectMeta, &native.ObjectMeta)
+	oldSts.Spec.Replicas = native.Spec.Replicas
+	resources.KeepReadinessGates(&native.Spec.Template, oldSts.Spec.Template.Spec.ReadinessGates)
+	oldSts.Spec.Template = native.Spec.Template
+	oldSts.Spec.UpdateStrategy = native.Spec.UpdateStrategy
+	return b.k8s.UpdateNativeStatefulSet(oldSts)
+}
+
+func toNative(sts *kruise.StatefulSet) *appsv1.StatefulSet {
+	template := sts.Spec.Template.DeepCopy()
+	// the in-place update readiness gate is only set by kruise, pods would never be ready with it
+	var gates []corev1.PodReadinessGate
+	for _, gate := range template.Spec.ReadinessGates {
+		if gate.ConditionType != pub.InPlaceUpdateReady {
+			gates = append(gates, gate)
+		}
+	}
+	template.Spec.ReadinessGates = gates
+	return &appsv1.StatefulSet{
+		ObjectMeta: *sts.ObjectMeta.DeepCopy(),
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:             sts.Spec.Replicas,
+			Selector:             sts.Spec.Selector,
+			Template:             *template,
+			VolumeClaimTemplates: sts.Spec.VolumeClaimTemplates,
+			ServiceName:          sts.Spec.ServiceName,
+			PodManagementPolicy:  sts.Spec.PodManagementPolicy,
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type: appsv1.RollingUpdateStatefulSetStrategyType,
+			},
+			RevisionHistoryLimit: sts.Spec.RevisionHistoryLimit,
+		},
+	}
+}
+
+func fromNative(sts *appsv1.StatefulSet) *kruise.StatefulSet {
+	return &kruise.StatefulSet{
+		ObjectMeta: *sts.ObjectMeta.DeepCopy(),
+		Spec: kruise.StatefulSetSpec{
+			Replicas:             sts.Spec.Replicas,
+			Selector:             sts.Spec.Selector,
+			Template:             sts.Spec.Template,
+			VolumeClaimTemplates: sts.Spec.VolumeClaimTemplates,
+			ServiceName:          sts.Spec.ServiceName,
+			PodManagementPolicy:  sts.Spec.PodManagementPolicy,
+			RevisionHistoryLimit: sts.Spec.RevisionHistoryLimit,
+		},
+		Status: kruise.StatefulSetStatus{
+			ObservedGeneration: sts.Status.ObservedGeneration,
+			Replicas:           sts.Status.Replicas,
+			ReadyReplicas:      sts.Status.ReadyReplicas,
+			AvailableReplicas:  sts.Status.AvailableReplicas,
+			CurrentReplicas:    sts.Status.CurrentReplicas,
+			UpdatedReplicas:    sts.Status.UpdatedReplicas,
+			CurrentRevision:    sts.Status.CurrentRevision,
+			UpdateRevision:     sts.Status.UpdateRevision,
+		},
+	}
+}
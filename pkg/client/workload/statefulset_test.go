@@ -0,0 +1,88 @@
+package workload
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openkruise/kruise-api/apps/pub"
+	kruise "github.com/openkruise/kruise-api/apps/v1beta1"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/k8s"
+)
+
+func TestStatefulSetBackend(t *testing.T) {
+	assert := assert.New(t)
+	ns := "unit-test"
+	labels := map[string]string{"app": "test"}
+	replicas := int32(3)
+	sts := &kruise.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: ns,
+			Labels:    labels,
+		},
+		Spec: kruise.StatefulSetSpec{
+			Replicas:        &replicas,
+			ReserveOrdinals: []int{1},
+			Selector:        &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers:     []corev1.Container{{Name: "kvrocks", Image: "image1"}, {Name: "exporter", Image: "image2"}},
+					ReadinessGates: []corev1.PodReadinessGate{{ConditionType: pub.InPlaceUpdateReady}},
+				},
+			},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-0",
+			Namespace: ns,
+			Labels:    labels,
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pod).Build()
+	backend := &statefulSetBackend{k8s: k8s.NewK8sClient(fakeClient, ctrl.Log.WithName("workload-test"))}
+	key := types.NamespacedName{Namespace: ns, Name: "test"}
+
+	assert.False(backend.SupportReserveOrdinals())
+	assert.NoError(backend.CreateStatefulSetOrUpdateTemplate(sts.DeepCopy()))
+
+	native := &appsv1.StatefulSet{}
+	assert.NoError(fakeClient.Get(context.TODO(), key, native))
+	assert.Empty(native.Spec.Template.Spec.ReadinessGates)
+	assert.Equal(replicas, *native.Spec.Replicas)
+
+	// scaling down only changes replicas
+	newReplicas := int32(2)
+	update := sts.DeepCopy()
+	update.Spec.Replicas = &newReplicas
+	assert.NoError(backend.CreateOrUpdateStatefulSet(update))
+	got, err := backend.GetStatefulSet(key)
+	assert.NoError(err)
+	assert.Equal(newReplicas, *got.Spec.Replicas)
+	assert.Empty(got.Spec.ReserveOrdinals)
+
+	pods, err := backend.ListStatefulSetPods(key)
+	assert.NoError(err)
+	assert.Len(pods.Items, 1)
+
+	stsList, err := backend.ListStatefulSets(ns, labels)
+	assert.NoError(err)
+	assert.Len(stsList.Items, 1)
+
+	assert.NoError(backend.DeleteStatefulSetIfExists(key))
+	assert.NoError(backend.DeleteStatefulSetIfExists(key))
+}
@@ -0,0 +1,50 @@
+package workload
+
+import (
+	kruise "github.com/openkruise/kruise-api/apps/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/k8s"
+)
+
+// Backend manages the statefulSets of kvrocks. The statefulSets are described by the kruise type,
+// the backends without kruise convert it to their own workload
+type Backend interface {
+	// SupportReserveOrdinals returns true if pods of any ordinal can be removed by ReserveOrdinals,
+	// otherwise scaling down removes the pods of the largest ordinals
+	SupportReserveOrdinals() bool
+
+	CreateIfNotExistsStatefulSet(sts *kruise.StatefulSet) error
+	CreateOrUpdateStatefulSet(sts *kruise.StatefulSet) error
+	CreateStatefulSetOrUpdateTemplate(sts *kruise.StatefulSet) error
+	DeleteStatefulSetIfExists(key types.NamespacedName) error
+	DeleteStatefulSetOrphan(sts *kruise.StatefulSet) error
+	GetStatefulSet(key types.NamespacedName) (*kruise.StatefulSet, error)
+	ListStatefulSetPVC(key types.NamespacedName) (*corev1.PersistentVolumeClaimList, error)
+	ListStatefulSetPods(key types.NamespacedName) (*corev1.PodList, error)
+	ListStatefulSets(namespace string, labels map[string]string) (*kruise.StatefulSetList, error)
+	UpdateStatefulSet(sts *kruise.StatefulSet) error
+}
+
+// DefaultBackend is used by the kvrocks which neither set spec.workloadBackend nor recorded one in status
+var DefaultBackend = kvrocksv1alpha1.KruiseWorkloadBackend
+
+// GetBackendType returns the backend recorded in status, then the one in spec, then the default
+func GetBackendType(instance *kvrocksv1alpha1.KVRocks) kvrocksv1alpha1.WorkloadBackendType {
+	if instance.Status.WorkloadBackend != "" {
+		return instance.Status.WorkloadBackend
+	}
+	if instance.Spec.WorkloadBackend != "" {
+		return instance.Spec.WorkloadBackend
+	}
+	return DefaultBackend
+}
+
+func NewBackend(k8s *k8s.Client, instance *kvrocksv1alpha1.KVRocks) Backend {
+	if GetBackendType(instance) == kvrocksv1alpha1.StatefulSetWorkloadBackend {
+		return &statefulSetBackend{k8s: k8s}
+	}
+	return &kruiseBackend{Client: k8s}
+}
@@ -13,7 +13,10 @@ func (h *KVRocksClusterHandler) ensureController() error {
 		return err
 	}
 	etcd := resources.NewEtcdStatefulSet(h.instance)
-	if err := h.k8s.CreateIfNotExistsNativeStatefulSet(etcd); err != nil {
+	if err := h.k8s.CreateNativeStatefulSetOrUpdateTemplate(etcd); err != nil {
+		return err
+	}
+	if err := h.k8s.CreateOrUpdatePDB(resources.NewEtcdPDB(h.instance)); err != nil {
 		return err
 	}
 	// ensure etcd
@@ -39,7 +42,10 @@ func (h *KVRocksClusterHandler) ensureController() error {
 		return err
 	}
 	controllerDep := resources.NewKVRocksControllerDeployment(h.instance)
-	if err := h.k8s.CreateIfNotExistsDeployment(controllerDep); err != nil {
+	if err := h.k8s.CreateDeploymentOrUpdateTemplate(controllerDep); err != nil {
+		return err
+	}
+	if err := h.k8s.CreateOrUpdatePDB(resources.NewKVRocksControllerPDB(h.instance)); err != nil {
 		return err
 	}
 	// ensure controller
@@ -84,6 +90,13 @@ func (h *KVRocksClusterHandler) removeController() (bool, error) {
 		return false, err
 	}
 
+	if err := h.k8s.DeletePDBIfExists(types.NamespacedName{
+		Namespace: h.instance.Namespace,
+		Name:      kvrocks.ControllerDeploymentName,
+	}); err != nil {
+		return false, err
+	}
+
 	if err := h.k8s.DeleteConfigMap(types.NamespacedName{
 		Namespace: h.instance.Namespace,
 		Name:      "controller-config",
@@ -106,6 +119,13 @@ func (h *KVRocksClusterHandler) removeController() (bool, error) {
 		return false, err
 	}
 
+	if err := h.k8s.DeletePDBIfExists(types.NamespacedName{
+		Namespace: h.instance.Namespace,
+		Name:      kvrocks.EtcdStatefulName,
+	}); err != nil {
+		return false, err
+	}
+
 	return false, nil
 }
 
@@ -3,12 +3,15 @@ package cluster
 import (
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 
 	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
 	"github.com/RocksLabs/kvrocks-operator/pkg/client/controller"
 	"github.com/RocksLabs/kvrocks-operator/pkg/client/k8s"
 	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/workload"
 	"github.com/RocksLabs/kvrocks-operator/pkg/controllers/common"
+	"github.com/RocksLabs/kvrocks-operator/pkg/metrics"
 	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
 )
 
@@ -16,6 +19,7 @@ type KVRocksClusterHandler struct {
 	instance         *kvrocksv1alpha1.KVRocks
 	k8s              *k8s.Client
 	kvrocks          kvrocks.Client
+	workload         workload.Backend
 	log              logr.Logger
 	password         string
 	requeue          bool
@@ -24,6 +28,7 @@ type KVRocksClusterHandler struct {
 	version          int
 	masters          map[string]*kvrocks.Node
 	controllerClient *controller.Client
+	recorder         record.EventRecorder
 }
 
 func NewKVRocksClusterHandler(
@@ -33,55 +38,98 @@ func NewKVRocksClusterHandler(
 	key types.NamespacedName,
 	instance *kvrocksv1alpha1.KVRocks,
 	controllerClient *controller.Client,
+	recorder record.EventRecorder,
 ) *KVRocksClusterHandler {
 	return &KVRocksClusterHandler{
 		instance:         instance,
 		k8s:              k8s,
 		kvrocks:          kvrocks,
+		workload:         workload.NewBackend(k8s, instance),
 		log:              log,
 		requeue:          false,
 		key:              key,
 		controllerClient: controllerClient,
+		recorder:         recorder,
 	}
 }
 
 func (h *KVRocksClusterHandler) Handle() (error, bool) {
 	// kvrocks-controller
-	err := h.ensureController()
+	err := h.step("ensureController", h.ensureController)
 	if err != nil || h.requeue {
 		return err, false
 	}
-	if h.instance.Status.Shrink != nil {
-		err := h.cleanStatefulSet()
+	if h.instance.Status.Shrink != nil && !resources.IsUnderMaintenance(h.instance) {
+		err := h.step("cleanStatefulSet", h.cleanStatefulSet)
 		if err != nil || h.requeue {
 			return err, false
 		}
 	}
-	err = h.ensureKubernetes()
+	err = h.step("ensureKubernetes", h.ensureKubernetes)
 	if err != nil || h.requeue {
 		return err, false
 	}
-	err = h.ensureFailover()
+	err = h.step("ensureExpose", h.ensureExpose)
 	if err != nil || h.requeue {
 		return err, false
 	}
-	err = h.ensureKVRocksStatus()
+	err = h.step("ensureFailover", h.ensureFailover)
 	if err != nil || h.requeue {
 		return err, false
 	}
-	err = h.ensureMigrate()
+	err = h.step("ensureKVRocksStatus", h.ensureKVRocksStatus)
 	if err != nil || h.requeue {
 		return err, false
 	}
-	err = h.ensureShrink()
+	err = h.step("ensureOperation", h.ensureOperation)
 	if err != nil || h.requeue {
 		return err, false
 	}
-	err = h.ensureSentinel()
+	err = h.step("ensureNodeDrain", h.ensureNodeDrain)
 	if err != nil || h.requeue {
 		return err, false
 	}
-	err = h.cleanPersistentVolumeClaim()
+	err = h.step("ensureReadReplicas", h.ensureReadReplicas)
+	if err != nil || h.requeue {
+		return err, false
+	}
+	err = h.step("ensureReseed", h.ensureReseed)
+	if err != nil || h.requeue {
+		return err, false
+	}
+	err = h.step("ensureConnectionSecret", h.ensureConnectionSecret)
+	if err != nil || h.requeue {
+		return err, false
+	}
+	err = h.step("ensureMonitoring", h.ensureMonitoring)
+	if err != nil || h.requeue {
+		return err, false
+	}
+	err = h.step("ensureMigrate", h.ensureMigrate)
+	if err != nil || h.requeue {
+		return err, false
+	}
+	err = h.step("ensureRestart", h.ensureRestart)
+	if err != nil || h.requeue {
+		return err, false
+	}
+	err = h.step("ensureZoneBalance", h.ensureZoneBalance)
+	if err != nil || h.requeue {
+		return err, false
+	}
+	err = h.step("ensureStorage", h.ensureStorage)
+	if err != nil || h.requeue {
+		return err, false
+	}
+	err = h.step("ensureShrink", h.ensureShrink)
+	if err != nil || h.requeue {
+		return err, false
+	}
+	err = h.step("ensureSentinel", h.ensureSentinel)
+	if err != nil || h.requeue {
+		return err, false
+	}
+	err = h.step("cleanPersistentVolumeClaim", h.cleanPersistentVolumeClaim)
 	if err != nil || h.requeue {
 		return err, false
 	}
@@ -92,11 +140,19 @@ func (h *KVRocksClusterHandler) Requeue() bool {
 	return h.requeue
 }
 
+// step runs the reconcile step and observes its duration
+func (h *KVRocksClusterHandler) step(name string, fn func() error) error {
+	return metrics.ObserveStep(h.instance.Spec.Type, name, fn)
+}
+
 func (h *KVRocksClusterHandler) Finializer() error {
+	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder)
+	if err := commHandler.RetainPVCs(); err != nil {
+		return err
+	}
 	if _, ok := h.instance.Labels[resources.MonitoredBy]; !ok {
 		return nil
 	}
-	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password)
 	_, masterName := resources.ParseRedisName(h.instance.Name)
 	for index := 0; index < int(h.instance.Spec.Master); index++ {
 		requeue, err := commHandler.RemoveMonitor(masterName, index)
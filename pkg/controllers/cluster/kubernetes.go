@@ -21,7 +21,11 @@ func (h *KVRocksClusterHandler) ensureKubernetes() error {
 		return err
 	}
 	service := resources.NewKVRocksService(h.instance)
-	if err := h.k8s.CreateIfNotExistsService(service); err != nil {
+	if err := h.k8s.CreateOrUpdateService(service); err != nil {
+		return err
+	}
+	readService := resources.NewReadService(h.instance)
+	if err := h.k8s.CreateOrUpdateService(readService); err != nil {
 		return err
 	}
 	oldCM, err := h.k8s.GetConfigMap(h.key)
@@ -33,17 +37,33 @@ func (h *KVRocksClusterHandler) ensureKubernetes() error {
 		return err
 	}
 	h.password = oldCM.Data["password"]
+	maintenance := resources.IsUnderMaintenance(h.instance)
 	for i := 0; i < int(h.instance.Spec.Master); i++ {
 		sts := resources.NewClusterStatefulSet(h.instance, i)
-		if err = h.k8s.CreateStatefulSetOrUpdateImage(sts); err != nil {
+		// no shard is added under maintenance
+		if maintenance {
+			if _, err = h.workload.GetStatefulSet(types.NamespacedName{Namespace: sts.Namespace, Name: sts.Name}); errors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return err
+			}
+		}
+		headless := resources.NewHeadlessService(h.instance, sts.Name)
+		if err = h.k8s.CreateIfNotExistsService(headless); err != nil {
+			return err
+		}
+		if err = h.workload.CreateStatefulSetOrUpdateTemplate(sts); err != nil {
+			return err
+		}
+		if err = h.k8s.CreateOrUpdatePDB(resources.NewKVRocksPDB(h.instance, sts.Name)); err != nil {
 			return err
 		}
 	}
-	curStsList, err := h.k8s.ListStatefulSets(h.instance.Namespace, resources.SelectorLabels(h.instance))
+	curStsList, err := h.workload.ListStatefulSets(h.instance.Namespace, resources.SelectorLabels(h.instance))
 	if err != nil {
 		return err
 	}
-	if len(curStsList.Items) < int(h.instance.Spec.Master) {
+	if len(curStsList.Items) < int(h.instance.Spec.Master) && !maintenance {
 		h.requeue = true
 		return nil
 	}
@@ -55,20 +75,34 @@ func (h *KVRocksClusterHandler) ensureKubernetes() error {
 			Namespace: h.instance.Namespace,
 			Name:      sts.Name,
 		}
-		oldSts, err := h.k8s.GetStatefulSet(key)
+		oldSts, err := h.workload.GetStatefulSet(key)
 		if err != nil {
 			return err
 		}
+		if oldSts.DeletionTimestamp != nil {
+			h.log.Info("waiting for statefulSet recreated", "statefulSet", key.Name)
+			h.requeue = true
+			return nil
+		}
+		// serviceName is immutable, the statefulSet created before the headless service is recreated with orphan pods
+		if oldSts.Spec.ServiceName != sts.Spec.ServiceName {
+			h.log.Info("recreate statefulSet to update serviceName", "statefulSet", key.Name, "serviceName", sts.Spec.ServiceName)
+			h.requeue = true
+			return h.workload.DeleteStatefulSetOrphan(oldSts)
+		}
 		sts.ResourceVersion = oldSts.ResourceVersion
+		sts.Spec.VolumeClaimTemplates = oldSts.Spec.VolumeClaimTemplates
+		resources.KeepReadinessGates(&sts.Spec.Template, oldSts.Spec.Template.Spec.ReadinessGates)
+		// scaling is suppressed under maintenance
 		delta := *sts.Spec.Replicas - *oldSts.Spec.Replicas
-		if delta > 0 {
+		if delta > 0 && !maintenance {
 			reserve := oldSts.Spec.ReserveOrdinals
 			for delta > 0 && len(reserve) > 0 {
 				reserve = reserve[1:]
 				delta--
 			}
 			sts.Spec.ReserveOrdinals = reserve
-			if err = h.k8s.UpdateStatefulSet(sts); err != nil {
+			if err = h.workload.UpdateStatefulSet(sts); err != nil {
 				return err
 			}
 		}
@@ -79,20 +113,22 @@ func (h *KVRocksClusterHandler) ensureKubernetes() error {
 			Namespace: h.instance.Namespace,
 			Name:      resources.GetStatefulSetName(h.instance.Name, i),
 		}
-		sts, err := h.k8s.GetStatefulSet(key)
+		sts, err := h.workload.GetStatefulSet(key)
 		if err != nil {
 			return err
 		}
-		if sts.Status.ReadyReplicas != *sts.Spec.Replicas {
-			h.log.Info("waiting for statefulSet ready", "statefulSet", key.Name)
-			h.requeue = true
-			return nil
-		}
-		pods, err := h.k8s.ListStatefulSetPods(key)
+		pods, err := h.workload.ListStatefulSetPods(key)
 		if err != nil {
 			return err
 		}
-		for _, pod := range pods.Items {
+		// the slaves lagging behind the master are not ready, but still take part in the replication
+		if resources.CountReadyPods(pods) != *sts.Spec.Replicas {
+			h.log.Info("waiting for statefulSet ready", "statefulSet", key.Name)
+			h.requeue = true
+			return h.ensureLostPods(i, key)
+		}
+		for k := range pods.Items {
+			pod := &pods.Items[k]
 			if pod.DeletionTimestamp != nil {
 				h.log.Info("pod is deleting,please wait")
 				h.requeue = true
@@ -104,7 +140,7 @@ func (h *KVRocksClusterHandler) ensureKubernetes() error {
 			}
 			// init topo message
 			h.stsNodes[i] = append(h.stsNodes[i], &kvrocks.Node{
-				IP:       pod.Status.PodIP,
+				IP:       resources.GetPodHost(pod),
 				PodIndex: podIndex,
 			})
 		}
@@ -143,7 +179,70 @@ func (h *KVRocksClusterHandler) ensureKubernetes() error {
 	return nil
 }
 
+// ensureExpose creates a service for each pod if spec.expose is set, the nodes announce the external addresses of
+// their services. The services of removed pods are deleted and the nodes stop announcing them
+func (h *KVRocksClusterHandler) ensureExpose() error {
+	services, err := h.k8s.ListServices(h.instance.Namespace, resources.MergeLabels(resources.SelectorLabels(h.instance), resources.PodServiceLabels()))
+	if err != nil {
+		return err
+	}
+	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder)
+	nodes := map[string]*kvrocks.Node{}
+	for i, sts := range h.stsNodes {
+		for _, node := range sts {
+			if node == nil {
+				continue
+			}
+			nodes[fmt.Sprintf("%s-%d", resources.GetStatefulSetName(h.instance.Name, i), node.PodIndex)] = node
+		}
+	}
+	exposed := map[string]struct{}{}
+	if h.instance.Spec.Expose != nil {
+		for podName, node := range nodes {
+			exposed[resources.GetPodServiceName(podName)] = struct{}{}
+			if err = h.k8s.CreateOrUpdateService(resources.NewPodService(h.instance, podName)); err != nil {
+				return err
+			}
+			node.Announce, err = commHandler.GetExposedAddr(podName)
+			if err != nil {
+				return err
+			}
+			if node.Announce == "" {
+				h.log.Info("waiting for external address", "pod", podName)
+				continue
+			}
+			if err = commHandler.EnsureAnnounce(node, node.Announce); err != nil {
+				return err
+			}
+		}
+	}
+	for _, service := range services.Items {
+		if _, ok := exposed[service.Name]; ok {
+			continue
+		}
+		if node, ok := nodes[strings.TrimSuffix(service.Name, resources.GetPodServiceName(""))]; ok {
+			if err = commHandler.EnsureAnnounce(node, ""); err != nil {
+				return err
+			}
+		}
+		if err = h.k8s.DeleteService(types.NamespacedName{
+			Namespace: service.Namespace,
+			Name:      service.Name,
+		}); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
 func (h *KVRocksClusterHandler) ensureStatusTopoMsg() error {
+	// the replication is observed by ensureReadReplicas, keep it while the topology is rebuilt
+	replication := map[string]*kvrocksv1alpha1.KVRocksReplication{}
+	for _, partition := range h.instance.Status.Topo {
+		for _, topo := range partition.Topology {
+			replication[topo.Pod] = topo.Replication
+		}
+	}
 	h.instance.Status.Topo = nil
 	for i, sts := range h.stsNodes {
 		if sts == nil {
@@ -151,19 +250,22 @@ func (h *KVRocksClusterHandler) ensureStatusTopoMsg() error {
 		}
 		var topoes []kvrocksv1alpha1.KVRocksTopology
 		partitionName := resources.GetStatefulSetName(h.instance.Name, i)
-		for j, node := range sts {
+		for _, node := range sts {
 			if node == nil {
 				continue
 			}
+			// the ordinals may have gaps reserved by scaling down, so the pod is named by its index
 			topo := kvrocksv1alpha1.KVRocksTopology{
-				Pod:      fmt.Sprintf("%s-%d", partitionName, j),
+				Pod:      fmt.Sprintf("%s-%d", partitionName, node.PodIndex),
 				Role:     node.Role,
 				NodeId:   node.NodeId,
 				Ip:       node.IP,
 				Port:     kvrocks.KVRocksPort,
 				Slots:    kvrocks.SlotsToString(node.Slots),
 				MasterId: node.Master,
+				Announce: node.Announce,
 			}
+			topo.Replication = replication[topo.Pod]
 			if node.Migrate != nil {
 				for _, migrate := range node.Migrate {
 					topo.Migrate = append(topo.Migrate, kvrocksv1alpha1.MigrateMsg{
@@ -193,12 +295,12 @@ func (h *KVRocksClusterHandler) ensureStatusTopoMsg() error {
 // delete statefulSet
 
 func (h *KVRocksClusterHandler) ensureShrink() error {
-	if h.instance.Status.Rebalance {
+	if h.instance.Status.Rebalance || resources.IsUnderMaintenance(h.instance) {
 		return nil
 	}
 	var shrinkIndex []int
 	var err error
-	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password)
+	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder)
 	_, masterName := resources.ParseRedisName(h.instance.Name)
 	for i := int(h.instance.Spec.Master); i < len(h.stsNodes); i++ {
 		// first remove sentinel monitor
@@ -209,6 +311,12 @@ func (h *KVRocksClusterHandler) ensureShrink() error {
 		h.stsNodes[i] = nil
 		shrinkIndex = append(shrinkIndex, i)
 	}
+	// only the pods of the largest ordinals can be removed, move the masters to lower ordinals first
+	if !h.workload.SupportReserveOrdinals() {
+		if err = h.moveMasters(); err != nil || h.requeue {
+			return err
+		}
+	}
 	reserves := make(map[string][]int)
 
 	for i := 0; i < int(h.instance.Spec.Master); i++ {
@@ -226,15 +334,28 @@ func (h *KVRocksClusterHandler) ensureShrink() error {
 
 func (h *KVRocksClusterHandler) cleanStatefulSet() error {
 	for _, index := range h.instance.Status.Shrink.Partition {
-		if err := h.k8s.DeleteStatefulSetIfExists(types.NamespacedName{
+		stsName := resources.GetStatefulSetName(h.instance.Name, index)
+		if err := h.workload.DeleteStatefulSetIfExists(types.NamespacedName{
 			Namespace: h.instance.Namespace,
-			Name:      resources.GetStatefulSetName(h.instance.Name, index),
+			Name:      stsName,
+		}); err != nil {
+			return err
+		}
+		if err := h.k8s.DeleteService(types.NamespacedName{
+			Namespace: h.instance.Namespace,
+			Name:      resources.GetHeadlessServiceName(stsName),
+		}); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		if err := h.k8s.DeletePDBIfExists(types.NamespacedName{
+			Namespace: h.instance.Namespace,
+			Name:      stsName,
 		}); err != nil {
 			return err
 		}
 	}
 	for stsName, reserve := range h.instance.Status.Shrink.ReserveMsg {
-		sts, err := h.k8s.GetStatefulSet(types.NamespacedName{
+		sts, err := h.workload.GetStatefulSet(types.NamespacedName{
 			Namespace: h.instance.Namespace,
 			Name:      stsName,
 		})
@@ -245,7 +366,7 @@ func (h *KVRocksClusterHandler) cleanStatefulSet() error {
 		if len(reserve) != 0 {
 			sts.Spec.ReserveOrdinals = append(sts.Spec.ReserveOrdinals, reserve...)
 		}
-		if err := h.k8s.UpdateStatefulSet(sts); err != nil {
+		if err := h.workload.UpdateStatefulSet(sts); err != nil {
 			return err
 		}
 	}
@@ -254,9 +375,37 @@ func (h *KVRocksClusterHandler) cleanStatefulSet() error {
 	return h.k8s.UpdateKVRocks(h.instance)
 }
 
+// moveMasters fails over the shards whose master would be removed by scaling down, the controller may promote
+// another slave to be removed, so it is repeated until the master has a lower ordinal
+func (h *KVRocksClusterHandler) moveMasters() error {
+	replicas := int(h.instance.Spec.Replicas)
+	for i := 0; i < int(h.instance.Spec.Master); i++ {
+		if len(h.stsNodes[i]) <= replicas {
+			continue
+		}
+		for _, node := range h.stsNodes[i] {
+			if node.Role == kvrocks.RoleMaster && node.PodIndex >= replicas {
+				h.log.Info("move master to a lower ordinal before scaling down", "partition", i, "pod", node.PodIndex)
+				h.requeue = true
+				if err := h.switchover(i, node); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func (h *KVRocksClusterHandler) getReserveIndex(nodes []*kvrocks.Node) []int {
 	delta := len(nodes) - int(h.instance.Spec.Replicas)
 	var result []int
+	if delta > 0 && !h.workload.SupportReserveOrdinals() {
+		// the statefulSet removes the pods of the largest ordinals
+		for j := int(h.instance.Spec.Replicas); j < len(nodes); j++ {
+			nodes[j] = nil
+		}
+		return []int{}
+	}
 	if delta > 0 {
 		result = []int{}
 		var reserve []int
@@ -286,15 +435,46 @@ func (h *KVRocksClusterHandler) getReserveIndex(nodes []*kvrocks.Node) []int {
 	return result
 }
 
-func (h *KVRocksClusterHandler) cleanPersistentVolumeClaim() error {
+// ensureStorage expands the volumes of all shards when spec.storage.size grows or the disk usage reaches the autoscale threshold
+func (h *KVRocksClusterHandler) ensureStorage() error {
 	if h.instance.Status.Shrink != nil {
 		return nil
 	}
+	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder)
+	var nodes []*kvrocks.Node
+	for _, sts := range h.stsNodes {
+		nodes = append(nodes, sts...)
+	}
+	if err := commHandler.EnsureStorageAutoScale(nodes); err != nil {
+		return err
+	}
+	var statuses []kvrocksv1alpha1.KVRocksVolumeStatus
+	for index := range h.stsNodes {
+		key := types.NamespacedName{
+			Namespace: h.instance.Namespace,
+			Name:      resources.GetStatefulSetName(h.instance.Name, index),
+		}
+		stsStatuses, requeue, err := commHandler.EnsureStorage(key)
+		if err != nil {
+			return err
+		}
+		statuses = append(statuses, stsStatuses...)
+		h.requeue = h.requeue || requeue
+	}
+	return commHandler.UpdateStorageStatus(statuses)
+}
+
+func (h *KVRocksClusterHandler) cleanPersistentVolumeClaim() error {
+	if h.instance.Status.Shrink != nil || resources.IsUnderMaintenance(h.instance) {
+		return nil
+	}
 	pvcList, err := h.k8s.ListPVC(h.instance.Namespace, resources.SelectorLabels(h.instance))
 	if err != nil {
 		return err
 	}
-	for _, pvc := range pvcList.Items {
+	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder)
+	for i := range pvcList.Items {
+		pvc := &pvcList.Items[i]
 		remove := false
 		fields := strings.Split(pvc.Name, "-")
 		stsIdx, _ := strconv.Atoi(fields[len(fields)-2])
@@ -310,9 +490,12 @@ func (h *KVRocksClusterHandler) cleanPersistentVolumeClaim() error {
 			}
 		}
 		if remove {
-			if err = h.k8s.DeletePVC(&pvc); err != nil {
-				return err
-			}
+			err = commHandler.CleanPVC(pvc)
+		} else {
+			err = commHandler.ReusePVC(pvc)
+		}
+		if err != nil {
+			return err
 		}
 	}
 	return nil
@@ -0,0 +1,38 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+)
+
+func TestEnsureStatusTopoMsg(t *testing.T) {
+	assert := assert.New(t)
+
+	instance := newTestInstance()
+	replication := &kvrocksv1alpha1.KVRocksReplication{LinkStatus: "up", Offset: 1000}
+	instance.Status.Topo = []kvrocksv1alpha1.KVRocksTopoPartitions{{
+		PartitionName: "test-0",
+		Topology: []kvrocksv1alpha1.KVRocksTopology{
+			{Pod: "test-0-0", Role: kvrocks.RoleMaster},
+			{Pod: "test-0-2", Role: kvrocks.RoleSlaver, Replication: replication},
+		},
+	}}
+	h, _, _ := newTestHandler(t, instance, &fakeKVRocks{})
+	// the ordinal 1 is reserved by scaling down
+	h.stsNodes = [][]*kvrocks.Node{{
+		{IP: "10.0.0.1", PodIndex: 0, Role: kvrocks.RoleMaster, NodeId: "node-0"},
+		{IP: "10.0.0.3", PodIndex: 2, Role: kvrocks.RoleSlaver, NodeId: "node-2", Master: "node-0"},
+	}}
+
+	assert.NoError(h.ensureStatusTopoMsg())
+	if assert.Len(instance.Status.Topo, 1) && assert.Len(instance.Status.Topo[0].Topology, 2) {
+		topology := instance.Status.Topo[0].Topology
+		assert.Equal("test-0-0", topology[0].Pod)
+		assert.Equal("test-0-2", topology[1].Pod)
+		assert.Equal(replication, topology[1].Replication)
+	}
+}
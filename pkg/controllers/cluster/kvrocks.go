@@ -3,15 +3,18 @@ package cluster
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 
 	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
 	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
 	"github.com/RocksLabs/kvrocks-operator/pkg/controllers/common"
 	"github.com/RocksLabs/kvrocks-operator/pkg/controllers/events"
+	sentinel "github.com/RocksLabs/kvrocks-operator/pkg/controllers/sentinel"
 	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
 )
 
@@ -42,15 +45,24 @@ func (h *KVRocksClusterHandler) ensureKVRocksStatus() error {
 }
 
 func (h *KVRocksClusterHandler) ensureKVRocksConfig() error {
-	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password)
-	for _, sts := range h.stsNodes {
-		if err := commHandler.EnsureConfig(sts); err != nil {
-			return err
+	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder)
+	var statuses []kvrocksv1alpha1.KVRocksNodeConfig
+	var err error
+	for index, sts := range h.stsNodes {
+		stsStatuses, stsErr := commHandler.EnsureConfig(resources.GetStatefulSetName(h.instance.Name, index), sts)
+		statuses = append(statuses, stsStatuses...)
+		if stsErr != nil && err == nil {
+			err = stsErr
 		}
 	}
+	if updateErr := commHandler.UpdateConfigStatus(statuses); updateErr != nil {
+		return updateErr
+	}
+	if err != nil {
+		return err
+	}
 	h.password = h.instance.Spec.Password
-	configMap := resources.NewKVRocksConfigMap(h.instance)
-	if err := h.k8s.UpdateConfigMap(configMap); err != nil {
+	if err := commHandler.UpdateKVRocksConfigMap(); err != nil {
 		return err
 	}
 	h.log.Info("kvrocks config ready")
@@ -123,7 +135,7 @@ func (h *KVRocksClusterHandler) ensureVersion() {
 			goto version
 		}
 		for j, topo := range partition.Topology {
-			if topo.Ip != h.stsNodes[i][j].IP { // ip change
+			if topo.Ip != h.stsNodes[i][j].IP || topo.Announce != h.stsNodes[i][j].Announce { // ip change
 				goto version
 			}
 			if topo.Role != h.stsNodes[i][j].Role { // role change
@@ -138,6 +150,9 @@ version:
 
 // if operator exists, and node down,send failover message
 func (h *KVRocksClusterHandler) ensureFailover() error {
+	if resources.IsUnderMaintenance(h.instance) {
+		return nil
+	}
 	change := false
 	for partition, sts := range h.stsNodes {
 		for index, node := range sts {
@@ -149,12 +164,13 @@ func (h *KVRocksClusterHandler) ensureFailover() error {
 					continue
 				}
 				podName := fmt.Sprintf("%s-%d-%d", h.instance.Name, partition, index)
-				if err := h.k8s.DeletePVCByPod(podName, h.instance.Namespace); err != nil {
+				if err := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder).DeleteFailoverPVC(podName); err != nil {
 					return err
 				}
 				if err := h.k8s.DeletePodImmediately(podName, h.instance.Namespace); err != nil {
 					return err
 				}
+				h.podEventf(partition, node.PodIndex, corev1.EventTypeWarning, common.ReasonPodReplaced, "node is still unreachable after failover, the pod is replaced")
 				node.Failover = false
 				change = true
 				continue
@@ -172,6 +188,110 @@ func (h *KVRocksClusterHandler) ensureFailover() error {
 	return nil
 }
 
+// ensureReadReplicas excludes the slaves lagging behind their shard masters from the read service and publishes the endpoints
+func (h *KVRocksClusterHandler) ensureReadReplicas() error {
+	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder)
+	changed := false
+	for index, sts := range h.stsNodes {
+		stsName := resources.GetStatefulSetName(h.instance.Name, index)
+		replication := commHandler.GetReplication(stsName, sts)
+		if err := commHandler.EnsureReplicationGate(stsName, sts, replication); err != nil {
+			return err
+		}
+		if index >= len(h.instance.Status.Topo) {
+			continue
+		}
+		topology := h.instance.Status.Topo[index].Topology
+		for i := range topology {
+			for _, node := range sts {
+				if node == nil || topology[i].Pod != fmt.Sprintf("%s-%d", stsName, node.PodIndex) {
+					continue
+				}
+				if !reflect.DeepEqual(topology[i].Replication, replication[node.PodIndex]) {
+					topology[i].Replication = replication[node.PodIndex]
+					changed = true
+				}
+			}
+		}
+	}
+	if err := commHandler.UpdateReplicationHealth(changed); err != nil {
+		return err
+	}
+	return commHandler.UpdateEndpoints()
+}
+
+// ensureConnectionSecret publishes the connection info with all nodes as the cluster seed nodes
+func (h *KVRocksClusterHandler) ensureConnectionSecret() error {
+	var nodes []string
+	for _, sts := range h.stsNodes {
+		for _, node := range sts {
+			if node != nil {
+				nodes = append(nodes, nodeAddr(node))
+			}
+		}
+	}
+	return common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder).EnsureConnectionSecret(nodes)
+}
+
+func (h *KVRocksClusterHandler) ensureMonitoring() error {
+	return common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder).EnsureMonitoring()
+}
+
+// ensureRestart restarts the pods which were started with outdated restart-required configs, one shard at a time
+func (h *KVRocksClusterHandler) ensureRestart() error {
+	if h.instance.Status.Shrink != nil {
+		return nil
+	}
+	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder)
+	var pending []string
+	for index, sts := range h.stsNodes {
+		partition := index
+		key := types.NamespacedName{
+			Namespace: h.instance.Namespace,
+			Name:      resources.GetStatefulSetName(h.instance.Name, index),
+		}
+		pods, err := commHandler.EnsureRestart(key, sts, func(master *kvrocks.Node) error {
+			// the controller promotes any slave of the shard, so the restart waits until one is in sync
+			if err := h.checkShardInSync(commHandler, partition, master, nil); err != nil {
+				var opErr *common.OperationError
+				if errors.As(err, &opErr) {
+					h.log.Info("wait for a slave in sync before restart", "partition", partition, "reason", err.Error())
+					return nil
+				}
+				return err
+			}
+			return h.switchover(partition, master)
+		})
+		if err != nil {
+			return err
+		}
+		if len(pods) != 0 {
+			pending = pods
+			h.requeue = true
+			break
+		}
+	}
+	return commHandler.UpdateRestartCondition(pending)
+}
+
+// switchover lets the controller promote a slave of the shard
+func (h *KVRocksClusterHandler) switchover(partition int, master *kvrocks.Node) error {
+	if err := h.controllerClient.FailoverShard(partition); err != nil {
+		return err
+	}
+	h.log.Info("switch over successfully", "partition", partition, "from", master.IP)
+	common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder).Eventf(corev1.EventTypeNormal, common.ReasonFailover, "shard %d is switched over from %s", partition, master.IP)
+	return h.notifySentinel()
+}
+
+// notifySentinel lets the sentinel update the monitored masters after switchover
+func (h *KVRocksClusterHandler) notifySentinel() error {
+	if v, ok := h.instance.Labels[resources.MonitoredBy]; ok {
+		return sentinel.UpdateSentinelAnnotationCount(h.k8s, h.instance.Namespace, v)
+	}
+	return nil
+}
+
 func (h *KVRocksClusterHandler) updatePodLabels(key types.NamespacedName, role string) error {
 	pod, err := h.k8s.GetPod(key)
 	if err != nil {
@@ -183,6 +303,9 @@ func (h *KVRocksClusterHandler) updatePodLabels(key types.NamespacedName, role s
 			return err
 		}
 	}
+	if role == kvrocks.RoleMaster {
+		return common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder).EnsureMasterGate(key.Name)
+	}
 	return nil
 }
 
@@ -190,7 +313,7 @@ func (h *KVRocksClusterHandler) ensureCluster() (bool, error) {
 	nodes := make([]string, 0)
 	for _, sts := range h.stsNodes {
 		for _, node := range sts {
-			nodes = append(nodes, node.IP+":"+strconv.Itoa(kvrocks.KVRocksPort))
+			nodes = append(nodes, nodeAddr(node))
 		}
 	}
 	if h.instance.Status.Status == kvrocksv1alpha1.StatusCreating {
@@ -218,6 +341,10 @@ func (h *KVRocksClusterHandler) ensureCluster() (bool, error) {
 }
 
 func (h *KVRocksClusterHandler) ensureSetNodeID() error {
+	resyncing, err := h.getResyncingPod()
+	if err != nil {
+		return err
+	}
 	for index, sts := range h.stsNodes {
 		shardData, err := h.controllerClient.GetNodes(index)
 		if err != nil {
@@ -235,8 +362,10 @@ func (h *KVRocksClusterHandler) ensureSetNodeID() error {
 			return fmt.Errorf("no master node in shard %d", index)
 		}
 		for _, node := range sts {
+			// a node removed from the controller, such as being resynced, has no id
+			node.NodeId = ""
 			for _, shard := range shardData.Nodes {
-				if simplyIp(shard.Addr) == node.IP {
+				if shard.Addr == nodeAddr(node) {
 					node.NodeId = shard.ID
 					if shard.Role == "master" {
 						node.Role = kvrocks.RoleMaster
@@ -254,6 +383,10 @@ func (h *KVRocksClusterHandler) ensureSetNodeID() error {
 				Namespace: h.instance.GetNamespace(),
 				Name:      fmt.Sprintf("%s-%d", resources.GetStatefulSetName(h.instance.GetName(), index), node.PodIndex),
 			}
+			// the resynced pod has no role while it is out of the controller, it keeps its last role label
+			if key.Name == resyncing {
+				continue
+			}
 			if err := h.updatePodLabels(key, node.Role); err != nil {
 				return err
 			}
@@ -263,6 +396,7 @@ func (h *KVRocksClusterHandler) ensureSetNodeID() error {
 }
 
 func (h *KVRocksClusterHandler) updateCluster() error {
+	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder)
 	//remove node
 	for index, sts := range h.stsNodes {
 		shardData, err := h.controllerClient.GetNodes(index)
@@ -273,18 +407,19 @@ func (h *KVRocksClusterHandler) updateCluster() error {
 		if shardData == nil {
 			nodes := make([]string, 0)
 			for _, node := range sts {
-				nodes = append(nodes, node.IP+":"+strconv.Itoa(kvrocks.KVRocksPort))
+				nodes = append(nodes, nodeAddr(node))
 			}
 			err := h.controllerClient.CreateShard(nodes, h.password)
 			if err != nil {
 				return err
 			}
+			commHandler.Eventf(corev1.EventTypeNormal, common.ReasonShardCreated, "shard %d is created with nodes %s", index, strings.Join(nodes, ","))
 			continue
 		}
 		for _, shard := range shardData.Nodes {
 			needDeleted := true
 			for _, node := range sts {
-				if simplyIp(shard.Addr) == node.IP {
+				if shard.Addr == nodeAddr(node) {
 					needDeleted = false
 					break
 				}
@@ -293,11 +428,16 @@ func (h *KVRocksClusterHandler) updateCluster() error {
 				if err := h.controllerClient.DeleteNode(index, shard.ID); err != nil {
 					return err
 				}
+				commHandler.Eventf(corev1.EventTypeNormal, common.ReasonNodeRemoved, "node %s is removed from shard %d", shard.Addr, index)
 			}
 		}
 	}
 
-	// add node
+	// add node, except the one being resynced
+	resyncing, err := h.getResyncingPod()
+	if err != nil {
+		return err
+	}
 	for index, sts := range h.stsNodes {
 		shardData, err := h.controllerClient.GetNodes(index)
 		if err != nil {
@@ -306,16 +446,17 @@ func (h *KVRocksClusterHandler) updateCluster() error {
 		for _, node := range sts {
 			needAdded := true
 			for _, shard := range shardData.Nodes {
-				if simplyIp(shard.Addr) == node.IP {
+				if shard.Addr == nodeAddr(node) {
 					needAdded = false
 					break
 				}
 			}
-			if needAdded {
-				err = h.controllerClient.AddNode(index, node.IP+":"+strconv.Itoa(kvrocks.KVRocksPort), node.Role, h.password)
+			if needAdded && h.podName(index, node) != resyncing {
+				err = h.controllerClient.AddNode(index, nodeAddr(node), node.Role, h.password)
 				if err != nil {
 					return err
 				}
+				commHandler.Eventf(corev1.EventTypeNormal, common.ReasonNodeAdded, "node %s is added to shard %d as %s", nodeAddr(node), index, node.Role)
 			}
 		}
 	}
@@ -330,10 +471,19 @@ func (h *KVRocksClusterHandler) updateCluster() error {
 		if err := h.controllerClient.DeleteShard(i); err != nil {
 			return err
 		}
+		commHandler.Eventf(corev1.EventTypeNormal, common.ReasonShardDeleted, "shard %d is deleted", i)
 	}
 	return nil
 }
 
-func simplyIp(ip string) string {
-	return strings.Split(ip, ":")[0]
+// nodeAddr returns the address of the node registered in the controller, it is always the internal address so the
+// replication and migration between the nodes stay inside the kubernetes cluster
+func nodeAddr(node *kvrocks.Node) string {
+	return node.IP + ":" + strconv.Itoa(kvrocks.KVRocksPort)
+}
+
+// podEventf records an event against the kvrocks and the pod of the node in the shard
+func (h *KVRocksClusterHandler) podEventf(partition, podIndex int, eventtype, reason, messageFmt string, args ...interface{}) {
+	podName := fmt.Sprintf("%s-%d", resources.GetStatefulSetName(h.instance.Name, partition), podIndex)
+	common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder).PodEventf(podName, eventtype, reason, messageFmt, args...)
 }
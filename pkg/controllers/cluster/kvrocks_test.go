@@ -0,0 +1,129 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/controller"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/controllers/common"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+func TestEnsureRestart(t *testing.T) {
+	tests := []struct {
+		name        string
+		slaveOffset int
+		expRequests []string
+	}{
+		{
+			name:        "The master should be switched over before restart if a slave is in sync.",
+			slaveOffset: 1 << 30,
+			expRequests: []string{"FailoverShard"},
+		}, {
+			name:        "The master should not be switched over before restart while the slaves lag.",
+			slaveOffset: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			instance := newTestInstance()
+			instance.Spec.KVRocksConfig = map[string]string{"rocksdb.block_size": "16384"}
+			hash := resources.RestartConfigHash(resources.GetKVRocksConfig(instance))
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      instance.Name,
+					Namespace: instance.Namespace,
+					Annotations: map[string]string{
+						resources.ConfigHash:     hash,
+						resources.ConfigHashTime: time.Now().UTC().Format(time.RFC3339),
+					},
+				},
+			}
+			name := resources.GetStatefulSetName(instance.Name, 0)
+			labels := map[string]string{"kvrocks/statefulset": name}
+			sts := &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: instance.Namespace, Labels: labels},
+				Spec:       appsv1.StatefulSetSpec{Selector: &metav1.LabelSelector{MatchLabels: labels}},
+			}
+			// the master is started with the outdated configs, the slave with the current ones
+			pod := func(index int, podHash string) *corev1.Pod {
+				return &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        fmt.Sprintf("%s-%d", name, index),
+						Namespace:   instance.Namespace,
+						Labels:      labels,
+						Annotations: map[string]string{resources.ConfigHash: podHash},
+					},
+				}
+			}
+			kvClient := &fakeKVRocks{offsets: map[string]int{"10.0.0.1": 1 << 30, "10.0.0.2": test.slaveOffset}}
+			fakeCtrl := &fakeController{}
+			withController(t, fakeCtrl)
+			h, _, _ := newTestHandler(t, instance, kvClient, sts, cm, pod(0, "outdated"), pod(1, hash))
+
+			assert.NoError(h.ensureRestart())
+			assert.True(h.requeue)
+			assert.Equal(test.expRequests, fakeCtrl.requests)
+			assert.Equal(kvrocks.RoleMaster, h.stsNodes[0][0].Role)
+		})
+	}
+}
+
+func TestEnsureSetNodeID(t *testing.T) {
+	assert := assert.New(t)
+
+	instance := newTestInstance()
+	// the slave is removed from the controller to be resynced
+	fakeCtrl := &fakeController{nodes: []controller.Node{{ID: "node-0", Addr: "10.0.0.1:6379", Role: "master"}}}
+	withController(t, fakeCtrl)
+	stepTime := metav1.Now()
+	op := &kvrocksv1alpha1.KVRocksOperation{
+		ObjectMeta: metav1.ObjectMeta{Name: "resync", Namespace: instance.Namespace},
+		Spec: kvrocksv1alpha1.KVRocksOperationSpec{
+			Instance: instance.Name,
+			Type:     kvrocksv1alpha1.ResyncOperation,
+			Pod:      "test-0-1",
+		},
+		Status: kvrocksv1alpha1.KVRocksOperationStatus{
+			Phase:     kvrocksv1alpha1.OperationRunning,
+			Step:      common.OperationStepWipe,
+			StartTime: &stepTime,
+			StepTime:  &stepTime,
+		},
+	}
+	pod := func(index int, role string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("test-0-%d", index),
+				Namespace: instance.Namespace,
+				Labels:    map[string]string{resources.KvrocksRole: role},
+			},
+		}
+	}
+	h, fakeClient, _ := newTestHandler(t, instance, &fakeKVRocks{}, op, pod(0, kvrocks.RoleSlaver), pod(1, kvrocks.RoleSlaver))
+	// the nodes are listed from the pods without their roles
+	for _, node := range h.stsNodes[0] {
+		node.Role, node.Master = "", ""
+	}
+
+	assert.NoError(h.ensureSetNodeID())
+	assert.Equal("", h.stsNodes[0][1].NodeId)
+	for name, expRole := range map[string]string{"test-0-0": kvrocks.RoleMaster, "test-0-1": kvrocks.RoleSlaver} {
+		var got corev1.Pod
+		assert.NoError(fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: instance.Namespace, Name: name}, &got))
+		assert.Equal(expRole, got.Labels[resources.KvrocksRole], name)
+	}
+}
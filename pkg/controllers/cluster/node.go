@@ -0,0 +1,96 @@
+package cluster
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/controllers/common"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+// ensureNodeDrain switches over the masters on the draining nodes to the slaves on other nodes with the largest
+// replication offset, one shard at a time
+func (h *KVRocksClusterHandler) ensureNodeDrain() error {
+	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder)
+	for partition, sts := range h.stsNodes {
+		stsName := resources.GetStatefulSetName(h.instance.Name, partition)
+		draining, _, err := commHandler.ListDisruptedPods(types.NamespacedName{
+			Namespace: h.instance.Namespace,
+			Name:      stsName,
+		})
+		if err != nil {
+			return err
+		}
+		drained := map[string]bool{}
+		for _, pod := range draining {
+			drained[pod.Name] = true
+		}
+		var master, target *kvrocks.Node
+		maxOffset := -1
+		for _, node := range sts {
+			podName := fmt.Sprintf("%s-%d", stsName, node.PodIndex)
+			if node.Role == kvrocks.RoleMaster {
+				if drained[podName] {
+					master = node
+				}
+				continue
+			}
+			if drained[podName] {
+				continue
+			}
+			// the slave with the smallest lag loses the least writes when it is promoted
+			offset, err := h.kvrocks.GetOffset(node.IP, h.password)
+			if err != nil {
+				continue
+			}
+			if offset > maxOffset {
+				target, maxOffset = node, offset
+			}
+		}
+		if master == nil || target == nil {
+			continue
+		}
+		if err = h.controllerClient.FailoverShardTo(partition, target.NodeId); err != nil {
+			return err
+		}
+		h.podEventf(partition, target.PodIndex, corev1.EventTypeNormal, common.ReasonPromotedMaster, "promoted to master, the node of %s is draining", master.IP)
+		h.log.Info("node of master is draining, switch over", "partition", partition, "from", master.IP, "to", target.IP)
+		h.requeue = true
+		return h.notifySentinel()
+	}
+	return nil
+}
+
+// ensureLostPods replaces the pods of the shard on the lost nodes, which would never be ready again. A lost master
+// is failed over by the controller first, and replaced as a slave
+func (h *KVRocksClusterHandler) ensureLostPods(partition int, key types.NamespacedName) error {
+	if resources.IsUnderMaintenance(h.instance) {
+		return nil
+	}
+	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder)
+	_, lost, err := commHandler.ListDisruptedPods(key)
+	if err != nil {
+		return err
+	}
+	for _, pod := range lost {
+		if pod.Labels[resources.KvrocksRole] != kvrocks.RoleMaster {
+			continue
+		}
+		if h.instance.Spec.Replicas > 1 {
+			h.log.Info("node of master is lost, fail over", "pod", pod.Name, "node", pod.Spec.NodeName)
+			if err = h.switchover(partition, &kvrocks.Node{IP: resources.GetPodHost(pod)}); err != nil {
+				return err
+			}
+		}
+		return h.updatePodLabels(types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}, kvrocks.RoleSlaver)
+	}
+	for _, pod := range lost {
+		if err = commHandler.ReplacePod(pod); err != nil {
+			return err
+		}
+	}
+	return nil
+}
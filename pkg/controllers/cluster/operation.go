@@ -0,0 +1,274 @@
+package cluster
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/controllers/common"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+// ensureOperation runs a step of the oldest unfinished KVRocksOperation, the kvrocks is requeued until it finishes.
+// The operations wait while the cluster is shrinking or rebalancing
+func (h *KVRocksClusterHandler) ensureOperation() error {
+	if h.instance.Status.Shrink != nil || h.instance.Status.Rebalance {
+		return nil
+	}
+	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder)
+	op, err := commHandler.NextOperation()
+	if err != nil || op == nil {
+		return err
+	}
+	done, err := h.runOperation(op)
+	running, err := commHandler.UpdateOperation(op, done, err)
+	if running {
+		h.requeue = true
+	}
+	return err
+}
+
+// runOperation starts the operation or checks if its current step is completed, it returns true if the operation
+// is completed
+func (h *KVRocksClusterHandler) runOperation(op *kvrocksv1alpha1.KVRocksOperation) (bool, error) {
+	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder)
+	partition, target, err := h.getOperationTarget(op)
+	if err != nil {
+		return false, err
+	}
+	master := h.getShardMaster(partition)
+	if master == nil {
+		return false, nil
+	}
+	switch op.Status.Step {
+	case "":
+		op.Status.PreviousMaster = h.podName(partition, master)
+		switch op.Spec.Type {
+		case kvrocksv1alpha1.SwitchoverOperation, kvrocksv1alpha1.FailoverOperation:
+			return h.promote(op, partition, master, target)
+		case kvrocksv1alpha1.RestartPodOperation, kvrocksv1alpha1.ReplaceNodeOperation:
+			if target.Role == kvrocks.RoleMaster {
+				return h.promote(op, partition, master, nil)
+			}
+			return false, h.deleteOperationPod(op, partition, target)
+		case kvrocksv1alpha1.ResyncOperation:
+			if target.Role == kvrocks.RoleMaster {
+				return false, common.NewOperationError("%s is the master, switch over before resync", op.Spec.Pod)
+			}
+			// the wiped slave must not be elected by the controller, updateCluster does not add it back until the
+			// AddNode step, which is reached only after the pod is wiped and recreated
+			return false, commHandler.SetOperationStep(op, common.OperationStepRemoveNode, "removing %s from shard %d", op.Spec.Pod, partition)
+		}
+		return false, common.NewOperationError("unknown operation type %s", op.Spec.Type)
+	case common.OperationStepWaitPromotion:
+		if h.podName(partition, master) == op.Status.PreviousMaster {
+			return false, nil
+		}
+		// the preferred node is a hint which the controller may ignore, the promoted node is checked against the target
+		if target != nil && (op.Spec.Type == kvrocksv1alpha1.SwitchoverOperation || op.Spec.Type == kvrocksv1alpha1.FailoverOperation) {
+			if master != target {
+				return false, common.NewOperationError("%s is promoted to master of shard %d instead of %s", h.podName(partition, master), partition, op.Spec.Pod)
+			}
+			h.podEventf(partition, target.PodIndex, corev1.EventTypeNormal, common.ReasonPromotedMaster, "promoted to master by %s operation %s", op.Spec.Type, op.Name)
+		}
+		if op.Spec.Type == kvrocksv1alpha1.RestartPodOperation || op.Spec.Type == kvrocksv1alpha1.ReplaceNodeOperation {
+			return false, h.deleteOperationPod(op, partition, target)
+		}
+		op.Status.Message = fmt.Sprintf("%s is promoted to master of shard %d", h.podName(partition, master), partition)
+		return true, nil
+	case common.OperationStepRemoveNode:
+		nodeID, err := h.getControllerNodeID(partition, target)
+		if err != nil {
+			return false, err
+		}
+		if nodeID != "" {
+			if err = h.controllerClient.DeleteNode(partition, nodeID); err != nil {
+				return false, err
+			}
+			commHandler.Eventf(corev1.EventTypeNormal, common.ReasonNodeRemoved, "node %s is removed from shard %d to resync", nodeAddr(target), partition)
+		}
+		return false, commHandler.SetOperationStep(op, common.OperationStepWipe, "wiping %s", op.Spec.Pod)
+	case common.OperationStepWipe:
+		return false, h.deleteOperationPod(op, partition, target)
+	case common.OperationStepAddNode:
+		recreated, err := commHandler.IsPodRecreated(op, op.Spec.Pod)
+		if err != nil || !recreated {
+			return false, err
+		}
+		nodeID, err := h.getControllerNodeID(partition, target)
+		if err != nil {
+			return false, err
+		}
+		// the node is added back before it catches up, kvrocks refuses SLAVEOF with cluster-enabled, so it only
+		// full syncs from the master once the controller assigns it to the shard
+		if nodeID == "" {
+			if err = h.controllerClient.AddNode(partition, nodeAddr(target), kvrocks.RoleSlaver, h.password); err != nil {
+				return false, err
+			}
+			commHandler.Eventf(corev1.EventTypeNormal, common.ReasonNodeAdded, "node %s is added to shard %d as %s to resync", nodeAddr(target), partition, kvrocks.RoleSlaver)
+		}
+		return false, commHandler.SetOperationStep(op, common.OperationStepWaitSync, "waiting for %s to full sync", op.Spec.Pod)
+	case common.OperationStepWaitReady:
+		recreated, err := commHandler.IsPodRecreated(op, op.Spec.Pod)
+		if recreated {
+			op.Status.Message = fmt.Sprintf("%s is recreated", op.Spec.Pod)
+		}
+		return recreated, err
+	case common.OperationStepWaitSync:
+		// the pod is recreated before it is added back, the node id is set once the controller knows the node
+		if target.NodeId == "" {
+			return false, nil
+		}
+		synced, err := commHandler.IsReplicaSynced(master, target)
+		if synced {
+			op.Status.Message = fmt.Sprintf("%s is resynced from %s", op.Spec.Pod, h.podName(partition, master))
+		}
+		return synced, err
+	}
+	return false, common.NewOperationError("unknown step %s", op.Status.Step)
+}
+
+// promote lets the controller fail over the shard to the target, or to the slave chosen by the controller. The target
+// is only preferred, the WaitPromotion step fails the operation if the controller promoted another slave
+func (h *KVRocksClusterHandler) promote(op *kvrocksv1alpha1.KVRocksOperation, partition int, master, target *kvrocks.Node) (bool, error) {
+	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder)
+	if target == master {
+		op.Status.Message = fmt.Sprintf("%s is already the master of shard %d", h.podName(partition, master), partition)
+		return true, nil
+	}
+	if !op.Spec.Force {
+		if err := h.checkShardInSync(commHandler, partition, master, target); err != nil {
+			return false, err
+		}
+	}
+	if target != nil {
+		if err := h.controllerClient.FailoverShardTo(partition, target.NodeId); err != nil {
+			return false, err
+		}
+	} else {
+		if err := h.controllerClient.FailoverShard(partition); err != nil {
+			return false, err
+		}
+	}
+	commHandler.Eventf(corev1.EventTypeNormal, common.ReasonFailover, "shard %d is failed over from %s by %s operation %s", partition, master.IP, op.Spec.Type, op.Name)
+	if err := h.notifySentinel(); err != nil {
+		return false, err
+	}
+	return false, commHandler.SetOperationStep(op, common.OperationStepWaitPromotion, "shard %d is failing over from %s", partition, h.podName(partition, master))
+}
+
+// checkShardInSync checks the target, or any slave of the shard if it is not set, is in sync with the master
+func (h *KVRocksClusterHandler) checkShardInSync(commHandler *common.CommandHandler, partition int, master, target *kvrocks.Node) error {
+	if target != nil {
+		return commHandler.CheckReplicaInSync(master, target)
+	}
+	err := common.NewOperationError("no slave of shard %d to promote", partition)
+	for _, node := range h.stsNodes[partition] {
+		if node == nil || node.Role == kvrocks.RoleMaster {
+			continue
+		}
+		if err = commHandler.CheckReplicaInSync(master, node); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// deleteOperationPod deletes the target pod, with its pvc if the node is replaced or resynced
+func (h *KVRocksClusterHandler) deleteOperationPod(op *kvrocksv1alpha1.KVRocksOperation, partition int, target *kvrocks.Node) error {
+	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder)
+	podName := h.podName(partition, target)
+	wipe := op.Spec.Type != kvrocksv1alpha1.RestartPodOperation
+	if err := commHandler.DeleteOperationPod(op, podName, wipe); err != nil {
+		return err
+	}
+	if op.Spec.Type == kvrocksv1alpha1.ResyncOperation {
+		return commHandler.SetOperationStep(op, common.OperationStepAddNode, "waiting for %s to be recreated", podName)
+	}
+	return commHandler.SetOperationStep(op, common.OperationStepWaitReady, "waiting for %s to be recreated", podName)
+}
+
+// getOperationTarget returns the shard and the node of spec.pod, the shard is spec.shard if the pod is not set.
+// The pod is required except for Switchover and Failover
+func (h *KVRocksClusterHandler) getOperationTarget(op *kvrocksv1alpha1.KVRocksOperation) (int, *kvrocks.Node, error) {
+	if op.Spec.Pod == "" {
+		if op.Spec.Type != kvrocksv1alpha1.SwitchoverOperation && op.Spec.Type != kvrocksv1alpha1.FailoverOperation {
+			return 0, nil, common.NewOperationError("spec.pod is required by %s", op.Spec.Type)
+		}
+		if op.Spec.Shard == nil {
+			return 0, nil, common.NewOperationError("spec.shard or spec.pod is required by %s", op.Spec.Type)
+		}
+		if *op.Spec.Shard < 0 || *op.Spec.Shard >= len(h.stsNodes) {
+			return 0, nil, common.NewOperationError("shard %d is not found", *op.Spec.Shard)
+		}
+		return *op.Spec.Shard, nil, nil
+	}
+	for partition, sts := range h.stsNodes {
+		for _, node := range sts {
+			if node == nil || h.podName(partition, node) != op.Spec.Pod {
+				continue
+			}
+			if op.Spec.Shard != nil && *op.Spec.Shard != partition {
+				return 0, nil, common.NewOperationError("pod %s is not in shard %d", op.Spec.Pod, *op.Spec.Shard)
+			}
+			return partition, node, nil
+		}
+	}
+	return 0, nil, common.NewOperationError("pod %s is not a node of %s", op.Spec.Pod, h.instance.Name)
+}
+
+// getControllerNodeID returns the id of the node in the controller shard, it is empty if the node is not in the shard
+func (h *KVRocksClusterHandler) getControllerNodeID(partition int, node *kvrocks.Node) (string, error) {
+	shardData, err := h.controllerClient.GetNodes(partition)
+	if err != nil || shardData == nil {
+		return "", err
+	}
+	for _, shard := range shardData.Nodes {
+		if shard.Addr == nodeAddr(node) {
+			return shard.ID, nil
+		}
+	}
+	return "", nil
+}
+
+// getResyncingPod returns the target pod of the running Resync operation between removing the node from the
+// controller and adding it back, updateCluster must not add it back meanwhile
+func (h *KVRocksClusterHandler) getResyncingPod() (string, error) {
+	operations, err := h.k8s.ListKVRocksOperations(h.instance.Namespace, h.instance.Name)
+	if err != nil {
+		return "", err
+	}
+	for _, op := range operations {
+		if op.Spec.Type != kvrocksv1alpha1.ResyncOperation || op.Status.Phase != kvrocksv1alpha1.OperationRunning {
+			continue
+		}
+		switch op.Status.Step {
+		case common.OperationStepRemoveNode, common.OperationStepWipe, common.OperationStepAddNode:
+			return op.Spec.Pod, nil
+		}
+	}
+	return "", nil
+}
+
+// ensureReseed resyncs the slaves out of sync for too long by the reseed policy
+func (h *KVRocksClusterHandler) ensureReseed() error {
+	if h.instance.Status.Shrink != nil || h.instance.Status.Rebalance {
+		return nil
+	}
+	return common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder).EnsureReseed()
+}
+
+func (h *KVRocksClusterHandler) getShardMaster(partition int) *kvrocks.Node {
+	for _, node := range h.stsNodes[partition] {
+		if node != nil && node.Role == kvrocks.RoleMaster {
+			return node
+		}
+	}
+	return nil
+}
+
+func (h *KVRocksClusterHandler) podName(partition int, node *kvrocks.Node) string {
+	return fmt.Sprintf("%s-%d", resources.GetStatefulSetName(h.instance.Name, partition), node.PodIndex)
+}
@@ -0,0 +1,412 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sApiClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/controller"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/k8s"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/controllers/common"
+)
+
+// fakeKVRocks answers the replication of the nodes from its maps, the other commands panic
+type fakeKVRocks struct {
+	kvrocks.Client
+	offsets     map[string]int
+	replication map[string]*kvrocks.ReplicationInfo
+}
+
+func (c *fakeKVRocks) Logger() logr.Logger {
+	return ctrl.Log.WithName("cluster-test")
+}
+
+func (c *fakeKVRocks) GetOffset(ip string, password string) (int, error) {
+	return c.offsets[ip], nil
+}
+
+func (c *fakeKVRocks) GetReplicationInfo(ip string, password string) (*kvrocks.ReplicationInfo, error) {
+	return c.replication[ip], nil
+}
+
+// fakeController serves a shard of the kvrocks controller and records the mutating requests
+type fakeController struct {
+	nodes    []controller.Node
+	requests []string
+}
+
+func (c *fakeController) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorder := httptest.NewRecorder()
+	switch req.Method {
+	case http.MethodGet:
+		data, _ := json.Marshal(map[string]map[string]controller.ShardData{"data": {"shard": {Nodes: c.nodes}}})
+		_, _ = recorder.Write(data)
+	case http.MethodPost:
+		if strings.HasSuffix(req.URL.Path, "/failover") {
+			c.requests = append(c.requests, "FailoverShard")
+			break
+		}
+		var option controller.NodeOption
+		_ = json.NewDecoder(req.Body).Decode(&option)
+		c.nodes = append(c.nodes, controller.Node{ID: "node-new", Addr: option.Addr, Role: option.Role})
+		c.requests = append(c.requests, "AddNode "+option.Addr+" "+option.Role)
+		recorder.WriteHeader(http.StatusCreated)
+	case http.MethodDelete:
+		id := req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]
+		for i, node := range c.nodes {
+			if node.ID == id {
+				c.nodes = append(c.nodes[:i], c.nodes[i+1:]...)
+				break
+			}
+		}
+		c.requests = append(c.requests, "DeleteNode "+id)
+	}
+	return recorder.Result(), nil
+}
+
+// withController serves the requests of the kvrocks controller by the fake controller during the test
+func withController(t *testing.T, c *fakeController) {
+	transport := http.DefaultTransport
+	http.DefaultTransport = c
+	t.Cleanup(func() {
+		http.DefaultTransport = transport
+	})
+}
+
+// drainEvents returns the events recorded so far
+func drainEvents(recorder *record.FakeRecorder) []string {
+	var events []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func newTestInstance() *kvrocksv1alpha1.KVRocks {
+	return &kvrocksv1alpha1.KVRocks{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "unit-test",
+		},
+		Spec: kvrocksv1alpha1.KVRocksSpec{
+			Type:     kvrocksv1alpha1.ClusterType,
+			Master:   1,
+			Replicas: 2,
+			Password: "password",
+		},
+		Status: kvrocksv1alpha1.KVRocksStatus{
+			Status:          kvrocksv1alpha1.StatusRunning,
+			WorkloadBackend: kvrocksv1alpha1.StatefulSetWorkloadBackend,
+		},
+	}
+}
+
+func newTestHandler(t *testing.T, instance *kvrocksv1alpha1.KVRocks, kvClient kvrocks.Client, objs ...k8sApiClient.Object) (*KVRocksClusterHandler, k8sApiClient.Client, *record.FakeRecorder) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+	_ = kvrocksv1alpha1.AddToScheme(scheme)
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: kvrocks.ControllerServiceName, Namespace: instance.Namespace},
+		Spec:       corev1.ServiceSpec{ClusterIP: "10.96.0.1"},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(objs, instance, service)...).Build()
+	recorder := record.NewFakeRecorder(100)
+	log := ctrl.Log.WithName("cluster-test")
+	k8sClient := k8s.NewK8sClient(fakeClient, log)
+	controllerClient := controller.NewClient(log)
+	assert.NoError(t, controllerClient.SetEndPoint(instance.Namespace, k8sClient))
+	h := NewKVRocksClusterHandler(k8sClient, kvClient, log, k8sApiClient.ObjectKeyFromObject(instance), instance, controllerClient, recorder)
+	h.password = instance.Spec.Password
+	h.stsNodes = [][]*kvrocks.Node{{
+		{IP: "10.0.0.1", PodIndex: 0, Role: kvrocks.RoleMaster, NodeId: "node-0"},
+		{IP: "10.0.0.2", PodIndex: 1, Role: kvrocks.RoleSlaver, NodeId: "node-1", Master: "node-0"},
+	}}
+	return h, fakeClient, recorder
+}
+
+func TestRunResyncOperation(t *testing.T) {
+	stepTime := metav1.NewTime(time.Now().Add(-time.Minute).Truncate(time.Second))
+	shard := func(slave bool) []controller.Node {
+		nodes := []controller.Node{{ID: "node-0", Addr: "10.0.0.1:6379", Role: "master"}}
+		if slave {
+			nodes = append(nodes, controller.Node{ID: "node-1", Addr: "10.0.0.2:6379", Role: "slave"})
+		}
+		return nodes
+	}
+
+	tests := []struct {
+		name        string
+		pod         string
+		step        string
+		slaveInCtrl bool
+		podCreated  time.Time
+		slaveNodeId string
+		slaveInfo   *kvrocks.ReplicationInfo
+		expDone     bool
+		expErr      bool
+		expStep     string
+		expRequests []string
+		expWiped    bool
+	}{
+		{
+			name:        "A resync should remove the node from the controller before anything else.",
+			pod:         "test-0-1",
+			slaveInCtrl: true,
+			slaveNodeId: "node-1",
+			expStep:     common.OperationStepRemoveNode,
+		}, {
+			name:        "A resync of the master should fail.",
+			pod:         "test-0-0",
+			slaveInCtrl: true,
+			slaveNodeId: "node-1",
+			expErr:      true,
+		}, {
+			name:        "The node should be removed from the controller before it is wiped.",
+			pod:         "test-0-1",
+			step:        common.OperationStepRemoveNode,
+			slaveInCtrl: true,
+			slaveNodeId: "node-1",
+			expStep:     common.OperationStepWipe,
+			expRequests: []string{"DeleteNode node-1"},
+		}, {
+			name:        "A retried removal should not remove the node again.",
+			pod:         "test-0-1",
+			step:        common.OperationStepRemoveNode,
+			slaveNodeId: "node-1",
+			expStep:     common.OperationStepWipe,
+		}, {
+			name:     "The removed node should be wiped with its pvc.",
+			pod:      "test-0-1",
+			step:     common.OperationStepWipe,
+			expStep:  common.OperationStepAddNode,
+			expWiped: true,
+		}, {
+			name:       "The node should not be added back before the pod is recreated.",
+			pod:        "test-0-1",
+			step:       common.OperationStepAddNode,
+			podCreated: stepTime.Add(-time.Minute),
+			expStep:    common.OperationStepAddNode,
+		}, {
+			name:        "The recreated pod should be added back to the controller as a slave.",
+			pod:         "test-0-1",
+			step:        common.OperationStepAddNode,
+			podCreated:  stepTime.Add(time.Minute),
+			expStep:     common.OperationStepWaitSync,
+			expRequests: []string{"AddNode 10.0.0.2:6379 slave"},
+		}, {
+			name:        "A retried addition should not add the node again.",
+			pod:         "test-0-1",
+			step:        common.OperationStepAddNode,
+			slaveInCtrl: true,
+			podCreated:  stepTime.Add(time.Minute),
+			expStep:     common.OperationStepWaitSync,
+		}, {
+			name:        "The operation should wait until the full sync is done.",
+			pod:         "test-0-1",
+			step:        common.OperationStepWaitSync,
+			slaveInCtrl: true,
+			slaveNodeId: "node-1",
+			slaveInfo:   &kvrocks.ReplicationInfo{Role: kvrocks.RoleSlaver, LinkStatus: "up", SyncInProgress: true},
+			expStep:     common.OperationStepWaitSync,
+		}, {
+			name:        "The operation should be done after the full sync.",
+			pod:         "test-0-1",
+			step:        common.OperationStepWaitSync,
+			slaveInCtrl: true,
+			slaveNodeId: "node-1",
+			slaveInfo:   &kvrocks.ReplicationInfo{Role: kvrocks.RoleSlaver, LinkStatus: "up"},
+			expDone:     true,
+			expStep:     common.OperationStepWaitSync,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			fakeCtrl := &fakeController{nodes: shard(test.slaveInCtrl)}
+			withController(t, fakeCtrl)
+			instance := newTestInstance()
+			op := &kvrocksv1alpha1.KVRocksOperation{
+				ObjectMeta: metav1.ObjectMeta{Name: "resync", Namespace: instance.Namespace},
+				Spec: kvrocksv1alpha1.KVRocksOperationSpec{
+					Instance: instance.Name,
+					Type:     kvrocksv1alpha1.ResyncOperation,
+					Pod:      test.pod,
+				},
+			}
+			if test.step != "" {
+				op.Status = kvrocksv1alpha1.KVRocksOperationStatus{
+					Phase:     kvrocksv1alpha1.OperationRunning,
+					Step:      test.step,
+					StartTime: &stepTime,
+					StepTime:  &stepTime,
+				}
+			}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-0-1",
+					Namespace:         instance.Namespace,
+					CreationTimestamp: metav1.NewTime(test.podCreated),
+				},
+				Status: corev1.PodStatus{
+					Conditions: []corev1.PodCondition{{Type: corev1.ContainersReady, Status: corev1.ConditionTrue}},
+				},
+			}
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "data-test-0-1", Namespace: instance.Namespace},
+			}
+			kvClient := &fakeKVRocks{
+				offsets:     map[string]int{"10.0.0.1": 100, "10.0.0.2": 100},
+				replication: map[string]*kvrocks.ReplicationInfo{"10.0.0.2": test.slaveInfo},
+			}
+			h, fakeClient, _ := newTestHandler(t, instance, kvClient, op, pod, pvc)
+			h.stsNodes[0][1].NodeId = test.slaveNodeId
+
+			done, err := h.runOperation(op)
+			assert.Equal(test.expDone, done)
+			assert.Equal(test.expErr, err != nil)
+			assert.Equal(test.expStep, op.Status.Step)
+			assert.Equal(test.expRequests, fakeCtrl.requests)
+			err = fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: instance.Namespace, Name: pvc.Name}, &corev1.PersistentVolumeClaim{})
+			assert.Equal(test.expWiped, k8serrors.IsNotFound(err))
+		})
+	}
+}
+
+func TestGetResyncingPod(t *testing.T) {
+	tests := []struct {
+		name   string
+		phase  kvrocksv1alpha1.KVRocksOperationPhase
+		step   string
+		expPod string
+	}{
+		{
+			name: "A pending resync should not hold the node back.",
+		}, {
+			name:   "A node removed from the controller should be held back.",
+			phase:  kvrocksv1alpha1.OperationRunning,
+			step:   common.OperationStepWipe,
+			expPod: "test-0-1",
+		}, {
+			name:   "A node being recreated should be held back.",
+			phase:  kvrocksv1alpha1.OperationRunning,
+			step:   common.OperationStepAddNode,
+			expPod: "test-0-1",
+		}, {
+			name:  "A node added back should not be held back.",
+			phase: kvrocksv1alpha1.OperationRunning,
+			step:  common.OperationStepWaitSync,
+		}, {
+			name:  "A node of a failed resync should not be held back.",
+			phase: kvrocksv1alpha1.OperationFailed,
+			step:  common.OperationStepWipe,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			instance := newTestInstance()
+			op := &kvrocksv1alpha1.KVRocksOperation{
+				ObjectMeta: metav1.ObjectMeta{Name: "resync", Namespace: instance.Namespace},
+				Spec: kvrocksv1alpha1.KVRocksOperationSpec{
+					Instance: instance.Name,
+					Type:     kvrocksv1alpha1.ResyncOperation,
+					Pod:      "test-0-1",
+				},
+				Status: kvrocksv1alpha1.KVRocksOperationStatus{Phase: test.phase, Step: test.step},
+			}
+			h, _, _ := newTestHandler(t, instance, &fakeKVRocks{}, op)
+
+			pod, err := h.getResyncingPod()
+			assert.NoError(t, err)
+			assert.Equal(t, test.expPod, pod)
+		})
+	}
+}
+
+func TestRunSwitchoverOperation(t *testing.T) {
+	tests := []struct {
+		name      string
+		master    int
+		expDone   bool
+		expErr    bool
+		expEvents []string
+	}{
+		{
+			name:   "The operation should wait while the previous master is still the master.",
+			master: 0,
+		}, {
+			name:      "The operation should be done once the target is promoted.",
+			master:    1,
+			expDone:   true,
+			expEvents: []string{"Normal PromotedMaster pod test-0-1: promoted to master by Switchover operation switchover"},
+		}, {
+			name:   "The operation should fail if the controller promoted another slave.",
+			master: 2,
+			expErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			withController(t, &fakeController{})
+			instance := newTestInstance()
+			stepTime := metav1.Now()
+			op := &kvrocksv1alpha1.KVRocksOperation{
+				ObjectMeta: metav1.ObjectMeta{Name: "switchover", Namespace: instance.Namespace},
+				Spec: kvrocksv1alpha1.KVRocksOperationSpec{
+					Instance: instance.Name,
+					Type:     kvrocksv1alpha1.SwitchoverOperation,
+					Pod:      "test-0-1",
+				},
+				Status: kvrocksv1alpha1.KVRocksOperationStatus{
+					Phase:          kvrocksv1alpha1.OperationRunning,
+					Step:           common.OperationStepWaitPromotion,
+					PreviousMaster: "test-0-0",
+					StartTime:      &stepTime,
+					StepTime:       &stepTime,
+				},
+			}
+			h, _, recorder := newTestHandler(t, instance, &fakeKVRocks{}, op)
+			h.stsNodes[0] = append(h.stsNodes[0], &kvrocks.Node{IP: "10.0.0.3", PodIndex: 2, NodeId: "node-2"})
+			for i, node := range h.stsNodes[0] {
+				node.Role = kvrocks.RoleSlaver
+				if i == test.master {
+					node.Role = kvrocks.RoleMaster
+				}
+			}
+
+			done, err := h.runOperation(op)
+			assert.Equal(test.expDone, done)
+			assert.Equal(test.expErr, err != nil)
+			assert.Equal(test.expEvents, drainEvents(recorder))
+		})
+	}
+}
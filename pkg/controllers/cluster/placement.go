@@ -0,0 +1,115 @@
+package cluster
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/controllers/common"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+// ensureZoneBalance spreads the masters across zones after failover. One shard is switched over at a time, its
+// master in the zone with the most masters is moved to a slave in the zone with the fewest
+func (h *KVRocksClusterHandler) ensureZoneBalance() error {
+	if !resources.RebalanceMasters(h.instance) || h.instance.Status.Shrink != nil || h.instance.Status.Rebalance {
+		return nil
+	}
+	zones, err := h.getNodeZones()
+	if err != nil || zones == nil {
+		return err
+	}
+	partition, target := balanceMasters(h.stsNodes, zones)
+	if target == nil {
+		return nil
+	}
+	if err = h.controllerClient.FailoverShardTo(partition, target.NodeId); err != nil {
+		return err
+	}
+	h.podEventf(partition, target.PodIndex, corev1.EventTypeNormal, common.ReasonPromotedMaster, "promoted to master to spread masters across zones")
+	h.log.Info("switch over to spread masters across zones", "partition", partition, "to", target.IP, "zone", zones[target])
+	h.requeue = true
+	return h.notifySentinel()
+}
+
+// getNodeZones returns the zone of the k8s node where each kvrocks node runs, it returns nil if any zone is unknown
+func (h *KVRocksClusterHandler) getNodeZones() (map[*kvrocks.Node]string, error) {
+	zoneKey := resources.GetZoneKey(h.instance)
+	nodeZones := map[string]string{}
+	zones := map[*kvrocks.Node]string{}
+	for index, sts := range h.stsNodes {
+		key := types.NamespacedName{
+			Namespace: h.instance.Namespace,
+			Name:      resources.GetStatefulSetName(h.instance.Name, index),
+		}
+		pods, err := h.workload.ListStatefulSetPods(key)
+		if err != nil {
+			return nil, err
+		}
+		podNodes := map[string]string{}
+		for _, pod := range pods.Items {
+			podNodes[pod.Name] = pod.Spec.NodeName
+		}
+		for _, node := range sts {
+			if node.Failover || node.NodeId == "" {
+				return nil, nil
+			}
+			nodeName := podNodes[fmt.Sprintf("%s-%d", key.Name, node.PodIndex)]
+			if nodeName == "" {
+				return nil, nil
+			}
+			zone, ok := nodeZones[nodeName]
+			if !ok {
+				k8sNode, err := h.k8s.GetNode(nodeName)
+				if err != nil {
+					return nil, err
+				}
+				zone = k8sNode.Labels[zoneKey]
+				nodeZones[nodeName] = zone
+			}
+			if zone == "" {
+				return nil, nil
+			}
+			zones[node] = zone
+		}
+	}
+	return zones, nil
+}
+
+// balanceMasters picks the slave to be promoted, which moves a master from the most loaded zone to the least loaded
+// one. It returns nil if no switchover narrows the difference of the masters between zones. The nodes whose zones are
+// unknown are neither counted nor promoted
+func balanceMasters(stsNodes [][]*kvrocks.Node, zones map[*kvrocks.Node]string) (int, *kvrocks.Node) {
+	masters := map[string]int{}
+	shardMasters := make([]*kvrocks.Node, len(stsNodes))
+	for partition, sts := range stsNodes {
+		for _, node := range sts {
+			if _, ok := zones[node]; !ok {
+				continue
+			}
+			if node.Role == kvrocks.RoleMaster {
+				shardMasters[partition] = node
+				masters[zones[node]]++
+			}
+		}
+	}
+	partition, gain := -1, 1
+	var target *kvrocks.Node
+	for i, sts := range stsNodes {
+		if shardMasters[i] == nil {
+			continue
+		}
+		from := masters[zones[shardMasters[i]]]
+		for _, node := range sts {
+			if _, ok := zones[node]; !ok || node.Role == kvrocks.RoleMaster {
+				continue
+			}
+			if delta := from - masters[zones[node]]; delta > gain {
+				partition, gain, target = i, delta, node
+			}
+		}
+	}
+	return partition, target
+}
@@ -0,0 +1,83 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+)
+
+func TestBalanceMasters(t *testing.T) {
+	// each shard is described by the zones of its nodes, the first node is the master. An empty zone is unknown
+	tests := []struct {
+		name         string
+		shards       [][]string
+		expPartition int
+		expTarget    int
+	}{
+		{
+			name:         "A master in the most loaded zone should be moved to the least loaded zone.",
+			shards:       [][]string{{"a", "b", "c"}, {"a", "b", "c"}, {"a", "b", "c"}},
+			expPartition: 0,
+			expTarget:    1,
+		}, {
+			name:         "The shard whose slave is in the least loaded zone should be switched over.",
+			shards:       [][]string{{"a", "b"}, {"a", "c"}, {"b", "a"}},
+			expPartition: 1,
+			expTarget:    1,
+		}, {
+			name:         "Masters spread across zones should not be moved.",
+			shards:       [][]string{{"a", "b", "c"}, {"b", "c", "a"}, {"c", "a", "b"}},
+			expPartition: -1,
+		}, {
+			name:         "A difference of one master should not be moved.",
+			shards:       [][]string{{"a", "b"}, {"a", "b"}, {"b", "a"}},
+			expPartition: -1,
+		}, {
+			name:         "Masters in a single zone should not be moved.",
+			shards:       [][]string{{"a", "a", "a"}, {"a", "a", "a"}},
+			expPartition: -1,
+		}, {
+			name:         "A slave in an unknown zone should not be promoted.",
+			shards:       [][]string{{"a", ""}, {"a", ""}, {"a", "b"}},
+			expPartition: 2,
+			expTarget:    1,
+		}, {
+			name:         "A master in an unknown zone should not be counted.",
+			shards:       [][]string{{"", "b"}, {"", "b"}, {"a", "b"}},
+			expPartition: -1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var stsNodes [][]*kvrocks.Node
+			zones := map[*kvrocks.Node]string{}
+			for _, shard := range test.shards {
+				var sts []*kvrocks.Node
+				for i, zone := range shard {
+					node := &kvrocks.Node{Role: kvrocks.RoleSlaver, PodIndex: i}
+					if i == 0 {
+						node.Role = kvrocks.RoleMaster
+					}
+					if zone != "" {
+						zones[node] = zone
+					}
+					sts = append(sts, node)
+				}
+				stsNodes = append(stsNodes, sts)
+			}
+
+			partition, target := balanceMasters(stsNodes, zones)
+			assert.Equal(test.expPartition, partition)
+			if test.expPartition < 0 {
+				assert.Nil(target)
+				return
+			}
+			assert.Equal(stsNodes[test.expPartition][test.expTarget], target)
+		})
+	}
+}
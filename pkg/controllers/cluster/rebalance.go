@@ -4,7 +4,12 @@ import (
 	"errors"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+
 	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/controllers/common"
+	"github.com/RocksLabs/kvrocks-operator/pkg/metrics"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
 )
 
 func (h *KVRocksClusterHandler) ensureMigrate() error {
@@ -19,7 +24,8 @@ func (h *KVRocksClusterHandler) ensureMigrate() error {
 		}
 	}
 	for index, master := range masters {
-		if master.Migrate != nil {
+		// slots are not moved under maintenance, the topology is still refreshed
+		if master.Migrate != nil && !resources.IsUnderMaintenance(h.instance) {
 			h.requeue = true
 			return h.ensureReBalanceTopo(index, master)
 		}
@@ -38,17 +44,20 @@ func (h *KVRocksClusterHandler) ensureReBalanceTopo(src int, node *kvrocks.Node)
 		moveSlots:
 			err := h.controllerClient.MigrateSlotAndData(src, dest, slot)
 			if err != nil {
+				metrics.SlotMigrationFailures.WithLabelValues(h.key.Namespace, h.key.Name).Inc()
 				h.log.Error(err, "move slot error")
 				if retry < 5 {
 					time.Sleep(wait)
 				} else {
 					h.log.Error(errors.New("slot migrate timeout"), "slot migrate timeout")
+					common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder).Eventf(corev1.EventTypeWarning, common.ReasonSlotMigrationFailed, "migrate slot %d from shard %d to %d failed: %v", slot, src, dest, err)
 					return errors.New("slot migrate timeout")
 				}
 				retry++
 				wait *= 10
 				goto moveSlots
 			}
+			metrics.SlotsMigrated.WithLabelValues(h.key.Namespace, h.key.Name).Inc()
 		}
 		node.Slots = node.Slots[len(migrate.Slots):]
 		node.Migrate = node.Migrate[1:]
@@ -56,6 +65,7 @@ func (h *KVRocksClusterHandler) ensureReBalanceTopo(src int, node *kvrocks.Node)
 			return err
 		}
 		h.log.Info("move slots successfully", "src", src, "dst", dest, "slots", migrate.Slots)
+		common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder).Eventf(corev1.EventTypeNormal, common.ReasonSlotsMigrated, "%d slots are migrated from shard %d to %d", len(migrate.Slots), src, dest)
 	}
 	return nil
 }
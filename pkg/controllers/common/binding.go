@@ -0,0 +1,68 @@
+package common
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+// EnsureConnectionSecret publishes the connection info in the <name>-connection secret and references it in
+// status.binding, so that apps can bind kvrocks without knowing the naming conventions. clusterNodes are the seed
+// nodes of the cluster mode
+func (h *CommandHandler) EnsureConnectionSecret(clusterNodes []string) error {
+	data := map[string]string{
+		"type":      "redis",
+		"provider":  "kvrocks",
+		"mode":      string(h.instance.Spec.Type),
+		"host":      fmt.Sprintf("%s.%s.svc", h.instance.Name, h.instance.Namespace),
+		"read-host": fmt.Sprintf("%s.%s.svc", resources.GetReadServiceName(h.instance.Name), h.instance.Namespace),
+		"port":      strconv.Itoa(kvrocks.KVRocksPort),
+		"password":  h.instance.Spec.Password,
+	}
+	if len(clusterNodes) != 0 {
+		data["cluster-nodes"] = strings.Join(clusterNodes, ",")
+	}
+	if sentinelName, ok := h.instance.Labels[resources.MonitoredBy]; ok {
+		_, _, requeue, err := h.GetSentinel(types.NamespacedName{
+			Namespace: h.instance.Namespace,
+			Name:      sentinelName,
+		})
+		if err != nil {
+			return err
+		}
+		if !requeue {
+			// the headless service resolves to all sentinels, the pod ips change when the sentinels are recreated
+			data["sentinel-addresses"] = resources.GetSentinelAddress(sentinelName, h.instance.Namespace)
+			data["sentinel-master-names"] = strings.Join(h.sentinelMasterNames(), ",")
+		}
+	}
+	if err := h.k8s.CreateOrUpdateSecret(resources.NewConnectionSecret(h.instance, data)); err != nil {
+		return err
+	}
+	binding := &corev1.LocalObjectReference{Name: resources.GetConnectionSecretName(h.instance.Name)}
+	if h.instance.Status.Binding != nil && *h.instance.Status.Binding == *binding {
+		return nil
+	}
+	h.instance.Status.Binding = binding
+	return h.k8s.UpdateKVRocks(h.instance)
+}
+
+// sentinelMasterNames returns the names which the masters are monitored by, one for each shard in cluster mode
+func (h *CommandHandler) sentinelMasterNames() []string {
+	_, masterName := resources.ParseRedisName(h.instance.Name)
+	if h.instance.Spec.Type != kvrocksv1alpha1.ClusterType {
+		return []string{masterName}
+	}
+	var names []string
+	for index := 0; index < int(h.instance.Spec.Master); index++ {
+		names = append(names, fmt.Sprintf("%s-%d", masterName, index))
+	}
+	return names
+}
@@ -0,0 +1,73 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+func TestEnsureConnectionSecret(t *testing.T) {
+	tests := []struct {
+		name           string
+		sentinelStatus kvrocksv1alpha1.KVRocksStatusType
+		expData        map[string]string
+	}{
+		{
+			name:           "The headless service of the sentinels should be published.",
+			sentinelStatus: kvrocksv1alpha1.StatusRunning,
+			expData: map[string]string{
+				"sentinel-addresses":    "sentinel-1-headless.unit-test.svc:26379",
+				"sentinel-master-names": "demo",
+			},
+		}, {
+			name:           "The sentinel info should not be published until the sentinels are running.",
+			sentinelStatus: kvrocksv1alpha1.StatusCreating,
+			expData:        map[string]string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			instance := newTestInstance()
+			instance.Name = "kvrocks-standard-1-demo"
+			instance.Labels = map[string]string{resources.MonitoredBy: "sentinel-1"}
+			sentinel := &kvrocksv1alpha1.KVRocks{
+				ObjectMeta: metav1.ObjectMeta{Name: "sentinel-1", Namespace: instance.Namespace},
+				Spec:       kvrocksv1alpha1.KVRocksSpec{Type: kvrocksv1alpha1.SentinelType, Password: "sentinel"},
+				Status:     kvrocksv1alpha1.KVRocksStatus{Status: test.sentinelStatus},
+			}
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "sentinel-1", Namespace: instance.Namespace},
+				Spec: appsv1.DeploymentSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "sentinel-1"}},
+				},
+			}
+			h, fakeClient, _ := newTestHandler(instance, newFakeKVRocks(), sentinel, deployment)
+
+			assert.NoError(h.EnsureConnectionSecret(nil))
+			var secret corev1.Secret
+			assert.NoError(fakeClient.Get(context.TODO(), types.NamespacedName{
+				Namespace: instance.Namespace,
+				Name:      resources.GetConnectionSecretName(instance.Name),
+			}, &secret))
+			assert.Equal("kvrocks-standard-1-demo.unit-test.svc", string(secret.Data["host"]))
+			for _, key := range []string{"sentinel-addresses", "sentinel-master-names"} {
+				value, ok := secret.Data[key]
+				if expValue, expOK := test.expData[key]; assert.Equal(expOK, ok) && ok {
+					assert.Equal(expValue, string(value))
+				}
+			}
+			assert.Equal(resources.GetConnectionSecretName(instance.Name), instance.Status.Binding.Name)
+		})
+	}
+}
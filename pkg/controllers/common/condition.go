@@ -0,0 +1,24 @@
+package common
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+)
+
+// SetCondition sets the condition of the instance, returns true if the condition is changed
+func SetCondition(instance *kvrocksv1alpha1.KVRocks, conditionType string, status metav1.ConditionStatus, reason, message string) bool {
+	old := meta.FindStatusCondition(instance.Status.Conditions, conditionType)
+	if old != nil && old.Status == status && old.Reason == reason && old.Message == message {
+		return false
+	}
+	meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: instance.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	return true
+}
@@ -1,29 +1,163 @@
 package common
 
 import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
 	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
 	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
 )
 
-func (h *CommandHandler) EnsureConfig(nodes []*kvrocks.Node) error {
-	config := resources.ParseKVRocksConfigs(h.instance.Spec.KVRocksConfig)
+// EnsureConfig applies the online changeable configs to the nodes of a statefulSet and persists them by CONFIG REWRITE.
+// All nodes are checked even if some of them fail, the returned statuses report the convergence of each node.
+func (h *CommandHandler) EnsureConfig(stsName string, nodes []*kvrocks.Node) ([]kvrocksv1alpha1.KVRocksNodeConfig, error) {
+	config := resources.ParseKVRocksConfigs(resources.GetKVRocksConfig(h.instance))
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var statuses []kvrocksv1alpha1.KVRocksNodeConfig
+	var diverged []string
 	for _, node := range nodes {
-		for key, value := range config {
-			curValue, err := h.kvrocks.GetConfig(node.IP, h.password, key)
-			if err != nil {
-				return err
+		if node == nil {
+			continue
+		}
+		status := h.ensureNodeConfig(fmt.Sprintf("%s-%d", stsName, node.PodIndex), node, keys, config)
+		if status.Message != "" {
+			diverged = append(diverged, status.Pod)
+		}
+		statuses = append(statuses, status)
+	}
+	if len(diverged) != 0 {
+		return statuses, fmt.Errorf("failed to apply config on %s", strings.Join(diverged, ","))
+	}
+	return statuses, nil
+}
+
+func (h *CommandHandler) ensureNodeConfig(pod string, node *kvrocks.Node, keys []string, config map[string]string) kvrocksv1alpha1.KVRocksNodeConfig {
+	status := kvrocksv1alpha1.KVRocksNodeConfig{
+		Pod:       pod,
+		Converged: true,
+		Persisted: true,
+	}
+	// the persistence is only known after CONFIG REWRITE, it is carried forward once the node is observed
+	observed := false
+	for _, old := range h.instance.Status.NodeConfig {
+		if old.Pod == pod && old.Message == "" {
+			status.Persisted = old.Persisted
+			observed = true
+			break
+		}
+	}
+	var changed []string
+	for _, key := range keys {
+		value := config[key]
+		curValue, err := h.kvrocks.GetConfig(node.IP, h.password, key)
+		if err != nil {
+			status.Converged = false
+			status.Message = err.Error()
+			return status
+		}
+		if *curValue == value {
+			continue
+		}
+		// the key is not changed in spec since it was applied, someone changed it by CONFIG SET
+		if applied, ok := h.instance.Status.AppliedConfig[key]; ok && applied == value {
+			status.Drifted = append(status.Drifted, key)
+			if h.instance.Spec.ConfigDriftPolicy == kvrocksv1alpha1.ConfigDriftReport {
+				status.Converged = false
+				continue
 			}
-			if *curValue != value {
-				if err = h.kvrocks.SetConfig(node.IP, h.password, key, value); err != nil {
-					return err
+		}
+		if err = h.kvrocks.SetConfig(node.IP, h.password, key, value); err != nil {
+			status.Converged = false
+			status.Message = err.Error()
+			h.PodEventf(pod, corev1.EventTypeWarning, ReasonConfigFailed, "set config %s failed: %v", key, err)
+			return status
+		}
+		changed = append(changed, key)
+	}
+	if h.password != h.instance.Spec.Password {
+		if err := h.kvrocks.ChangePassword(node.IP, h.password, h.instance.Spec.Password); err != nil {
+			status.Converged = false
+			status.Message = err.Error()
+			h.PodEventf(pod, corev1.EventTypeWarning, ReasonConfigFailed, "change password failed: %v", err)
+			return status
+		}
+		changed = append(changed, "requirepass")
+	}
+	if len(changed) != 0 {
+		h.PodEventf(pod, corev1.EventTypeNormal, ReasonConfigChanged, "config %s is changed", strings.Join(changed, ","))
+	}
+	if len(changed) != 0 || !observed {
+		// CONFIG REWRITE fails if the node still runs with the read-only config file of the configMap
+		status.Persisted = h.kvrocks.RewriteConfig(node.IP, h.instance.Spec.Password) == nil
+	}
+	return status
+}
+
+// UpdateConfigStatus records the config convergence of all nodes and the config applied to them
+func (h *CommandHandler) UpdateConfigStatus(statuses []kvrocksv1alpha1.KVRocksNodeConfig) error {
+	var failed, drifted []string
+	driftedKeys := map[string]struct{}{}
+	for _, status := range statuses {
+		if status.Message != "" {
+			failed = append(failed, status.Pod)
+		}
+		if len(status.Drifted) != 0 {
+			drifted = append(drifted, fmt.Sprintf("%s(%s)", status.Pod, strings.Join(status.Drifted, ",")))
+			if h.instance.Spec.ConfigDriftPolicy == kvrocksv1alpha1.ConfigDriftReport {
+				for _, key := range status.Drifted {
+					driftedKeys[key] = struct{}{}
 				}
 			}
 		}
-		if h.password != h.instance.Spec.Password {
-			if err := h.kvrocks.ChangePassword(node.IP, h.password, h.instance.Spec.Password); err != nil {
-				return err
+	}
+	applied := h.instance.Status.AppliedConfig
+	if len(failed) == 0 {
+		applied = map[string]string{}
+		for key, value := range resources.ParseKVRocksConfigs(resources.GetKVRocksConfig(h.instance)) {
+			if _, ok := driftedKeys[key]; ok {
+				if old, ok := h.instance.Status.AppliedConfig[key]; ok {
+					applied[key] = old
+				}
+				continue
 			}
+			applied[key] = value
 		}
 	}
-	return nil
+	changed := false
+	switch {
+	case len(failed) != 0:
+		changed = SetCondition(h.instance, kvrocksv1alpha1.ConditionConfigConverged, metav1.ConditionFalse,
+			"ApplyFailed", "failed to apply config on "+strings.Join(failed, ","))
+	case len(driftedKeys) != 0:
+		changed = SetCondition(h.instance, kvrocksv1alpha1.ConditionConfigConverged, metav1.ConditionFalse,
+			"Drifted", "config changed outside the operator: "+strings.Join(drifted, " "))
+	case len(drifted) != 0:
+		changed = SetCondition(h.instance, kvrocksv1alpha1.ConditionConfigConverged, metav1.ConditionTrue,
+			"DriftReset", "config changed outside the operator is reset: "+strings.Join(drifted, " "))
+	default:
+		changed = SetCondition(h.instance, kvrocksv1alpha1.ConditionConfigConverged, metav1.ConditionTrue,
+			"Converged", "all nodes run with the config in spec")
+	}
+	if !reflect.DeepEqual(h.instance.Status.NodeConfig, statuses) {
+		h.instance.Status.NodeConfig = statuses
+		changed = true
+	}
+	if (len(applied) != 0 || len(h.instance.Status.AppliedConfig) != 0) && !reflect.DeepEqual(h.instance.Status.AppliedConfig, applied) {
+		h.instance.Status.AppliedConfig = applied
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return h.k8s.UpdateKVRocks(h.instance)
 }
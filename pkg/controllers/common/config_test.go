@@ -0,0 +1,190 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+)
+
+func TestEnsureConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      kvrocksv1alpha1.ConfigDriftPolicy
+		applied     map[string]string
+		live        map[string]string
+		down        bool
+		unobserved  bool
+		unpersisted bool
+		readOnly    bool
+		expErr      bool
+		expStatus   kvrocksv1alpha1.KVRocksNodeConfig
+		expCommands []string
+		expEvents   []string
+	}{
+		{
+			name:    "Configs equal to the spec should not be set.",
+			applied: map[string]string{"maxclients": "100"},
+			live:    map[string]string{"maxclients": "100"},
+			expStatus: kvrocksv1alpha1.KVRocksNodeConfig{
+				Pod: "test-0", Converged: true, Persisted: true,
+			},
+		}, {
+			name: "A changed config should be set and persisted.",
+			live: map[string]string{"maxclients": "50"},
+			expStatus: kvrocksv1alpha1.KVRocksNodeConfig{
+				Pod: "test-0", Converged: true, Persisted: true,
+			},
+			expCommands: []string{"CONFIG SET 10.0.0.1 maxclients 100", "CONFIG REWRITE 10.0.0.1"},
+			expEvents:   []string{"Normal ConfigChanged pod test-0: config maxclients is changed"},
+		}, {
+			name:    "A drifted config should be reset by default.",
+			applied: map[string]string{"maxclients": "100"},
+			live:    map[string]string{"maxclients": "50"},
+			expStatus: kvrocksv1alpha1.KVRocksNodeConfig{
+				Pod: "test-0", Converged: true, Persisted: true, Drifted: []string{"maxclients"},
+			},
+			expCommands: []string{"CONFIG SET 10.0.0.1 maxclients 100", "CONFIG REWRITE 10.0.0.1"},
+			expEvents:   []string{"Normal ConfigChanged pod test-0: config maxclients is changed"},
+		}, {
+			name:    "A drifted config should only be reported by the Report policy.",
+			policy:  kvrocksv1alpha1.ConfigDriftReport,
+			applied: map[string]string{"maxclients": "100"},
+			live:    map[string]string{"maxclients": "50"},
+			expStatus: kvrocksv1alpha1.KVRocksNodeConfig{
+				Pod: "test-0", Converged: false, Persisted: true, Drifted: []string{"maxclients"},
+			},
+		}, {
+			name:   "An unreachable node should fail with the error in status.",
+			down:   true,
+			expErr: true,
+			expStatus: kvrocksv1alpha1.KVRocksNodeConfig{
+				Pod: "test-0", Converged: false, Persisted: true, Message: "10.0.0.1 is down",
+			},
+		}, {
+			name:       "A node observed the first time should be checked by CONFIG REWRITE.",
+			live:       map[string]string{"maxclients": "100"},
+			unobserved: true,
+			expStatus: kvrocksv1alpha1.KVRocksNodeConfig{
+				Pod: "test-0", Converged: true, Persisted: true,
+			},
+			expCommands: []string{"CONFIG REWRITE 10.0.0.1"},
+		}, {
+			name:       "A node with a read-only config file should not be persisted at the first observation.",
+			live:       map[string]string{"maxclients": "100"},
+			unobserved: true,
+			readOnly:   true,
+			expStatus: kvrocksv1alpha1.KVRocksNodeConfig{
+				Pod: "test-0", Converged: true, Persisted: false,
+			},
+			expCommands: []string{"CONFIG REWRITE 10.0.0.1"},
+		}, {
+			name:        "The persistence of an observed node should be carried forward.",
+			live:        map[string]string{"maxclients": "100"},
+			unpersisted: true,
+			expStatus: kvrocksv1alpha1.KVRocksNodeConfig{
+				Pod: "test-0", Converged: true, Persisted: false,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			instance := newTestInstance()
+			instance.Spec.KVRocksConfig = map[string]string{"maxclients": "100"}
+			instance.Spec.ConfigDriftPolicy = test.policy
+			instance.Status.AppliedConfig = test.applied
+			if !test.unobserved {
+				instance.Status.NodeConfig = []kvrocksv1alpha1.KVRocksNodeConfig{
+					{Pod: "test-0", Converged: true, Persisted: !test.unpersisted},
+				}
+			}
+			kvClient := newFakeKVRocks()
+			for key, value := range test.live {
+				kvClient.configs["10.0.0.1/"+key] = value
+			}
+			kvClient.down["10.0.0.1"] = test.down
+			kvClient.readOnly["10.0.0.1"] = test.readOnly
+			h, _, recorder := newTestHandler(instance, kvClient)
+
+			statuses, err := h.EnsureConfig("test", []*kvrocks.Node{{IP: "10.0.0.1", PodIndex: 0}, nil})
+			if test.expErr {
+				assert.Error(err)
+			} else {
+				assert.NoError(err)
+			}
+			assert.Equal([]kvrocksv1alpha1.KVRocksNodeConfig{test.expStatus}, statuses)
+			assert.Equal(test.expCommands, kvClient.commands)
+			assert.Equal(test.expEvents, drainEvents(recorder))
+		})
+	}
+}
+
+func TestUpdateConfigStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       kvrocksv1alpha1.ConfigDriftPolicy
+		applied      map[string]string
+		statuses     []kvrocksv1alpha1.KVRocksNodeConfig
+		expStatus    metav1.ConditionStatus
+		expReason    string
+		expAppliedMC string
+	}{
+		{
+			name:         "Converged nodes should record the spec as applied.",
+			statuses:     []kvrocksv1alpha1.KVRocksNodeConfig{{Pod: "test-0", Converged: true, Persisted: true}},
+			expStatus:    metav1.ConditionTrue,
+			expReason:    "Converged",
+			expAppliedMC: "100",
+		}, {
+			name:         "A failed node should keep the applied configs.",
+			applied:      map[string]string{"maxclients": "50"},
+			statuses:     []kvrocksv1alpha1.KVRocksNodeConfig{{Pod: "test-0", Message: "timeout"}},
+			expStatus:    metav1.ConditionFalse,
+			expReason:    "ApplyFailed",
+			expAppliedMC: "50",
+		}, {
+			name:         "Reset drifted configs should be reported as converged.",
+			applied:      map[string]string{"maxclients": "100"},
+			statuses:     []kvrocksv1alpha1.KVRocksNodeConfig{{Pod: "test-0", Converged: true, Persisted: true, Drifted: []string{"maxclients"}}},
+			expStatus:    metav1.ConditionTrue,
+			expReason:    "DriftReset",
+			expAppliedMC: "100",
+		}, {
+			name:         "Reported drifted configs should keep the applied value.",
+			policy:       kvrocksv1alpha1.ConfigDriftReport,
+			applied:      map[string]string{"maxclients": "50"},
+			statuses:     []kvrocksv1alpha1.KVRocksNodeConfig{{Pod: "test-0", Persisted: true, Drifted: []string{"maxclients"}}},
+			expStatus:    metav1.ConditionFalse,
+			expReason:    "Drifted",
+			expAppliedMC: "50",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			instance := newTestInstance()
+			instance.Spec.KVRocksConfig = map[string]string{"maxclients": "100"}
+			instance.Spec.ConfigDriftPolicy = test.policy
+			instance.Status.AppliedConfig = test.applied
+			h, _, _ := newTestHandler(instance, newFakeKVRocks())
+
+			assert.NoError(h.UpdateConfigStatus(test.statuses))
+			condition := meta.FindStatusCondition(instance.Status.Conditions, kvrocksv1alpha1.ConditionConfigConverged)
+			if assert.NotNil(condition) {
+				assert.Equal(test.expStatus, condition.Status)
+				assert.Equal(test.expReason, condition.Reason)
+			}
+			assert.Equal(test.statuses, instance.Status.NodeConfig)
+			assert.Equal(test.expAppliedMC, instance.Status.AppliedConfig["maxclients"])
+		})
+	}
+}
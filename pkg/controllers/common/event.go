@@ -0,0 +1,53 @@
+package common
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// reasons of the events recorded against the kvrocks and its pods
+const (
+	ReasonFailover                = "Failover"
+	ReasonFailoverFailed          = "FailoverFailed"
+	ReasonFailoverSuppressed      = "FailoverSuppressed"
+	ReasonPromotedMaster          = "PromotedMaster"
+	ReasonReplicaRepointed        = "ReplicaRepointed"
+	ReasonPodReplaced             = "PodReplaced"
+	ReasonPVCDeleted              = "PVCDeleted"
+	ReasonShardCreated            = "ShardCreated"
+	ReasonShardDeleted            = "ShardDeleted"
+	ReasonNodeAdded               = "NodeAdded"
+	ReasonNodeRemoved             = "NodeRemoved"
+	ReasonConfigChanged           = "ConfigChanged"
+	ReasonConfigFailed            = "ConfigFailed"
+	ReasonSlotsMigrated           = "SlotsMigrated"
+	ReasonSlotMigrationFailed     = "SlotMigrationFailed"
+	ReasonMonitorUpdated          = "MonitorUpdated"
+	ReasonOperationStarted        = "OperationStarted"
+	ReasonOperationSucceeded      = "OperationSucceeded"
+	ReasonOperationFailed         = "OperationFailed"
+	ReasonReseed                  = "Reseed"
+	ReasonStorageAutoScaled       = "StorageAutoScaled"
+	ReasonStorageMaxSize          = "StorageMaxSize"
+	ReasonStorageAutoScaleBlocked = "StorageAutoScaleBlocked"
+)
+
+// Eventf records an event against the kvrocks
+func (h *CommandHandler) Eventf(eventtype, reason, messageFmt string, args ...interface{}) {
+	h.recorder.Eventf(h.instance, eventtype, reason, messageFmt, args...)
+}
+
+// PodEventf records an event against the kvrocks with the pod name, and against the pod if it still exists
+func (h *CommandHandler) PodEventf(podName, eventtype, reason, messageFmt string, args ...interface{}) {
+	message := fmt.Sprintf(messageFmt, args...)
+	h.recorder.Eventf(h.instance, eventtype, reason, "pod %s: %s", podName, message)
+	pod, err := h.k8s.GetPod(types.NamespacedName{
+		Namespace: h.instance.Namespace,
+		Name:      podName,
+	})
+	if err != nil {
+		return
+	}
+	h.recorder.Event(pod, eventtype, reason, message)
+}
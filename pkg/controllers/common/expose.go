@@ -0,0 +1,99 @@
+package common
+
+import (
+	"net"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+// GetExposedAddr returns the external address of the pod service in host:port, it is empty until the load balancer
+// is assigned or the pod is scheduled
+func (h *CommandHandler) GetExposedAddr(podName string) (string, error) {
+	key := types.NamespacedName{
+		Namespace: h.instance.Namespace,
+		Name:      podName,
+	}
+	service, err := h.k8s.GetService(types.NamespacedName{
+		Namespace: h.instance.Namespace,
+		Name:      resources.GetPodServiceName(podName),
+	})
+	if err != nil {
+		return "", err
+	}
+	switch service.Spec.Type {
+	case corev1.ServiceTypeLoadBalancer:
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			if ingress.IP != "" {
+				return net.JoinHostPort(ingress.IP, strconv.Itoa(kvrocks.KVRocksPort)), nil
+			}
+			if ingress.Hostname != "" {
+				return net.JoinHostPort(ingress.Hostname, strconv.Itoa(kvrocks.KVRocksPort)), nil
+			}
+		}
+	case corev1.ServiceTypeNodePort:
+		if len(service.Spec.Ports) == 0 || service.Spec.Ports[0].NodePort == 0 {
+			return "", nil
+		}
+		pod, err := h.k8s.GetPod(key)
+		if err != nil || pod.Spec.NodeName == "" {
+			return "", err
+		}
+		node, err := h.k8s.GetNode(pod.Spec.NodeName)
+		if err != nil {
+			return "", err
+		}
+		port := strconv.Itoa(int(service.Spec.Ports[0].NodePort))
+		if host := nodeAddress(node, corev1.NodeExternalIP); host != "" {
+			return net.JoinHostPort(host, port), nil
+		}
+		if host := nodeAddress(node, corev1.NodeInternalIP); host != "" {
+			return net.JoinHostPort(host, port), nil
+		}
+	}
+	return "", nil
+}
+
+func nodeAddress(node *corev1.Node, addressType corev1.NodeAddressType) string {
+	for _, address := range node.Status.Addresses {
+		if address.Type == addressType {
+			return address.Address
+		}
+	}
+	return ""
+}
+
+// EnsureAnnounce sets the announce address of the node to addr in host:port, which the node reports to its master
+// in INFO replication. CLUSTER NODES and MOVED still return the address registered in the controller. The announce
+// address is reset if addr is empty
+func (h *CommandHandler) EnsureAnnounce(node *kvrocks.Node, addr string) error {
+	host, port := "", "0"
+	if addr != "" {
+		var err error
+		if host, port, err = net.SplitHostPort(addr); err != nil {
+			return err
+		}
+	}
+	changed := false
+	for _, config := range [][2]string{{kvrocks.AnnounceIP, host}, {kvrocks.AnnouncePort, port}} {
+		value, err := h.kvrocks.GetConfig(node.IP, h.password, config[0])
+		if err != nil {
+			return err
+		}
+		if *value == config[1] {
+			continue
+		}
+		if err = h.kvrocks.SetConfig(node.IP, h.password, config[0], config[1]); err != nil {
+			return err
+		}
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return h.kvrocks.RewriteConfig(node.IP, h.password)
+}
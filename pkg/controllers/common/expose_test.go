@@ -0,0 +1,126 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sApiClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+func TestGetExposedAddr(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceType corev1.ServiceType
+		ingress     []corev1.LoadBalancerIngress
+		nodePort    int32
+		nodeName    string
+		expAddr     string
+	}{
+		{
+			name:        "The ingress ip of the load balancer should be returned.",
+			serviceType: corev1.ServiceTypeLoadBalancer,
+			ingress:     []corev1.LoadBalancerIngress{{IP: "1.2.3.4"}},
+			expAddr:     "1.2.3.4:6379",
+		}, {
+			name:        "The ingress hostname of the load balancer should be returned.",
+			serviceType: corev1.ServiceTypeLoadBalancer,
+			ingress:     []corev1.LoadBalancerIngress{{Hostname: "lb.example.com"}},
+			expAddr:     "lb.example.com:6379",
+		}, {
+			name:        "The address should be empty until the load balancer is assigned.",
+			serviceType: corev1.ServiceTypeLoadBalancer,
+			expAddr:     "",
+		}, {
+			name:        "The external ip of the node should be returned with the node port.",
+			serviceType: corev1.ServiceTypeNodePort,
+			nodePort:    30001,
+			nodeName:    "node-1",
+			expAddr:     "5.6.7.8:30001",
+		}, {
+			name:        "The address should be empty until the pod is scheduled.",
+			serviceType: corev1.ServiceTypeNodePort,
+			nodePort:    30001,
+			expAddr:     "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			instance := newTestInstance()
+			sts := newTestStatefulSet(instance, "test")
+			pod := newTestPod(instance, "test", 0, metav1.Now())
+			pod.Spec.NodeName = test.nodeName
+			service := resources.NewPodService(instance, pod.Name)
+			service.Spec.Type = test.serviceType
+			service.Spec.Ports[0].NodePort = test.nodePort
+			service.Status.LoadBalancer.Ingress = test.ingress
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+				Status: corev1.NodeStatus{Addresses: []corev1.NodeAddress{
+					{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+					{Type: corev1.NodeExternalIP, Address: "5.6.7.8"},
+				}},
+			}
+			h, _, _ := newTestHandler(instance, newFakeKVRocks(), []k8sApiClient.Object{sts, pod, service, node}...)
+
+			addr, err := h.GetExposedAddr(pod.Name)
+			assert.NoError(err)
+			assert.Equal(test.expAddr, addr)
+		})
+	}
+}
+
+func TestEnsureAnnounce(t *testing.T) {
+	tests := []struct {
+		name        string
+		live        map[string]string
+		addr        string
+		expCommands []string
+	}{
+		{
+			name: "The external address should be announced and persisted.",
+			addr: "1.2.3.4:30001",
+			expCommands: []string{
+				"CONFIG SET 10.0.0.1 slave-announce-ip 1.2.3.4",
+				"CONFIG SET 10.0.0.1 slave-announce-port 30001",
+				"CONFIG REWRITE 10.0.0.1",
+			},
+		}, {
+			name: "The announced address should not be set again.",
+			live: map[string]string{kvrocks.AnnounceIP: "1.2.3.4", kvrocks.AnnouncePort: "30001"},
+			addr: "1.2.3.4:30001",
+		}, {
+			name: "The announced address should be reset if the node is not exposed.",
+			live: map[string]string{kvrocks.AnnounceIP: "1.2.3.4", kvrocks.AnnouncePort: "30001"},
+			addr: "",
+			expCommands: []string{
+				"CONFIG SET 10.0.0.1 slave-announce-ip ",
+				"CONFIG SET 10.0.0.1 slave-announce-port 0",
+				"CONFIG REWRITE 10.0.0.1",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			kvClient := newFakeKVRocks()
+			kvClient.configs["10.0.0.1/"+kvrocks.AnnouncePort] = "0"
+			for key, value := range test.live {
+				kvClient.configs["10.0.0.1/"+key] = value
+			}
+			h, _, _ := newTestHandler(newTestInstance(), kvClient)
+
+			assert.NoError(h.EnsureAnnounce(&kvrocks.Node{IP: "10.0.0.1"}, test.addr))
+			assert.Equal(test.expCommands, kvClient.commands)
+		})
+	}
+}
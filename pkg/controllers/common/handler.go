@@ -1,24 +1,31 @@
 package common
 
 import (
+	"k8s.io/client-go/tools/record"
+
 	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
 	"github.com/RocksLabs/kvrocks-operator/pkg/client/k8s"
 	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/workload"
 )
 
 type CommandHandler struct {
 	instance *kvrocksv1alpha1.KVRocks
 	k8s      *k8s.Client
 	kvrocks  kvrocks.Client
+	workload workload.Backend
 	password string
+	recorder record.EventRecorder
 }
 
-func NewCommandHandler(instance *kvrocksv1alpha1.KVRocks, k8s *k8s.Client, kvrocks kvrocks.Client, password string) *CommandHandler {
+func NewCommandHandler(instance *kvrocksv1alpha1.KVRocks, k8s *k8s.Client, kvrocks kvrocks.Client, password string, recorder record.EventRecorder) *CommandHandler {
 	return &CommandHandler{
 		instance: instance,
 		k8s:      k8s,
 		kvrocks:  kvrocks,
+		workload: workload.NewBackend(k8s, instance),
 		password: password,
+		recorder: recorder,
 	}
 }
 
@@ -0,0 +1,185 @@
+package common
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	kruise "github.com/openkruise/kruise-api/apps/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sApiClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/k8s"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+)
+
+// fakeKVRocks answers the commands used by the handlers from its maps, the other commands panic
+type fakeKVRocks struct {
+	kvrocks.Client
+	offsets     map[string]int
+	replication map[string]*kvrocks.ReplicationInfo
+	configs     map[string]string
+	usedPercent map[string]int
+	down        map[string]bool
+	readOnly    map[string]bool
+	commands    []string
+}
+
+func newFakeKVRocks() *fakeKVRocks {
+	return &fakeKVRocks{
+		offsets:     map[string]int{},
+		replication: map[string]*kvrocks.ReplicationInfo{},
+		configs:     map[string]string{},
+		usedPercent: map[string]int{},
+		down:        map[string]bool{},
+		readOnly:    map[string]bool{},
+	}
+}
+
+func (c *fakeKVRocks) Logger() logr.Logger {
+	return ctrl.Log.WithName("common-test")
+}
+
+func (c *fakeKVRocks) Ping(ip string, password string) bool {
+	return !c.down[ip]
+}
+
+func (c *fakeKVRocks) GetOffset(ip string, password string) (int, error) {
+	if c.down[ip] {
+		return 0, fmt.Errorf("%s is down", ip)
+	}
+	return c.offsets[ip], nil
+}
+
+func (c *fakeKVRocks) GetReplicationInfo(ip string, password string) (*kvrocks.ReplicationInfo, error) {
+	if c.down[ip] {
+		return nil, fmt.Errorf("%s is down", ip)
+	}
+	info, ok := c.replication[ip]
+	if !ok {
+		return nil, fmt.Errorf("no replication of %s", ip)
+	}
+	return info, nil
+}
+
+func (c *fakeKVRocks) GetConfig(ip string, password string, key string) (*string, error) {
+	if c.down[ip] {
+		return nil, fmt.Errorf("%s is down", ip)
+	}
+	value := c.configs[ip+"/"+key]
+	return &value, nil
+}
+
+func (c *fakeKVRocks) GetDiskUsage(ip string, password string) (*kvrocks.DiskUsage, error) {
+	if c.down[ip] {
+		return nil, fmt.Errorf("%s is down", ip)
+	}
+	return &kvrocks.DiskUsage{UsedPercent: c.usedPercent[ip]}, nil
+}
+
+func (c *fakeKVRocks) SetConfig(ip string, password string, key string, value string) error {
+	c.configs[ip+"/"+key] = value
+	c.commands = append(c.commands, fmt.Sprintf("CONFIG SET %s %s %s", ip, key, value))
+	return nil
+}
+
+func (c *fakeKVRocks) RewriteConfig(ip string, password string) error {
+	c.commands = append(c.commands, "CONFIG REWRITE "+ip)
+	if c.readOnly[ip] {
+		return fmt.Errorf("%s runs with a read-only config file", ip)
+	}
+	return nil
+}
+
+func (c *fakeKVRocks) ChangePassword(ip string, password string, newPassword string) error {
+	c.commands = append(c.commands, "CHANGE PASSWORD "+ip)
+	return nil
+}
+
+func newTestScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+	_ = storagev1.AddToScheme(scheme)
+	_ = kruise.AddToScheme(scheme)
+	_ = kvrocksv1alpha1.AddToScheme(scheme)
+	return scheme
+}
+
+func newTestInstance() *kvrocksv1alpha1.KVRocks {
+	return &kvrocksv1alpha1.KVRocks{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "KVRocks",
+			APIVersion: kvrocksv1alpha1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "unit-test",
+		},
+		Spec: kvrocksv1alpha1.KVRocksSpec{
+			Type:     kvrocksv1alpha1.StandardType,
+			Replicas: 3,
+			Password: "password",
+		},
+		Status: kvrocksv1alpha1.KVRocksStatus{
+			WorkloadBackend: kvrocksv1alpha1.StatefulSetWorkloadBackend,
+		},
+	}
+}
+
+// newTestHandler returns a handler of the instance, which is created with the objects in the fake client
+func newTestHandler(instance *kvrocksv1alpha1.KVRocks, kvClient kvrocks.Client, objs ...k8sApiClient.Object) (*CommandHandler, k8sApiClient.Client, *record.FakeRecorder) {
+	fakeClient := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(append(objs, instance)...).Build()
+	_ = fakeClient.Get(context.TODO(), k8sApiClient.ObjectKeyFromObject(instance), instance)
+	recorder := record.NewFakeRecorder(100)
+	c := k8s.NewK8sClient(fakeClient, ctrl.Log.WithName("common-test"))
+	return NewCommandHandler(instance, c, kvClient, instance.Spec.Password, recorder), fakeClient, recorder
+}
+
+// newTestStatefulSet returns a native statefulSet selecting the pods labeled by its name
+func newTestStatefulSet(instance *kvrocksv1alpha1.KVRocks, name string) *appsv1.StatefulSet {
+	labels := map[string]string{"kvrocks/statefulset": name}
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: instance.Namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &instance.Spec.Replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+		},
+	}
+}
+
+func newTestPod(instance *kvrocksv1alpha1.KVRocks, sts string, index int, created metav1.Time) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              fmt.Sprintf("%s-%d", sts, index),
+			Namespace:         instance.Namespace,
+			Labels:            map[string]string{"kvrocks/statefulset": sts},
+			CreationTimestamp: created,
+		},
+	}
+}
+
+// drainEvents returns the events recorded so far
+func drainEvents(recorder *record.FakeRecorder) []string {
+	var events []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package common
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/k8s"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+// OperationHistory buffers the operations sent for a kvrocks and appends them to its history configMap
+type OperationHistory struct {
+	lock       sync.Mutex
+	k8s        *k8s.Client
+	instance   *kvrocksv1alpha1.KVRocks
+	operations []kvrocks.Operation
+}
+
+func NewOperationHistory(k8s *k8s.Client, instance *kvrocksv1alpha1.KVRocks) *OperationHistory {
+	return &OperationHistory{
+		k8s:      k8s,
+		instance: instance,
+	}
+}
+
+// Record buffers the operation, it is used as the recorder of the kvrocks and controller clients
+func (h *OperationHistory) Record(op kvrocks.Operation) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.operations = append(h.operations, op)
+}
+
+// Flush appends the buffered operations to the history, the oldest ones are dropped beyond the limit
+func (h *OperationHistory) Flush() error {
+	h.lock.Lock()
+	operations := h.operations
+	h.operations = nil
+	h.lock.Unlock()
+	if len(operations) == 0 {
+		return nil
+	}
+	create := false
+	cm, err := h.k8s.GetConfigMap(types.NamespacedName{
+		Namespace: h.instance.Namespace,
+		Name:      resources.GetHistoryConfigMapName(h.instance.Name),
+	})
+	if errors.IsNotFound(err) {
+		cm, err, create = resources.NewHistoryConfigMap(h.instance), nil, true
+	}
+	if err != nil {
+		return err
+	}
+	history := ParseHistory(cm.Data[resources.HistoryKey])
+	for _, op := range operations {
+		history = appendOperation(history, op)
+	}
+	if len(history) > resources.HistoryLimit {
+		history = history[len(history)-resources.HistoryLimit:]
+	}
+	lines := make([]string, 0, len(history))
+	for _, op := range history {
+		line, err := json.Marshal(op)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, string(line))
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[resources.HistoryKey] = strings.Join(lines, "\n")
+	if create {
+		return h.k8s.CreateIfNotExistsConfigMap(cm)
+	}
+	return h.k8s.UpdateConfigMap(cm)
+}
+
+// ParseHistory returns the operations in the history, the lines which can not be parsed are skipped
+func ParseHistory(data string) []kvrocks.Operation {
+	var history []kvrocks.Operation
+	for _, line := range strings.Split(data, "\n") {
+		var op kvrocks.Operation
+		if line == "" || json.Unmarshal([]byte(line), &op) != nil {
+			continue
+		}
+		history = append(history, op)
+	}
+	return history
+}
+
+// appendOperation merges the operation into the last one if they are the same, so that a repeated command does not
+// flood the history and the order of the different commands is kept
+func appendOperation(history []kvrocks.Operation, op kvrocks.Operation) []kvrocks.Operation {
+	if len(history) == 0 || !history[len(history)-1].SameAs(&op) {
+		return append(history, op)
+	}
+	last := &history[len(history)-1]
+	if last.Count == 0 {
+		last.Count = 1
+		first := last.Time
+		last.FirstTime = &first
+	}
+	last.Count++
+	last.Time = op.Time
+	return history
+}
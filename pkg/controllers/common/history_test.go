@@ -0,0 +1,116 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+func TestAppendOperation(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newOperation := func(target string, minute int) kvrocks.Operation {
+		return kvrocks.Operation{
+			Time:      start.Add(time.Duration(minute) * time.Minute),
+			Component: kvrocks.ComponentSentinel,
+			Target:    target,
+			Command:   "SENTINEL RESET",
+			Args:      []string{"test"},
+		}
+	}
+	merged := func(target string, first, last, count int) kvrocks.Operation {
+		op := newOperation(target, last)
+		firstTime := start.Add(time.Duration(first) * time.Minute)
+		op.FirstTime = &firstTime
+		op.Count = count
+		return op
+	}
+
+	tests := []struct {
+		name       string
+		history    []kvrocks.Operation
+		op         kvrocks.Operation
+		expHistory []kvrocks.Operation
+	}{
+		{
+			name:       "The operation should be appended to an empty history.",
+			op:         newOperation("10.0.0.1", 0),
+			expHistory: []kvrocks.Operation{newOperation("10.0.0.1", 0)},
+		}, {
+			name:       "The same operation as the last one should be merged with the first and last times.",
+			history:    []kvrocks.Operation{newOperation("10.0.0.1", 0)},
+			op:         newOperation("10.0.0.1", 1),
+			expHistory: []kvrocks.Operation{merged("10.0.0.1", 0, 1, 2)},
+		}, {
+			name:       "A merged operation should keep its first time.",
+			history:    []kvrocks.Operation{merged("10.0.0.1", 0, 1, 2)},
+			op:         newOperation("10.0.0.1", 2),
+			expHistory: []kvrocks.Operation{merged("10.0.0.1", 0, 2, 3)},
+		}, {
+			name:    "The same operation as an earlier one should be appended to keep the order.",
+			history: []kvrocks.Operation{newOperation("10.0.0.1", 0), newOperation("10.0.0.2", 1)},
+			op:      newOperation("10.0.0.1", 2),
+			expHistory: []kvrocks.Operation{
+				newOperation("10.0.0.1", 0), newOperation("10.0.0.2", 1), newOperation("10.0.0.1", 2),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expHistory, appendOperation(test.history, test.op))
+		})
+	}
+}
+
+func TestOperationHistoryFlush(t *testing.T) {
+	assert := assert.New(t)
+
+	limit := resources.HistoryLimit
+	resources.HistoryLimit = 3
+	defer func() {
+		resources.HistoryLimit = limit
+	}()
+	instance := newTestInstance()
+	h, fakeClient, _ := newTestHandler(instance, newFakeKVRocks())
+	history := NewOperationHistory(h.k8s, instance)
+	kvClient := kvrocks.NewRecordingClient(newFakeKVRocks(), history.Record)
+	key := types.NamespacedName{Namespace: instance.Namespace, Name: resources.GetHistoryConfigMapName(instance.Name)}
+
+	// nothing is written without operations
+	assert.NoError(history.Flush())
+	assert.Error(fakeClient.Get(context.TODO(), key, &corev1.ConfigMap{}))
+
+	assert.NoError(kvClient.SetConfig("10.0.0.1", "", "requirepass", "secret"))
+	assert.NoError(kvClient.RewriteConfig("10.0.0.1", ""))
+	assert.NoError(kvClient.RewriteConfig("10.0.0.1", ""))
+	assert.NoError(history.Flush())
+	var cm corev1.ConfigMap
+	assert.NoError(fakeClient.Get(context.TODO(), key, &cm))
+	operations := ParseHistory(cm.Data[resources.HistoryKey])
+	if assert.Len(operations, 2) {
+		assert.Equal([]string{"requirepass", "******"}, operations[0].Args)
+		assert.Equal("CONFIG REWRITE", operations[1].Command)
+		assert.Equal(2, operations[1].Count)
+	}
+
+	// the oldest operations are dropped beyond the limit
+	for i := 0; i < 3; i++ {
+		assert.NoError(kvClient.SetConfig("10.0.0.1", "", "maxclients", fmt.Sprint(100+i)))
+	}
+	assert.NoError(history.Flush())
+	assert.NoError(fakeClient.Get(context.TODO(), key, &cm))
+	operations = ParseHistory(cm.Data[resources.HistoryKey])
+	if assert.Len(operations, 3) {
+		for i, op := range operations {
+			assert.Equal([]string{"maxclients", fmt.Sprint(100 + i)}, op.Args)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package common
 
 import (
+	"errors"
+
 	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
 	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
 )
@@ -16,6 +18,20 @@ func (h *CommandHandler) ResizeStatefulSet(stsNodes []*kvrocks.Node, index ...in
 		if len(index) > 0 {
 			sts = resources.NewClusterStatefulSet(h.instance, index[0])
 		}
+		if !h.workload.SupportReserveOrdinals() {
+			// only the pods of the largest ordinals can be removed, the master must be moved to a lower ordinal first
+			for _, node := range stsNodes {
+				if node.Role == kvrocks.RoleMaster && node.PodIndex >= int(h.instance.Spec.Replicas) {
+					return true, errors.New("master must be moved to a lower ordinal before scaling down")
+				}
+			}
+			for i, node := range stsNodes {
+				if node.PodIndex >= int(h.instance.Spec.Replicas) {
+					stsNodes[i] = nil
+				}
+			}
+			return true, h.workload.CreateOrUpdateStatefulSet(sts)
+		}
 		reserve := make([]int, 0)
 		masterID := 0
 		for i := len(stsNodes) - 1; i >= 0; i-- {
@@ -40,7 +56,7 @@ func (h *CommandHandler) ResizeStatefulSet(stsNodes []*kvrocks.Node, index ...in
 			}
 			sts.Spec.ReserveOrdinals = append(sts.Spec.ReserveOrdinals, id)
 		}
-		if err := h.k8s.CreateOrUpdateStatefulSet(sts); err != nil {
+		if err := h.workload.CreateOrUpdateStatefulSet(sts); err != nil {
 			return true, err
 		}
 	}
@@ -0,0 +1,42 @@
+package common
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+// EnsureMonitoring creates the PodMonitor and PrometheusRule enabled by spec.monitoring and deletes the disabled
+// ones, nothing is done for the kinds whose CRDs are not installed
+func (h *CommandHandler) EnsureMonitoring() error {
+	monitoring := h.instance.Spec.Monitoring
+	podMonitor := monitoring != nil && monitoring.PodMonitor && resources.IsExporterEnabled(h.instance)
+	if err := h.ensureMonitoringObject(resources.PodMonitorGVK, podMonitor, resources.NewPodMonitor); err != nil {
+		return err
+	}
+	prometheusRule := monitoring != nil && monitoring.PrometheusRule
+	return h.ensureMonitoringObject(resources.PrometheusRuleGVK, prometheusRule, resources.NewPrometheusRule)
+}
+
+func (h *CommandHandler) ensureMonitoringObject(gvk schema.GroupVersionKind, enabled bool, newObject func(*kvrocksv1alpha1.KVRocks) *unstructured.Unstructured) error {
+	installed, err := h.k8s.IsKindInstalled(gvk)
+	if err != nil {
+		return err
+	}
+	if !installed {
+		if enabled {
+			h.kvrocks.Logger().Info("crd is not installed, skip", "kind", gvk.Kind)
+		}
+		return nil
+	}
+	if !enabled {
+		return h.k8s.DeleteUnstructuredIfExists(gvk, types.NamespacedName{
+			Namespace: h.instance.Namespace,
+			Name:      h.instance.Name,
+		})
+	}
+	return h.k8s.CreateOrUpdateUnstructured(newObject(h.instance))
+}
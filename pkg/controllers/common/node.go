@@ -0,0 +1,90 @@
+package common
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+// NodeLostTimeout is how long a node can be NotReady before its kvrocks pods are replaced on other nodes
+var NodeLostTimeout = 5 * time.Minute
+
+// IsNodeDraining checks if the pod is going to be evicted from the node, the node is cordoned, annotated to be drained,
+// or tainted NoExecute by a taint the pod does not tolerate. Any NoExecute taint counts if pod is nil
+func IsNodeDraining(node *corev1.Node, pod *corev1.Pod) bool {
+	if node.Spec.Unschedulable || node.Annotations[resources.NodeDrain] == "true" {
+		return true
+	}
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectNoExecute && (pod == nil || !toleratesTaint(pod, taint)) {
+			return true
+		}
+	}
+	return false
+}
+
+func toleratesTaint(pod *corev1.Pod, taint *corev1.Taint) bool {
+	for i := range pod.Spec.Tolerations {
+		if pod.Spec.Tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNodeLost checks if the node is NotReady longer than NodeLostTimeout, the pods on it would never be ready again
+func IsNodeLost(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status != corev1.ConditionTrue && time.Since(condition.LastTransitionTime.Time) > NodeLostTimeout
+		}
+	}
+	return false
+}
+
+// ListDisruptedPods returns the pods of the statefulSet on the draining nodes, and the pods on the lost nodes.
+// The pods on the removed nodes are lost
+func (h *CommandHandler) ListDisruptedPods(key types.NamespacedName) ([]*corev1.Pod, []*corev1.Pod, error) {
+	pods, err := h.workload.ListStatefulSetPods(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	nodes := map[string]*corev1.Node{}
+	var draining, lost []*corev1.Pod
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		node, ok := nodes[pod.Spec.NodeName]
+		if !ok {
+			node, err = h.k8s.GetNode(pod.Spec.NodeName)
+			if err != nil && !errors.IsNotFound(err) {
+				return nil, nil, err
+			}
+			nodes[pod.Spec.NodeName] = node
+		}
+		if node == nil || IsNodeLost(node) {
+			lost = append(lost, pod)
+		} else if IsNodeDraining(node, pod) {
+			draining = append(draining, pod)
+		}
+	}
+	return draining, lost, nil
+}
+
+// ReplacePod force deletes the pod on the lost node, so that the statefulSet recreates it on another node.
+// The pvc is deleted by the failover policy
+func (h *CommandHandler) ReplacePod(pod *corev1.Pod) error {
+	h.kvrocks.Logger().Info("node is lost, replace pod", "pod", pod.Name, "node", pod.Spec.NodeName)
+	if err := h.DeleteFailoverPVC(pod.Name); err != nil {
+		return err
+	}
+	h.PodEventf(pod.Name, corev1.EventTypeWarning, ReasonPodReplaced, "node %s is lost, the pod is replaced", pod.Spec.NodeName)
+	return h.k8s.DeletePodImmediately(pod.Name, pod.Namespace)
+}
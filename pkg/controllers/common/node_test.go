@@ -0,0 +1,73 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+func TestIsNodeDraining(t *testing.T) {
+	noExecute := corev1.Taint{Key: "maintenance", Effect: corev1.TaintEffectNoExecute}
+
+	tests := []struct {
+		name        string
+		node        corev1.Node
+		tolerations []corev1.Toleration
+		nilPod      bool
+		expDraining bool
+	}{
+		{
+			name:        "A schedulable node without taints should not be draining.",
+			expDraining: false,
+		}, {
+			name:        "A cordoned node should be draining.",
+			node:        corev1.Node{Spec: corev1.NodeSpec{Unschedulable: true}},
+			expDraining: true,
+		}, {
+			name: "A node annotated to be drained should be draining.",
+			node: corev1.Node{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{resources.NodeDrain: "true"},
+			}},
+			expDraining: true,
+		}, {
+			name:        "A NoExecute taint not tolerated by the pod should be draining.",
+			node:        corev1.Node{Spec: corev1.NodeSpec{Taints: []corev1.Taint{noExecute}}},
+			expDraining: true,
+		}, {
+			name: "A NoExecute taint tolerated by the pod should not be draining.",
+			node: corev1.Node{Spec: corev1.NodeSpec{Taints: []corev1.Taint{noExecute}}},
+			tolerations: []corev1.Toleration{{
+				Key:      "maintenance",
+				Operator: corev1.TolerationOpExists,
+				Effect:   corev1.TaintEffectNoExecute,
+			}},
+			expDraining: false,
+		}, {
+			name: "A NoSchedule taint should not be draining.",
+			node: corev1.Node{Spec: corev1.NodeSpec{Taints: []corev1.Taint{{
+				Key:    "ToBeDeletedByClusterAutoscaler",
+				Effect: corev1.TaintEffectNoSchedule,
+			}}}},
+			expDraining: false,
+		}, {
+			name:        "Any NoExecute taint should be draining without the pod.",
+			node:        corev1.Node{Spec: corev1.NodeSpec{Taints: []corev1.Taint{noExecute}}},
+			nilPod:      true,
+			expDraining: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pod := &corev1.Pod{Spec: corev1.PodSpec{Tolerations: test.tolerations}}
+			if test.nilPod {
+				pod = nil
+			}
+			assert.Equal(t, test.expDraining, IsNodeDraining(&test.node, pod))
+		})
+	}
+}
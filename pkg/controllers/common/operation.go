@@ -0,0 +1,191 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+// steps of the operations
+const (
+	OperationStepWaitPromotion = "WaitPromotion"
+	OperationStepWaitReady     = "WaitReady"
+	OperationStepWaitSync      = "WaitSync"
+	// the steps of Resync in cluster mode, the slave is removed from the controller, wiped and added back in order
+	OperationStepRemoveNode = "RemoveNode"
+	OperationStepWipe       = "Wipe"
+	OperationStepAddNode    = "AddNode"
+)
+
+// OperationTimeout is how long an operation can run before it is failed
+var OperationTimeout = 30 * time.Minute
+
+// OperationError fails the operation instead of retrying it, such as a pre-check which is not passed
+type OperationError struct {
+	message string
+}
+
+func (e *OperationError) Error() string {
+	return e.message
+}
+
+func NewOperationError(format string, args ...interface{}) error {
+	return &OperationError{message: fmt.Sprintf(format, args...)}
+}
+
+// NextOperation returns the oldest unfinished operation on the kvrocks, the operations are run one at a time.
+// Under maintenance, a pending operation which fails over or deletes a pvc waits until the maintenance ends
+func (h *CommandHandler) NextOperation() (*kvrocksv1alpha1.KVRocksOperation, error) {
+	operations, err := h.k8s.ListKVRocksOperations(h.instance.Namespace, h.instance.Name)
+	if err != nil {
+		return nil, err
+	}
+	for i := range operations {
+		if operations[i].IsFinished() {
+			continue
+		}
+		if operations[i].Status.Phase != kvrocksv1alpha1.OperationRunning && resources.IsUnderMaintenance(h.instance) {
+			switch operations[i].Spec.Type {
+			case kvrocksv1alpha1.FailoverOperation, kvrocksv1alpha1.ReplaceNodeOperation, kvrocksv1alpha1.ResyncOperation:
+				h.kvrocks.Logger().Info("operation waits for the maintenance to end", "operation", operations[i].Name)
+				return nil, nil
+			}
+		}
+		return &operations[i], nil
+	}
+	return nil, nil
+}
+
+// SetOperationStep moves the operation to the step, a pending operation is started
+func (h *CommandHandler) SetOperationStep(op *kvrocksv1alpha1.KVRocksOperation, step, messageFmt string, args ...interface{}) error {
+	now := metav1.Now()
+	if op.Status.Phase != kvrocksv1alpha1.OperationRunning {
+		op.Status.Phase = kvrocksv1alpha1.OperationRunning
+		op.Status.StartTime = &now
+		h.Eventf(corev1.EventTypeNormal, ReasonOperationStarted, "%s operation %s is started", op.Spec.Type, op.Name)
+	}
+	if op.Status.Step != step {
+		op.Status.StepTime = &now
+	}
+	op.Status.Step = step
+	op.Status.Message = fmt.Sprintf(messageFmt, args...)
+	h.kvrocks.Logger().Info("operation step", "operation", op.Name, "type", op.Spec.Type, "step", step, "message", op.Status.Message)
+	return h.k8s.UpdateKVRocksOperation(op)
+}
+
+// UpdateOperation records the result of running the operation. An OperationError or a timeout fails the operation,
+// the other errors are returned so that the step is retried. It returns true if the operation is still running
+func (h *CommandHandler) UpdateOperation(op *kvrocksv1alpha1.KVRocksOperation, done bool, err error) (bool, error) {
+	var opErr *OperationError
+	if errors.As(err, &opErr) {
+		return false, h.finishOperation(op, err)
+	}
+	if done {
+		return false, h.finishOperation(op, nil)
+	}
+	if op.Status.StartTime != nil && time.Since(op.Status.StartTime.Time) > OperationTimeout {
+		if err == nil {
+			err = fmt.Errorf("timed out at step %s", op.Status.Step)
+		}
+		return false, h.finishOperation(op, err)
+	}
+	return err == nil, err
+}
+
+func (h *CommandHandler) finishOperation(op *kvrocksv1alpha1.KVRocksOperation, err error) error {
+	now := metav1.Now()
+	if op.Status.StartTime == nil {
+		op.Status.StartTime = &now
+	}
+	op.Status.CompletionTime = &now
+	if err != nil {
+		op.Status.Phase = kvrocksv1alpha1.OperationFailed
+		op.Status.Message = err.Error()
+		h.Eventf(corev1.EventTypeWarning, ReasonOperationFailed, "%s operation %s failed: %v", op.Spec.Type, op.Name, err)
+		h.recorder.Eventf(op, corev1.EventTypeWarning, ReasonOperationFailed, "%v", err)
+	} else {
+		op.Status.Phase = kvrocksv1alpha1.OperationSucceeded
+		h.Eventf(corev1.EventTypeNormal, ReasonOperationSucceeded, "%s operation %s succeeded", op.Spec.Type, op.Name)
+		h.recorder.Eventf(op, corev1.EventTypeNormal, ReasonOperationSucceeded, "%s", op.Status.Message)
+	}
+	h.kvrocks.Logger().Info("operation finished", "operation", op.Name, "type", op.Spec.Type, "phase", op.Status.Phase, "message", op.Status.Message)
+	return h.k8s.UpdateKVRocksOperation(op)
+}
+
+// CheckReplicaInSync checks the offset of the slave is within spec.maxReplicationLag of its master
+func (h *CommandHandler) CheckReplicaInSync(master, slave *kvrocks.Node) error {
+	masterOffset, err := h.kvrocks.GetOffset(master.IP, h.password)
+	if err != nil {
+		return err
+	}
+	slaveOffset, err := h.kvrocks.GetOffset(slave.IP, h.password)
+	if err != nil {
+		return err
+	}
+	if lag := int64(masterOffset - slaveOffset); lag > resources.GetMaxReplicationLag(h.instance) {
+		return NewOperationError("replica %s lags %d behind its master %s", slave.IP, lag, master.IP)
+	}
+	return nil
+}
+
+// DeleteOperationPod deletes the pod so that the statefulSet recreates it, the pvc is deleted first if wipe is set
+func (h *CommandHandler) DeleteOperationPod(op *kvrocksv1alpha1.KVRocksOperation, podName string, wipe bool) error {
+	if wipe {
+		if err := h.k8s.DeletePVCByPod(podName, h.instance.Namespace); err != nil {
+			return err
+		}
+		h.PodEventf(podName, corev1.EventTypeNormal, ReasonPVCDeleted, "pvc data-%s is deleted by %s operation %s", podName, op.Spec.Type, op.Name)
+	}
+	if err := h.k8s.DeletePodImmediately(podName, h.instance.Namespace); err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	h.PodEventf(podName, corev1.EventTypeNormal, ReasonPodReplaced, "pod is deleted by %s operation %s", op.Spec.Type, op.Name)
+	return nil
+}
+
+// IsPodRecreated checks if the pod is recreated and ready since the current step of the operation started
+func (h *CommandHandler) IsPodRecreated(op *kvrocksv1alpha1.KVRocksOperation, podName string) (bool, error) {
+	pod, err := h.k8s.GetPod(types.NamespacedName{
+		Namespace: h.instance.Namespace,
+		Name:      podName,
+	})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if op.Status.StepTime != nil && pod.CreationTimestamp.Before(op.Status.StepTime) {
+		return false, nil
+	}
+	return resources.IsPodReady(pod), nil
+}
+
+// IsReplicaSynced checks if the slave is linked to its master after a full sync, and it is within
+// spec.maxReplicationLag of the master
+func (h *CommandHandler) IsReplicaSynced(master, slave *kvrocks.Node) (bool, error) {
+	info, err := h.kvrocks.GetReplicationInfo(slave.IP, h.password)
+	if err != nil {
+		return false, err
+	}
+	if info.Role != kvrocks.RoleSlaver || info.LinkStatus != "up" || info.SyncInProgress {
+		return false, nil
+	}
+	if err = h.CheckReplicaInSync(master, slave); err != nil {
+		var opErr *OperationError
+		if errors.As(err, &opErr) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
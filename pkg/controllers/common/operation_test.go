@@ -0,0 +1,211 @@
+package common
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sApiClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+func newTestOperation(instance *kvrocksv1alpha1.KVRocks, name string, opType kvrocksv1alpha1.KVRocksOperationType, created time.Time) *kvrocksv1alpha1.KVRocksOperation {
+	return &kvrocksv1alpha1.KVRocksOperation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         instance.Namespace,
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: kvrocksv1alpha1.KVRocksOperationSpec{
+			Instance: instance.Name,
+			Type:     opType,
+			Pod:      "test-1",
+		},
+	}
+}
+
+func TestNextOperation(t *testing.T) {
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	withPhase := func(op *kvrocksv1alpha1.KVRocksOperation, phase kvrocksv1alpha1.KVRocksOperationPhase) *kvrocksv1alpha1.KVRocksOperation {
+		op.Status.Phase = phase
+		return op
+	}
+	withInstance := func(op *kvrocksv1alpha1.KVRocksOperation, instance string) *kvrocksv1alpha1.KVRocksOperation {
+		op.Spec.Instance = instance
+		return op
+	}
+
+	tests := []struct {
+		name        string
+		maintenance bool
+		operations  func(instance *kvrocksv1alpha1.KVRocks) []k8sApiClient.Object
+		expName     string
+	}{
+		{
+			name: "No operation should be returned without operations.",
+			operations: func(instance *kvrocksv1alpha1.KVRocks) []k8sApiClient.Object {
+				return nil
+			},
+		}, {
+			name: "The oldest operation should be returned first.",
+			operations: func(instance *kvrocksv1alpha1.KVRocks) []k8sApiClient.Object {
+				return []k8sApiClient.Object{
+					newTestOperation(instance, "second", kvrocksv1alpha1.RestartPodOperation, start.Add(time.Minute)),
+					newTestOperation(instance, "first", kvrocksv1alpha1.SwitchoverOperation, start),
+				}
+			},
+			expName: "first",
+		}, {
+			name: "The finished operations should be skipped.",
+			operations: func(instance *kvrocksv1alpha1.KVRocks) []k8sApiClient.Object {
+				return []k8sApiClient.Object{
+					withPhase(newTestOperation(instance, "succeeded", kvrocksv1alpha1.SwitchoverOperation, start), kvrocksv1alpha1.OperationSucceeded),
+					withPhase(newTestOperation(instance, "failed", kvrocksv1alpha1.SwitchoverOperation, start.Add(time.Minute)), kvrocksv1alpha1.OperationFailed),
+					newTestOperation(instance, "pending", kvrocksv1alpha1.ResyncOperation, start.Add(2*time.Minute)),
+				}
+			},
+			expName: "pending",
+		}, {
+			name:        "A pending failover should wait under maintenance.",
+			maintenance: true,
+			operations: func(instance *kvrocksv1alpha1.KVRocks) []k8sApiClient.Object {
+				return []k8sApiClient.Object{
+					newTestOperation(instance, "failover", kvrocksv1alpha1.FailoverOperation, start),
+					newTestOperation(instance, "restart", kvrocksv1alpha1.RestartPodOperation, start.Add(time.Minute)),
+				}
+			},
+		}, {
+			name:        "A pending replacement should wait under maintenance.",
+			maintenance: true,
+			operations: func(instance *kvrocksv1alpha1.KVRocks) []k8sApiClient.Object {
+				return []k8sApiClient.Object{newTestOperation(instance, "replace", kvrocksv1alpha1.ReplaceNodeOperation, start)}
+			},
+		}, {
+			name:        "A pending resync should wait under maintenance.",
+			maintenance: true,
+			operations: func(instance *kvrocksv1alpha1.KVRocks) []k8sApiClient.Object {
+				return []k8sApiClient.Object{newTestOperation(instance, "resync", kvrocksv1alpha1.ResyncOperation, start)}
+			},
+		}, {
+			name:        "A running resync should be completed under maintenance.",
+			maintenance: true,
+			operations: func(instance *kvrocksv1alpha1.KVRocks) []k8sApiClient.Object {
+				return []k8sApiClient.Object{
+					withPhase(newTestOperation(instance, "resync", kvrocksv1alpha1.ResyncOperation, start), kvrocksv1alpha1.OperationRunning),
+				}
+			},
+			expName: "resync",
+		}, {
+			name:        "A pending switchover should not wait under maintenance.",
+			maintenance: true,
+			operations: func(instance *kvrocksv1alpha1.KVRocks) []k8sApiClient.Object {
+				return []k8sApiClient.Object{newTestOperation(instance, "switchover", kvrocksv1alpha1.SwitchoverOperation, start)}
+			},
+			expName: "switchover",
+		}, {
+			name: "The operations on another kvrocks should be skipped.",
+			operations: func(instance *kvrocksv1alpha1.KVRocks) []k8sApiClient.Object {
+				return []k8sApiClient.Object{
+					withInstance(newTestOperation(instance, "other", kvrocksv1alpha1.SwitchoverOperation, start), "other"),
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			instance := newTestInstance()
+			if test.maintenance {
+				instance.Annotations = map[string]string{resources.Maintenance: "true"}
+			}
+			h, _, _ := newTestHandler(instance, newFakeKVRocks(), test.operations(instance)...)
+			op, err := h.NextOperation()
+			assert.NoError(err)
+			if test.expName == "" {
+				assert.Nil(op)
+				return
+			}
+			if assert.NotNil(op) {
+				assert.Equal(test.expName, op.Name)
+			}
+		})
+	}
+}
+
+func TestUpdateOperation(t *testing.T) {
+	tests := []struct {
+		name       string
+		started    time.Duration
+		done       bool
+		err        error
+		expRunning bool
+		expErr     bool
+		expPhase   kvrocksv1alpha1.KVRocksOperationPhase
+		expEvents  []string
+	}{
+		{
+			name:       "An operation at an unfinished step should keep running.",
+			expRunning: true,
+			expPhase:   kvrocksv1alpha1.OperationRunning,
+		}, {
+			name:     "A done operation should succeed.",
+			done:     true,
+			expPhase: kvrocksv1alpha1.OperationSucceeded,
+			expEvents: []string{
+				"Normal OperationSucceeded Resync operation resync succeeded",
+				"Normal OperationSucceeded test-1 is resynced",
+			},
+		}, {
+			name:     "An operation error should fail the operation.",
+			err:      NewOperationError("test-1 is the master, switch over before resync"),
+			expPhase: kvrocksv1alpha1.OperationFailed,
+			expEvents: []string{
+				"Warning OperationFailed Resync operation resync failed: test-1 is the master, switch over before resync",
+				"Warning OperationFailed test-1 is the master, switch over before resync",
+			},
+		}, {
+			name:     "Another error should be returned to retry the step.",
+			err:      errors.New("connection refused"),
+			expErr:   true,
+			expPhase: kvrocksv1alpha1.OperationRunning,
+		}, {
+			name:     "An operation running longer than the timeout should fail.",
+			started:  OperationTimeout + time.Minute,
+			expPhase: kvrocksv1alpha1.OperationFailed,
+			expEvents: []string{
+				"Warning OperationFailed Resync operation resync failed: timed out at step WaitSync",
+				"Warning OperationFailed timed out at step WaitSync",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			instance := newTestInstance()
+			op := newTestOperation(instance, "resync", kvrocksv1alpha1.ResyncOperation, time.Now())
+			startTime := metav1.NewTime(time.Now().Add(-test.started))
+			op.Status = kvrocksv1alpha1.KVRocksOperationStatus{
+				Phase:     kvrocksv1alpha1.OperationRunning,
+				Step:      OperationStepWaitSync,
+				Message:   "test-1 is resynced",
+				StartTime: &startTime,
+			}
+			h, _, recorder := newTestHandler(instance, newFakeKVRocks(), op)
+
+			running, err := h.UpdateOperation(op, test.done, test.err)
+			assert.Equal(test.expRunning, running)
+			assert.Equal(test.expErr, err != nil)
+			assert.Equal(test.expPhase, op.Status.Phase)
+			assert.Equal(test.expPhase != kvrocksv1alpha1.OperationRunning, op.Status.CompletionTime != nil)
+			assert.Equal(test.expEvents, drainEvents(recorder))
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package common
+
+import (
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+// UpdatePauseConditions sets the Paused and Maintenance conditions by the annotations, the conditions are only added
+// once the annotations are set. It returns true if the conditions are changed
+func UpdatePauseConditions(instance *kvrocksv1alpha1.KVRocks) bool {
+	paused := updateAnnotationCondition(instance, kvrocksv1alpha1.ConditionPaused, resources.Paused, resources.IsPaused(instance))
+	maintenance := updateAnnotationCondition(instance, kvrocksv1alpha1.ConditionMaintenance, resources.Maintenance, resources.IsUnderMaintenance(instance))
+	return paused || maintenance
+}
+
+func updateAnnotationCondition(instance *kvrocksv1alpha1.KVRocks, conditionType, annotation string, enabled bool) bool {
+	if enabled {
+		return SetCondition(instance, conditionType, metav1.ConditionTrue, "Annotated", fmt.Sprintf("%s is set", annotation))
+	}
+	if meta.FindStatusCondition(instance.Status.Conditions, conditionType) == nil {
+		return false
+	}
+	return SetCondition(instance, conditionType, metav1.ConditionFalse, "NotAnnotated", fmt.Sprintf("%s is not set", annotation))
+}
+
+// ObserveStatus refreshes the replication of the nodes in status without changing anything else, it is used while the
+// reconciliation is paused. Only the kvrocks status is written, it is updated if the replication health or the
+// conditions changed by the caller change
+func (h *CommandHandler) ObserveStatus(changed bool) error {
+	if h.instance.Spec.Type == kvrocksv1alpha1.SentinelType {
+		if changed {
+			return h.k8s.UpdateKVRocks(h.instance)
+		}
+		return nil
+	}
+	changed = h.observeTopology(h.instance.Name, h.instance.Status.Nodes) || changed
+	for _, partition := range h.instance.Status.Topo {
+		changed = h.observeTopology(partition.PartitionName, partition.Topology) || changed
+	}
+	return h.UpdateReplicationHealth(changed)
+}
+
+// observeTopology refreshes the replication of the nodes of a statefulSet with their last known roles. The readiness
+// gates of the pods are left as they are until the kvrocks is resumed. It returns true if any replication is changed
+func (h *CommandHandler) observeTopology(stsName string, topology []kvrocksv1alpha1.KVRocksTopology) bool {
+	var nodes []*kvrocks.Node
+	for _, topo := range topology {
+		index, err := resources.GetPVCOrPodIndex(topo.Pod)
+		if err != nil {
+			continue
+		}
+		nodes = append(nodes, &kvrocks.Node{IP: topo.Ip, Role: topo.Role, PodIndex: index})
+	}
+	replication := h.GetReplication(stsName, nodes)
+	changed := false
+	for i := range topology {
+		index, err := resources.GetPVCOrPodIndex(topology[i].Pod)
+		if err != nil {
+			continue
+		}
+		if !reflect.DeepEqual(topology[i].Replication, replication[index]) {
+			topology[i].Replication = replication[index]
+			changed = true
+		}
+	}
+	return changed
+}
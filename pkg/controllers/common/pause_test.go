@@ -0,0 +1,119 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+func TestUpdatePauseConditions(t *testing.T) {
+	tests := []struct {
+		name           string
+		annotations    map[string]string
+		conditions     []metav1.Condition
+		expChanged     bool
+		expPaused      metav1.ConditionStatus
+		expMaintenance metav1.ConditionStatus
+	}{
+		{
+			name: "No condition should be added without the annotations.",
+		}, {
+			name:        "The paused annotation should set the Paused condition.",
+			annotations: map[string]string{resources.Paused: "true"},
+			expChanged:  true,
+			expPaused:   metav1.ConditionTrue,
+		}, {
+			name:           "The maintenance annotation should set the Maintenance condition.",
+			annotations:    map[string]string{resources.Maintenance: "true"},
+			expChanged:     true,
+			expMaintenance: metav1.ConditionTrue,
+		}, {
+			name:        "An annotation other than true should not set the condition.",
+			annotations: map[string]string{resources.Paused: "false"},
+		}, {
+			name:        "A set condition should not be changed again.",
+			annotations: map[string]string{resources.Paused: "true"},
+			conditions: []metav1.Condition{
+				{Type: kvrocksv1alpha1.ConditionPaused, Status: metav1.ConditionTrue, Reason: "Annotated", Message: resources.Paused + " is set"},
+			},
+			expPaused: metav1.ConditionTrue,
+		}, {
+			name: "A removed annotation should clear the condition.",
+			conditions: []metav1.Condition{
+				{Type: kvrocksv1alpha1.ConditionPaused, Status: metav1.ConditionTrue, Reason: "Annotated", Message: resources.Paused + " is set"},
+			},
+			expChanged: true,
+			expPaused:  metav1.ConditionFalse,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			instance := newTestInstance()
+			instance.Annotations = test.annotations
+			instance.Status.Conditions = test.conditions
+			assert.Equal(test.expChanged, UpdatePauseConditions(instance))
+			for conditionType, expStatus := range map[string]metav1.ConditionStatus{
+				kvrocksv1alpha1.ConditionPaused:      test.expPaused,
+				kvrocksv1alpha1.ConditionMaintenance: test.expMaintenance,
+			} {
+				condition := meta.FindStatusCondition(instance.Status.Conditions, conditionType)
+				if expStatus == "" {
+					assert.Nil(condition)
+					continue
+				}
+				if assert.NotNil(condition) {
+					assert.Equal(expStatus, condition.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestObserveStatus(t *testing.T) {
+	assert := assert.New(t)
+
+	instance := newTestInstance()
+	instance.Annotations = map[string]string{resources.Paused: "true"}
+	instance.Status.Nodes = []kvrocksv1alpha1.KVRocksTopology{
+		{Pod: "test-0", Role: kvrocks.RoleMaster, Ip: "10.0.0.1"},
+		{Pod: "test-1", Role: kvrocks.RoleSlaver, Ip: "10.0.0.2"},
+		{Pod: "test-2", Role: kvrocks.RoleSlaver, Ip: "10.0.0.3"},
+	}
+	kvClient := newFakeKVRocks()
+	kvClient.replication["10.0.0.1"] = &kvrocks.ReplicationInfo{Role: kvrocks.RoleMaster, Offset: 1000}
+	kvClient.replication["10.0.0.2"] = &kvrocks.ReplicationInfo{Role: kvrocks.RoleSlaver, Offset: 1000, LinkStatus: "up"}
+	kvClient.replication["10.0.0.3"] = &kvrocks.ReplicationInfo{Role: kvrocks.RoleSlaver, LinkStatus: "down"}
+	// the master is restarted while paused, the gate of the recreated pod is not set yet. test-2 is deleted
+	h, fakeClient, _ := newTestHandler(instance, kvClient,
+		newTestPod(instance, "test", 0, metav1.Now()),
+		newTestPod(instance, "test", 1, metav1.Now()),
+	)
+
+	assert.NoError(h.ObserveStatus(UpdatePauseConditions(instance)))
+	// only the status is written while paused, the gates are set once resumed
+	for _, name := range []string{"test-0", "test-1"} {
+		var pod corev1.Pod
+		assert.NoError(fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: instance.Namespace, Name: name}, &pod))
+		assert.Empty(pod.Status.Conditions, name)
+	}
+	if assert.NotNil(instance.Status.Nodes[2].Replication) {
+		assert.Equal("down", instance.Status.Nodes[2].Replication.LinkStatus)
+	}
+	condition := meta.FindStatusCondition(instance.Status.Conditions, kvrocksv1alpha1.ConditionReplicationHealthy)
+	if assert.NotNil(condition) {
+		assert.Equal("LinkDown", condition.Reason)
+	}
+	assert.True(meta.IsStatusConditionTrue(instance.Status.Conditions, kvrocksv1alpha1.ConditionPaused))
+}
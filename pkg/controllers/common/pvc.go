@@ -0,0 +1,145 @@
+package common
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+// CleanPVC deletes the pvc of a pod removed by scaling down, or labels it for later reuse if whenScaled is Retain
+func (h *CommandHandler) CleanPVC(pvc *corev1.PersistentVolumeClaim) error {
+	if resources.GetPVCRetentionPolicy(h.instance).WhenScaled == kvrocksv1alpha1.DeletePVCRetentionPolicy {
+		return h.k8s.DeletePVC(pvc)
+	}
+	if pvc.Labels[resources.PVCRetained] != "" {
+		return nil
+	}
+	pvc.Labels = resources.MergeLabels(pvc.Labels, map[string]string{resources.PVCRetained: resources.PVCRetainedScaled})
+	h.kvrocks.Logger().Info("retain pvc", "pvc", pvc.Name)
+	return h.k8s.UpdatePVC(pvc)
+}
+
+// ReusePVC removes the retained label when the pvc is used by a pod again, the pvc retained from a deleted kvrocks
+// is owned by the new kvrocks
+func (h *CommandHandler) ReusePVC(pvc *corev1.PersistentVolumeClaim) error {
+	if _, ok := pvc.Labels[resources.PVCRetained]; !ok {
+		return nil
+	}
+	delete(pvc.Labels, resources.PVCRetained)
+	if metav1.GetControllerOf(pvc) == nil {
+		pvc.OwnerReferences = append(pvc.OwnerReferences, *metav1.NewControllerRef(h.instance, h.instance.GroupVersionKind()))
+	}
+	h.kvrocks.Logger().Info("reuse retained pvc", "pvc", pvc.Name)
+	return h.k8s.UpdatePVC(pvc)
+}
+
+// RetainPVCs removes the owner references of the pvcs if whenDeleted is Retain, so that they are not deleted with the kvrocks
+func (h *CommandHandler) RetainPVCs() error {
+	if resources.GetPVCRetentionPolicy(h.instance).WhenDeleted == kvrocksv1alpha1.DeletePVCRetentionPolicy {
+		return nil
+	}
+	pvcList, err := h.k8s.ListPVC(h.instance.Namespace, resources.SelectorLabels(h.instance))
+	if err != nil {
+		return err
+	}
+	for i := range pvcList.Items {
+		pvc := &pvcList.Items[i]
+		var owners []metav1.OwnerReference
+		for _, owner := range pvc.OwnerReferences {
+			if owner.UID != h.instance.UID {
+				owners = append(owners, owner)
+			}
+		}
+		pvc.OwnerReferences = owners
+		pvc.Labels = resources.MergeLabels(pvc.Labels, map[string]string{resources.PVCRetained: resources.PVCRetainedDeleted})
+		if err = h.k8s.UpdatePVC(pvc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteFailoverPVC deletes the pvc of a failed pod before it is replaced. With the DeleteOnNodeLoss policy, the pvc
+// is kept unless it is bound to a local volume whose node is lost, since the new pod can not be scheduled there
+func (h *CommandHandler) DeleteFailoverPVC(podName string) error {
+	if resources.GetPVCRetentionPolicy(h.instance).WhenFailover == kvrocksv1alpha1.DeleteFailoverPVCPolicy {
+		if err := h.k8s.DeletePVCByPod(podName, h.instance.Namespace); err != nil {
+			return err
+		}
+		h.PodEventf(podName, corev1.EventTypeNormal, ReasonPVCDeleted, "pvc data-%s is deleted by the failover policy", podName)
+		return nil
+	}
+	pvc, err := h.k8s.GetPVC(types.NamespacedName{
+		Namespace: h.instance.Namespace,
+		Name:      "data-" + podName,
+	})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	lost, err := h.isLocalVolumeLost(pvc)
+	if err != nil || !lost {
+		return err
+	}
+	h.kvrocks.Logger().Info("node of local volume is lost, delete pvc", "pvc", pvc.Name)
+	if err = h.k8s.DeletePVC(pvc); err != nil {
+		return err
+	}
+	h.PodEventf(podName, corev1.EventTypeWarning, ReasonPVCDeleted, "pvc %s is deleted since the node of its local volume is lost", pvc.Name)
+	return nil
+}
+
+// isLocalVolumeLost checks if the pvc is bound to a local volume whose node is removed or not ready
+func (h *CommandHandler) isLocalVolumeLost(pvc *corev1.PersistentVolumeClaim) (bool, error) {
+	if pvc.Spec.VolumeName == "" {
+		return false, nil
+	}
+	pv, err := h.k8s.GetPV(pvc.Spec.VolumeName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	nodeName := localVolumeNode(pv)
+	if nodeName == "" {
+		return false, nil
+	}
+	node, err := h.k8s.GetNode(nodeName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status != corev1.ConditionTrue, nil
+		}
+	}
+	return false, nil
+}
+
+// localVolumeNode returns the node which the local volume is pinned to
+func localVolumeNode(pv *corev1.PersistentVolume) string {
+	if pv.Spec.Local == nil && pv.Spec.HostPath == nil {
+		return ""
+	}
+	if pv.Spec.NodeAffinity == nil || pv.Spec.NodeAffinity.Required == nil {
+		return ""
+	}
+	for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
+		for _, expression := range term.MatchExpressions {
+			if expression.Key == corev1.LabelHostname && expression.Operator == corev1.NodeSelectorOpIn && len(expression.Values) == 1 {
+				return expression.Values[0]
+			}
+		}
+	}
+	return ""
+}
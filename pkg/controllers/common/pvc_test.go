@@ -0,0 +1,110 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8sApiClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+)
+
+func TestDeleteFailoverPVC(t *testing.T) {
+	newNode := func(ready corev1.ConditionStatus) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		policy     kvrocksv1alpha1.FailoverPVCPolicyType
+		node       *corev1.Node
+		expDeleted bool
+		expEvents  []string
+	}{
+		{
+			name:       "The pvc should be deleted by the Delete policy.",
+			policy:     kvrocksv1alpha1.DeleteFailoverPVCPolicy,
+			node:       newNode(corev1.ConditionTrue),
+			expDeleted: true,
+			expEvents: []string{
+				"Normal PVCDeleted pod test-0: pvc data-test-0 is deleted by the failover policy",
+				"Normal PVCDeleted pvc data-test-0 is deleted by the failover policy",
+			},
+		}, {
+			name:   "The pvc on a ready node should be kept by the DeleteOnNodeLoss policy.",
+			policy: kvrocksv1alpha1.DeleteOnNodeLossFailoverPVCPolicy,
+			node:   newNode(corev1.ConditionTrue),
+		}, {
+			name:       "The pvc on a not ready node should be deleted by the DeleteOnNodeLoss policy.",
+			policy:     kvrocksv1alpha1.DeleteOnNodeLossFailoverPVCPolicy,
+			node:       newNode(corev1.ConditionFalse),
+			expDeleted: true,
+			expEvents: []string{
+				"Warning PVCDeleted pod test-0: pvc data-test-0 is deleted since the node of its local volume is lost",
+				"Warning PVCDeleted pvc data-test-0 is deleted since the node of its local volume is lost",
+			},
+		}, {
+			name:       "The pvc on a removed node should be deleted by the DeleteOnNodeLoss policy.",
+			policy:     kvrocksv1alpha1.DeleteOnNodeLossFailoverPVCPolicy,
+			expDeleted: true,
+			expEvents: []string{
+				"Warning PVCDeleted pod test-0: pvc data-test-0 is deleted since the node of its local volume is lost",
+				"Warning PVCDeleted pvc data-test-0 is deleted since the node of its local volume is lost",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			instance := newTestInstance()
+			instance.Spec.Storage = &kvrocksv1alpha1.KVRocksStorage{
+				RetentionPolicy: &kvrocksv1alpha1.KVRocksPVCRetentionPolicy{WhenFailover: test.policy},
+			}
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "data-test-0", Namespace: instance.Namespace},
+				Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-1"},
+			}
+			pv := &corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "pv-1"},
+				Spec: corev1.PersistentVolumeSpec{
+					PersistentVolumeSource: corev1.PersistentVolumeSource{
+						Local: &corev1.LocalVolumeSource{Path: "/data"},
+					},
+					NodeAffinity: &corev1.VolumeNodeAffinity{
+						Required: &corev1.NodeSelector{
+							NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+								MatchExpressions: []corev1.NodeSelectorRequirement{{
+									Key:      corev1.LabelHostname,
+									Operator: corev1.NodeSelectorOpIn,
+									Values:   []string{"node-1"},
+								}},
+							}},
+						},
+					},
+				},
+			}
+			objs := []k8sApiClient.Object{pvc, pv, newTestPod(instance, "test", 0, metav1.Now())}
+			if test.node != nil {
+				objs = append(objs, test.node)
+			}
+			h, fakeClient, recorder := newTestHandler(instance, newFakeKVRocks(), objs...)
+
+			assert.NoError(h.DeleteFailoverPVC("test-0"))
+			err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: instance.Namespace, Name: pvc.Name}, &corev1.PersistentVolumeClaim{})
+			assert.Equal(test.expDeleted, k8serrors.IsNotFound(err))
+			assert.Equal(test.expEvents, drainEvents(recorder))
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package common
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+// StatusRefreshInterval is how often the observed status is refreshed, the replication offsets are written to status
+// at most once per interval unless the health changes
+var StatusRefreshInterval = time.Minute
+
+// GetReplication observes the replication of the nodes of a statefulSet, indexed by the pod index. The slaves are
+// compared with the master in the same statefulSet, the nodes which can not be reached are absent
+func (h *CommandHandler) GetReplication(stsName string, nodes []*kvrocks.Node) map[int]*kvrocksv1alpha1.KVRocksReplication {
+	infos := map[int]*kvrocks.ReplicationInfo{}
+	var masterOffset int64 = -1
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		info, err := h.kvrocks.GetReplicationInfo(node.IP, h.password)
+		if err != nil {
+			continue
+		}
+		infos[node.PodIndex] = info
+		if node.Role == kvrocks.RoleMaster {
+			masterOffset = info.Offset
+		}
+	}
+	now := metav1.Now()
+	replication := map[int]*kvrocksv1alpha1.KVRocksReplication{}
+	for _, node := range nodes {
+		if node == nil || infos[node.PodIndex] == nil {
+			continue
+		}
+		info := infos[node.PodIndex]
+		current := &kvrocksv1alpha1.KVRocksReplication{
+			Offset:       info.Offset,
+			ObservedTime: now,
+		}
+		previous := h.findReplication(fmt.Sprintf("%s-%d", stsName, node.PodIndex))
+		if node.Role != kvrocks.RoleMaster {
+			current.LinkStatus = info.LinkStatus
+			current.SyncInProgress = info.SyncInProgress
+			if masterOffset >= 0 && masterOffset > info.Offset {
+				current.Lag = masterOffset - info.Offset
+			}
+			if h.isInSync(current) {
+				current.LastSyncTime = &now
+			} else if previous != nil && previous.LastSyncTime != nil {
+				current.LastSyncTime = previous.LastSyncTime
+				current.LagSeconds = int64(now.Sub(previous.LastSyncTime.Time).Seconds())
+			} else {
+				// a slave never in sync, such as a wiped one which never finishes the full sync, is out of sync since
+				// it is first observed
+				current.FirstObservedTime = &now
+				if previous != nil && previous.FirstObservedTime != nil {
+					current.FirstObservedTime = previous.FirstObservedTime
+				}
+				current.LagSeconds = int64(now.Sub(current.FirstObservedTime.Time).Seconds())
+			}
+		}
+		// keep the previous observation if only the offsets change, so that the status is not updated all the time.
+		// The seconds out of sync still grow on every pass
+		if previous != nil && now.Sub(previous.ObservedTime.Time) < StatusRefreshInterval &&
+			previous.LinkStatus == current.LinkStatus && previous.SyncInProgress == current.SyncInProgress &&
+			h.isInSync(previous) == h.isInSync(current) {
+			lagSeconds := current.LagSeconds
+			current = previous.DeepCopy()
+			current.LagSeconds = lagSeconds
+		}
+		replication[node.PodIndex] = current
+	}
+	return replication
+}
+
+// isInSync checks if a slave is linked to its master and its lag is within spec.maxReplicationLag
+func (h *CommandHandler) isInSync(replication *kvrocksv1alpha1.KVRocksReplication) bool {
+	return replication.LinkStatus == "up" && !replication.SyncInProgress &&
+		replication.Lag <= resources.GetMaxReplicationLag(h.instance)
+}
+
+// findReplication returns the replication of the pod in status
+func (h *CommandHandler) findReplication(pod string) *kvrocksv1alpha1.KVRocksReplication {
+	for _, node := range h.instance.Status.Nodes {
+		if node.Pod == pod {
+			return node.Replication
+		}
+	}
+	for _, partition := range h.instance.Status.Topo {
+		for _, topo := range partition.Topology {
+			if topo.Pod == pod {
+				return topo.Replication
+			}
+		}
+	}
+	return nil
+}
+
+// UpdateReplicationHealth sets the ReplicationHealthy condition and the max lag from the nodes in status, the
+// kvrocks is updated if they change or the nodes are changed by the caller
+func (h *CommandHandler) UpdateReplicationHealth(changed bool) error {
+	var nodes []kvrocksv1alpha1.KVRocksTopology
+	nodes = append(nodes, h.instance.Status.Nodes...)
+	for _, partition := range h.instance.Status.Topo {
+		nodes = append(nodes, partition.Topology...)
+	}
+	var maxLag int64
+	var unknown, linkDown, syncing, lagging []string
+	for _, node := range nodes {
+		if node.Role == kvrocks.RoleMaster {
+			continue
+		}
+		replication := node.Replication
+		switch {
+		case replication == nil:
+			unknown = append(unknown, node.Pod)
+			continue
+		case replication.SyncInProgress:
+			syncing = append(syncing, node.Pod)
+		case replication.LinkStatus != "up":
+			linkDown = append(linkDown, node.Pod)
+		case !h.isInSync(replication):
+			lagging = append(lagging, node.Pod)
+		}
+		if replication.Lag > maxLag {
+			maxLag = replication.Lag
+		}
+	}
+	status, reason, message := metav1.ConditionTrue, "InSync", "all slaves are in sync with their masters"
+	switch {
+	case len(linkDown) != 0:
+		status, reason, message = metav1.ConditionFalse, "LinkDown", "master link is down on "+strings.Join(linkDown, ",")
+	case len(syncing) != 0:
+		status, reason, message = metav1.ConditionFalse, "FullSync", "full sync is in progress on "+strings.Join(syncing, ",")
+	case len(lagging) != 0:
+		status, reason, message = metav1.ConditionFalse, "Lagging", "replication lag exceeds the max on "+strings.Join(lagging, ",")
+	case len(unknown) != 0:
+		status, reason, message = metav1.ConditionUnknown, "Unknown", "replication is unknown on "+strings.Join(unknown, ",")
+	}
+	if SetCondition(h.instance, kvrocksv1alpha1.ConditionReplicationHealthy, status, reason, message) {
+		changed = true
+	}
+	if h.instance.Status.ReplicationLag != maxLag {
+		h.instance.Status.ReplicationLag = maxLag
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return h.k8s.UpdateKVRocks(h.instance)
+}
+
+// EnsureReplicationGate sets the replication readiness gate of the pods of the statefulSet. A slave lagging behind its
+// master more than spec.maxReplicationLag is not ready, so it is excluded from the read service
+func (h *CommandHandler) EnsureReplicationGate(stsName string, nodes []*kvrocks.Node, replication map[int]*kvrocksv1alpha1.KVRocksReplication) error {
+	maxLag := resources.GetMaxReplicationLag(h.instance)
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		status, reason, message := corev1.ConditionTrue, "Master", ""
+		if node.Role != kvrocks.RoleMaster {
+			reason = "InSync"
+			current := replication[node.PodIndex]
+			switch {
+			case current == nil:
+				status, reason, message = corev1.ConditionFalse, "Unknown", "replication offset is unknown"
+			case current.LinkStatus != "up":
+				status, reason, message = corev1.ConditionFalse, "LinkDown", "master link is down"
+			case current.SyncInProgress:
+				status, reason, message = corev1.ConditionFalse, "FullSync", "full sync is in progress"
+			case current.Lag > maxLag:
+				status, reason, message = corev1.ConditionFalse, "Lagging", fmt.Sprintf("replication lag %d exceeds %d", current.Lag, maxLag)
+			}
+		}
+		podName := fmt.Sprintf("%s-%d", stsName, node.PodIndex)
+		if err := h.setPodCondition(podName, resources.ReplicationReadyGate, status, reason, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnsureMasterGate passes the replication readiness gate of a master pod, it is called as soon as the pod is known to be
+// a master so the master service is not blocked until the replication is observed
+func (h *CommandHandler) EnsureMasterGate(podName string) error {
+	return h.setPodCondition(podName, resources.ReplicationReadyGate, corev1.ConditionTrue, "Master", "")
+}
+
+// UpdateEndpoints publishes the addresses of the master service and the read service in status
+func (h *CommandHandler) UpdateEndpoints() error {
+	endpoints := resources.GetEndpoints(h.instance)
+	if reflect.DeepEqual(h.instance.Status.Endpoints, endpoints) {
+		return nil
+	}
+	h.instance.Status.Endpoints = endpoints
+	return h.k8s.UpdateKVRocks(h.instance)
+}
+
+// setPodCondition updates the pod condition only if its status changes
+func (h *CommandHandler) setPodCondition(podName string, conditionType corev1.PodConditionType, status corev1.ConditionStatus, reason, message string) error {
+	pod, err := h.k8s.GetPod(types.NamespacedName{
+		Namespace: h.instance.Namespace,
+		Name:      podName,
+	})
+	if err != nil {
+		return err
+	}
+	condition := corev1.PodCondition{
+		Type:               conditionType,
+		Status:             status,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+	found := false
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type != conditionType {
+			continue
+		}
+		if pod.Status.Conditions[i].Status == status {
+			return nil
+		}
+		pod.Status.Conditions[i] = condition
+		found = true
+	}
+	if !found {
+		pod.Status.Conditions = append(pod.Status.Conditions, condition)
+	}
+	if status == corev1.ConditionFalse {
+		h.kvrocks.Logger().Info("slave is not ready to serve reads", "pod", podName, "reason", message)
+	}
+	return h.k8s.UpdatePodStatus(pod)
+}
@@ -0,0 +1,307 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8sApiClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+func TestEnsureReplicationGate(t *testing.T) {
+	tests := []struct {
+		name        string
+		role        string
+		replication *kvrocksv1alpha1.KVRocksReplication
+		expStatus   corev1.ConditionStatus
+		expReason   string
+	}{
+		{
+			name:      "A master should be ready without the replication.",
+			role:      kvrocks.RoleMaster,
+			expStatus: corev1.ConditionTrue,
+			expReason: "Master",
+		}, {
+			name:        "A slave in sync should be ready.",
+			role:        kvrocks.RoleSlaver,
+			replication: &kvrocksv1alpha1.KVRocksReplication{LinkStatus: "up"},
+			expStatus:   corev1.ConditionTrue,
+			expReason:   "InSync",
+		}, {
+			name:      "A slave with unknown replication should not be ready.",
+			role:      kvrocks.RoleSlaver,
+			expStatus: corev1.ConditionFalse,
+			expReason: "Unknown",
+		}, {
+			name:        "A slave with the master link down should not be ready.",
+			role:        kvrocks.RoleSlaver,
+			replication: &kvrocksv1alpha1.KVRocksReplication{LinkStatus: "down"},
+			expStatus:   corev1.ConditionFalse,
+			expReason:   "LinkDown",
+		}, {
+			name:        "A slave lagging more than the max should not be ready.",
+			role:        kvrocks.RoleSlaver,
+			replication: &kvrocksv1alpha1.KVRocksReplication{LinkStatus: "up", Lag: resources.DefaultMaxReplicationLag + 1},
+			expStatus:   corev1.ConditionFalse,
+			expReason:   "Lagging",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			instance := newTestInstance()
+			sts := newTestStatefulSet(instance, "test")
+			pod := newTestPod(instance, "test", 0, metav1.Now())
+			h, fakeClient, _ := newTestHandler(instance, newFakeKVRocks(), sts, pod)
+
+			replication := map[int]*kvrocksv1alpha1.KVRocksReplication{}
+			if test.replication != nil {
+				replication[0] = test.replication
+			}
+			assert.NoError(h.EnsureReplicationGate("test", []*kvrocks.Node{{IP: "10.0.0.1", Role: test.role, PodIndex: 0}}, replication))
+			assertPodCondition(t, fakeClient, pod.Name, test.expStatus, test.expReason)
+		})
+	}
+}
+
+func TestEnsureMasterGate(t *testing.T) {
+	instance := newTestInstance()
+	sts := newTestStatefulSet(instance, "test")
+	pod := newTestPod(instance, "test", 0, metav1.Now())
+	pod.Status.Conditions = []corev1.PodCondition{{
+		Type:   resources.ReplicationReadyGate,
+		Status: corev1.ConditionFalse,
+		Reason: "Unknown",
+	}}
+	h, fakeClient, _ := newTestHandler(instance, newFakeKVRocks(), sts, pod)
+
+	assert.NoError(t, h.EnsureMasterGate(pod.Name))
+	assertPodCondition(t, fakeClient, pod.Name, corev1.ConditionTrue, "Master")
+}
+
+func assertPodCondition(t *testing.T, fakeClient k8sApiClient.Client, podName string, expStatus corev1.ConditionStatus, expReason string) {
+	var pod corev1.Pod
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "unit-test", Name: podName}, &pod))
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == resources.ReplicationReadyGate {
+			assert.Equal(t, expStatus, condition.Status)
+			assert.Equal(t, expReason, condition.Reason)
+			return
+		}
+	}
+	t.Errorf("condition %s is not found", resources.ReplicationReadyGate)
+}
+
+func TestGetReplication(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	lastSync := metav1.NewTime(now.Add(-10 * time.Minute))
+	stale := metav1.NewTime(now.Add(-2 * StatusRefreshInterval))
+	recent := metav1.NewTime(now.Add(-StatusRefreshInterval / 2))
+
+	tests := []struct {
+		name          string
+		slave         *kvrocks.ReplicationInfo
+		slaveDown     bool
+		previous      *kvrocksv1alpha1.KVRocksReplication
+		expAbsent     bool
+		expLag        int64
+		expInSync     bool
+		expLastSync   *metav1.Time
+		expFirst      *metav1.Time
+		expLagSeconds int64
+		expOffset     int64
+	}{
+		{
+			name:      "A slave in sync should record the observed time as the last sync time.",
+			slave:     &kvrocks.ReplicationInfo{Offset: 900, LinkStatus: "up"},
+			expLag:    100,
+			expInSync: true,
+			expOffset: 900,
+		}, {
+			name:          "A lagging slave should keep the last sync time and count the seconds since it.",
+			slave:         &kvrocks.ReplicationInfo{Offset: 0, LinkStatus: "up"},
+			previous:      &kvrocksv1alpha1.KVRocksReplication{LinkStatus: "up", Offset: 0, Lag: 800, LastSyncTime: &lastSync, ObservedTime: stale},
+			expLag:        1000,
+			expLastSync:   &lastSync,
+			expLagSeconds: 600,
+			expOffset:     0,
+		}, {
+			name:          "A slave whose link is down should keep the last sync time and count the seconds since it.",
+			slave:         &kvrocks.ReplicationInfo{Offset: 500, LinkStatus: "down"},
+			previous:      &kvrocksv1alpha1.KVRocksReplication{LinkStatus: "up", Offset: 500, LastSyncTime: &lastSync, ObservedTime: stale},
+			expLag:        500,
+			expLastSync:   &lastSync,
+			expLagSeconds: 600,
+			expOffset:     500,
+		}, {
+			name:      "A slave never in sync should have no last sync time and be out of sync since it is observed.",
+			slave:     &kvrocks.ReplicationInfo{Offset: 0, LinkStatus: "down"},
+			expLag:    1000,
+			expOffset: 0,
+		}, {
+			name:          "A slave never in sync should count the seconds since it is first observed.",
+			slave:         &kvrocks.ReplicationInfo{Offset: 0, LinkStatus: "up", SyncInProgress: true},
+			previous:      &kvrocksv1alpha1.KVRocksReplication{LinkStatus: "down", FirstObservedTime: &lastSync, ObservedTime: stale},
+			expLag:        1000,
+			expFirst:      &lastSync,
+			expLagSeconds: 600,
+			expOffset:     0,
+		}, {
+			name:      "An unreachable slave should be absent.",
+			slaveDown: true,
+			expAbsent: true,
+		}, {
+			name:      "The recent observation should be kept if only the offset changes.",
+			slave:     &kvrocks.ReplicationInfo{Offset: 950, LinkStatus: "up"},
+			previous:  &kvrocksv1alpha1.KVRocksReplication{LinkStatus: "up", Offset: 800, Lag: 200, LastSyncTime: &recent, ObservedTime: recent},
+			expLag:    200,
+			expInSync: true,
+			expOffset: 800,
+		}, {
+			name:          "The seconds out of sync should be recomputed when the recent observation is kept.",
+			slave:         &kvrocks.ReplicationInfo{Offset: 500, LinkStatus: "down"},
+			previous:      &kvrocksv1alpha1.KVRocksReplication{LinkStatus: "down", Offset: 400, Lag: 600, LastSyncTime: &lastSync, LagSeconds: 570, ObservedTime: recent},
+			expLag:        600,
+			expLastSync:   &lastSync,
+			expLagSeconds: 600,
+			expOffset:     400,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			instance := newTestInstance()
+			maxLag := int64(500)
+			instance.Spec.MaxReplicationLag = &maxLag
+			instance.Status.Nodes = []kvrocksv1alpha1.KVRocksTopology{
+				{Pod: "test-0", Role: kvrocks.RoleMaster},
+				{Pod: "test-1", Role: kvrocks.RoleSlaver, Replication: test.previous},
+			}
+			kvClient := newFakeKVRocks()
+			kvClient.replication["10.0.0.1"] = &kvrocks.ReplicationInfo{Role: kvrocks.RoleMaster, Offset: 1000}
+			if test.slave != nil {
+				kvClient.replication["10.0.0.2"] = test.slave
+			}
+			kvClient.down["10.0.0.2"] = test.slaveDown
+			h, _, _ := newTestHandler(instance, kvClient)
+
+			replication := h.GetReplication("test", []*kvrocks.Node{
+				{IP: "10.0.0.1", Role: kvrocks.RoleMaster, PodIndex: 0},
+				{IP: "10.0.0.2", Role: kvrocks.RoleSlaver, PodIndex: 1},
+				nil,
+			})
+			if assert.NotNil(replication[0]) {
+				assert.Equal(int64(1000), replication[0].Offset)
+				assert.Zero(replication[0].Lag)
+				assert.Nil(replication[0].LastSyncTime)
+			}
+			slave := replication[1]
+			if test.expAbsent {
+				assert.Nil(slave)
+				return
+			}
+			if !assert.NotNil(slave) {
+				return
+			}
+			assert.Equal(test.expLag, slave.Lag)
+			assert.Equal(test.expOffset, slave.Offset)
+			assert.Equal(test.expInSync, h.isInSync(slave))
+			switch {
+			case test.expInSync:
+				assert.NotNil(slave.LastSyncTime)
+				assert.Zero(slave.LagSeconds)
+			case test.expLastSync != nil:
+				assert.True(test.expLastSync.Equal(slave.LastSyncTime))
+				assert.Nil(slave.FirstObservedTime)
+				assert.InDelta(test.expLagSeconds, slave.LagSeconds, 2)
+			default:
+				assert.Nil(slave.LastSyncTime)
+				if assert.NotNil(slave.FirstObservedTime) && test.expFirst != nil {
+					assert.True(test.expFirst.Equal(slave.FirstObservedTime))
+				}
+				assert.InDelta(test.expLagSeconds, slave.LagSeconds, 2)
+			}
+		})
+	}
+}
+
+func TestUpdateReplicationHealth(t *testing.T) {
+	tests := []struct {
+		name      string
+		slaves    []*kvrocksv1alpha1.KVRocksReplication
+		expStatus metav1.ConditionStatus
+		expReason string
+		expLag    int64
+	}{
+		{
+			name:      "Slaves in sync should be healthy.",
+			slaves:    []*kvrocksv1alpha1.KVRocksReplication{{LinkStatus: "up", Lag: 10}, {LinkStatus: "up", Lag: 20}},
+			expStatus: metav1.ConditionTrue,
+			expReason: "InSync",
+			expLag:    20,
+		}, {
+			name:      "A slave whose link is down should be reported first.",
+			slaves:    []*kvrocksv1alpha1.KVRocksReplication{{LinkStatus: "down", Lag: 10}, {LinkStatus: "up", SyncInProgress: true}},
+			expStatus: metav1.ConditionFalse,
+			expReason: "LinkDown",
+			expLag:    10,
+		}, {
+			name:      "A slave in full sync should not be healthy.",
+			slaves:    []*kvrocksv1alpha1.KVRocksReplication{{LinkStatus: "up", SyncInProgress: true}, {LinkStatus: "up"}},
+			expStatus: metav1.ConditionFalse,
+			expReason: "FullSync",
+		}, {
+			name:      "A slave lagging more than the max should not be healthy.",
+			slaves:    []*kvrocksv1alpha1.KVRocksReplication{{LinkStatus: "up", Lag: 2000}, {LinkStatus: "up"}},
+			expStatus: metav1.ConditionFalse,
+			expReason: "Lagging",
+			expLag:    2000,
+		}, {
+			name:      "A slave without observation should be unknown.",
+			slaves:    []*kvrocksv1alpha1.KVRocksReplication{nil, {LinkStatus: "up"}},
+			expStatus: metav1.ConditionUnknown,
+			expReason: "Unknown",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			instance := newTestInstance()
+			maxLag := int64(1000)
+			instance.Spec.MaxReplicationLag = &maxLag
+			instance.Status.Nodes = []kvrocksv1alpha1.KVRocksTopology{{Pod: "test-0", Role: kvrocks.RoleMaster}}
+			for i, slave := range test.slaves {
+				instance.Status.Nodes = append(instance.Status.Nodes, kvrocksv1alpha1.KVRocksTopology{
+					Pod: fmt.Sprintf("test-%d", i+1), Role: kvrocks.RoleSlaver, Replication: slave,
+				})
+			}
+			h, fakeClient, _ := newTestHandler(instance, newFakeKVRocks())
+
+			assert.NoError(h.UpdateReplicationHealth(false))
+			var updated kvrocksv1alpha1.KVRocks
+			assert.NoError(fakeClient.Get(context.TODO(), k8sApiClient.ObjectKeyFromObject(instance), &updated))
+			condition := meta.FindStatusCondition(updated.Status.Conditions, kvrocksv1alpha1.ConditionReplicationHealthy)
+			if assert.NotNil(condition) {
+				assert.Equal(test.expStatus, condition.Status)
+				assert.Equal(test.expReason, condition.Reason)
+			}
+			assert.Equal(test.expLag, updated.Status.ReplicationLag)
+		})
+	}
+}
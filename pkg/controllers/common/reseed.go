@@ -0,0 +1,93 @@
+package common
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+// EnsureReseed creates a Resync operation for a slave which is out of sync longer than spec.reseed.outOfSyncTimeout
+// while its master is reachable. Nothing is created while another operation is unfinished, or for a slave resynced
+// within the timeout
+func (h *CommandHandler) EnsureReseed() error {
+	if h.instance.Spec.Reseed == nil || resources.IsUnderMaintenance(h.instance) {
+		return nil
+	}
+	timeout := h.instance.Spec.Reseed.OutOfSyncTimeout.Duration
+	operations, err := h.k8s.ListKVRocksOperations(h.instance.Namespace, h.instance.Name)
+	if err != nil {
+		return err
+	}
+	resynced := map[string]bool{}
+	for _, op := range operations {
+		if !op.IsFinished() {
+			return nil
+		}
+		if op.Spec.Type == kvrocksv1alpha1.ResyncOperation && op.Status.CompletionTime != nil &&
+			time.Since(op.Status.CompletionTime.Time) < timeout {
+			resynced[op.Spec.Pod] = true
+		}
+	}
+	groups := [][]kvrocksv1alpha1.KVRocksTopology{h.instance.Status.Nodes}
+	for _, partition := range h.instance.Status.Topo {
+		groups = append(groups, partition.Topology)
+	}
+	for _, nodes := range groups {
+		master := getReachableMaster(nodes)
+		if master == nil {
+			continue
+		}
+		for _, node := range nodes {
+			if node.Role == kvrocks.RoleMaster || node.Replication == nil || resynced[node.Pod] || h.isInSync(node.Replication) {
+				continue
+			}
+			outOfSync := getOutOfSyncDuration(node.Replication)
+			if outOfSync < timeout {
+				continue
+			}
+			// the status may be observed before the slave caught up, it is not reseeded if it is in sync now
+			synced, err := h.IsReplicaSynced(&kvrocks.Node{IP: master.Ip}, &kvrocks.Node{IP: node.Ip})
+			if err != nil {
+				h.kvrocks.Logger().Info("skip the reseed of an unreachable node", "pod", node.Pod, "error", err.Error())
+				continue
+			}
+			if synced {
+				continue
+			}
+			op := resources.NewReseedOperation(h.instance, node.Pod)
+			if err = h.k8s.CreateKVRocksOperation(op); err != nil {
+				return err
+			}
+			h.PodEventf(node.Pod, corev1.EventTypeWarning, ReasonReseed, "out of sync for %s, reseeded by operation %s", outOfSync, op.Name)
+			return nil
+		}
+	}
+	return nil
+}
+
+// getReachableMaster returns the master whose replication is observed
+func getReachableMaster(nodes []kvrocksv1alpha1.KVRocksTopology) *kvrocksv1alpha1.KVRocksTopology {
+	for i, node := range nodes {
+		if node.Role == kvrocks.RoleMaster && node.Replication != nil {
+			return &nodes[i]
+		}
+	}
+	return nil
+}
+
+// getOutOfSyncDuration returns how long the slave has been out of sync until now, since its last sync time or the
+// first observed time if it has never been in sync. The LagSeconds in status is only as fresh as the last update
+func getOutOfSyncDuration(replication *kvrocksv1alpha1.KVRocksReplication) time.Duration {
+	since := replication.LastSyncTime
+	if since == nil {
+		since = replication.FirstObservedTime
+	}
+	if since == nil {
+		return 0
+	}
+	return time.Since(since.Time).Truncate(time.Second)
+}
@@ -0,0 +1,160 @@
+package common
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sApiClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+func TestEnsureReseed(t *testing.T) {
+	timeout := 30 * time.Minute
+	since := func(d time.Duration) *metav1.Time {
+		t := metav1.NewTime(time.Now().Add(-d))
+		return &t
+	}
+	outOfSync := &kvrocksv1alpha1.KVRocksReplication{LinkStatus: "down", LastSyncTime: since(timeout + time.Minute)}
+	finished := func(instance *kvrocksv1alpha1.KVRocks, completed time.Duration) *kvrocksv1alpha1.KVRocksOperation {
+		op := newTestOperation(instance, "resync", kvrocksv1alpha1.ResyncOperation, time.Now().Add(-time.Hour))
+		completionTime := metav1.NewTime(time.Now().Add(-completed))
+		op.Status = kvrocksv1alpha1.KVRocksOperationStatus{Phase: kvrocksv1alpha1.OperationSucceeded, CompletionTime: &completionTime}
+		return op
+	}
+
+	tests := []struct {
+		name        string
+		disabled    bool
+		maintenance bool
+		masterDown  bool
+		slave       *kvrocksv1alpha1.KVRocksReplication
+		slaveDown   bool
+		live        *kvrocks.ReplicationInfo
+		operations  func(instance *kvrocksv1alpha1.KVRocks) []k8sApiClient.Object
+		expReseed   bool
+	}{
+		{
+			name:      "A slave out of sync longer than the timeout should be reseeded.",
+			slave:     outOfSync,
+			expReseed: true,
+		}, {
+			name:      "A slave never in sync longer than the timeout should be reseeded.",
+			slave:     &kvrocksv1alpha1.KVRocksReplication{LinkStatus: "up", SyncInProgress: true, FirstObservedTime: since(timeout + time.Minute)},
+			expReseed: true,
+		}, {
+			name:      "The time out of sync should be counted until now rather than by the seconds in status.",
+			slave:     &kvrocksv1alpha1.KVRocksReplication{LinkStatus: "down", LagSeconds: 60, LastSyncTime: outOfSync.LastSyncTime},
+			expReseed: true,
+		}, {
+			name:  "A slave out of sync shorter than the timeout should not be reseeded.",
+			slave: &kvrocksv1alpha1.KVRocksReplication{LinkStatus: "down", LagSeconds: int64(timeout.Seconds()), LastSyncTime: since(time.Minute)},
+		}, {
+			name:  "A slave in sync should not be reseeded.",
+			slave: &kvrocksv1alpha1.KVRocksReplication{LinkStatus: "up", LastSyncTime: outOfSync.LastSyncTime},
+		}, {
+			name:  "A slave in sync again since the status is observed should not be reseeded.",
+			slave: outOfSync,
+			live:  &kvrocks.ReplicationInfo{Role: kvrocks.RoleSlaver, LinkStatus: "up", Offset: 1000},
+		}, {
+			name:      "An unreachable slave should not be reseeded.",
+			slave:     outOfSync,
+			slaveDown: true,
+		}, {
+			name:       "A slave with an unreachable master should not be reseeded.",
+			slave:      outOfSync,
+			masterDown: true,
+		}, {
+			name:     "A slave should not be reseeded without the policy.",
+			disabled: true,
+			slave:    outOfSync,
+		}, {
+			name:        "A slave should not be reseeded under maintenance.",
+			maintenance: true,
+			slave:       outOfSync,
+		}, {
+			name:  "A slave should not be reseeded while another operation is unfinished.",
+			slave: outOfSync,
+			operations: func(instance *kvrocksv1alpha1.KVRocks) []k8sApiClient.Object {
+				return []k8sApiClient.Object{newTestOperation(instance, "restart", kvrocksv1alpha1.RestartPodOperation, time.Now())}
+			},
+		}, {
+			name:  "A slave resynced within the timeout should not be reseeded again.",
+			slave: outOfSync,
+			operations: func(instance *kvrocksv1alpha1.KVRocks) []k8sApiClient.Object {
+				return []k8sApiClient.Object{finished(instance, time.Minute)}
+			},
+		}, {
+			name:  "A slave resynced before the timeout should be reseeded again.",
+			slave: outOfSync,
+			operations: func(instance *kvrocksv1alpha1.KVRocks) []k8sApiClient.Object {
+				return []k8sApiClient.Object{finished(instance, timeout+time.Minute)}
+			},
+			expReseed: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			instance := newTestInstance()
+			if !test.disabled {
+				instance.Spec.Reseed = &kvrocksv1alpha1.KVRocksReseed{OutOfSyncTimeout: metav1.Duration{Duration: timeout}}
+			}
+			if test.maintenance {
+				instance.Annotations = map[string]string{resources.Maintenance: "true"}
+			}
+			master := &kvrocksv1alpha1.KVRocksReplication{Offset: 1000}
+			if test.masterDown {
+				master = nil
+			}
+			instance.Status.Nodes = []kvrocksv1alpha1.KVRocksTopology{
+				{Pod: "test-0", Role: kvrocks.RoleMaster, Ip: "10.0.0.1", Replication: master},
+				{Pod: "test-1", Role: kvrocks.RoleSlaver, Ip: "10.0.0.2", Replication: test.slave},
+			}
+			kvClient := newFakeKVRocks()
+			kvClient.offsets["10.0.0.1"] = 1000
+			kvClient.replication["10.0.0.2"] = test.live
+			if test.live == nil {
+				kvClient.replication["10.0.0.2"] = &kvrocks.ReplicationInfo{Role: kvrocks.RoleSlaver, LinkStatus: test.slave.LinkStatus, SyncInProgress: test.slave.SyncInProgress}
+			} else {
+				kvClient.offsets["10.0.0.2"] = int(test.live.Offset)
+			}
+			kvClient.down["10.0.0.2"] = test.slaveDown
+			var objs []k8sApiClient.Object
+			if test.operations != nil {
+				objs = test.operations(instance)
+			}
+			objs = append(objs, newTestPod(instance, "test", 1, metav1.Now()))
+			h, fakeClient, recorder := newTestHandler(instance, kvClient, objs...)
+
+			assert.NoError(h.EnsureReseed())
+			var list kvrocksv1alpha1.KVRocksOperationList
+			assert.NoError(fakeClient.List(context.TODO(), &list))
+			var reseeds []kvrocksv1alpha1.KVRocksOperation
+			for _, op := range list.Items {
+				if strings.HasPrefix(op.Name, "test-1-reseed-") {
+					reseeds = append(reseeds, op)
+				}
+			}
+			events := drainEvents(recorder)
+			if !test.expReseed {
+				assert.Empty(reseeds)
+				assert.Empty(events)
+				return
+			}
+			if assert.Len(reseeds, 1) {
+				assert.Equal(kvrocksv1alpha1.ResyncOperation, reseeds[0].Spec.Type)
+				assert.Equal("test-1", reseeds[0].Spec.Pod)
+				assert.Contains(events, "Warning Reseed pod test-1: out of sync for 31m0s, reseeded by operation "+reseeds[0].Name)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+// EnsureRestart restarts the pods of the statefulSet which were started with outdated restart-required configs.
+// Only one pod is restarted per call: slaves go first, the master is switched over before being restarted.
+// It returns the pods still waiting for restart.
+func (h *CommandHandler) EnsureRestart(key types.NamespacedName, nodes []*kvrocks.Node, switchover func(master *kvrocks.Node) error) ([]string, error) {
+	hash := resources.RestartConfigHash(resources.GetKVRocksConfig(h.instance))
+	cm, err := h.k8s.GetConfigMap(types.NamespacedName{
+		Namespace: h.instance.Namespace,
+		Name:      h.instance.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// the hash is added by UpdateKVRocksConfigMap to a configMap written by an older operator, nothing is known to be
+	// outdated until then
+	if _, ok := cm.Annotations[resources.ConfigHash]; !ok {
+		return nil, nil
+	}
+	pods, err := h.workload.ListStatefulSetPods(key)
+	if err != nil {
+		return nil, err
+	}
+	var pending []*corev1.Pod
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		cur, ok := pod.Annotations[resources.ConfigHash]
+		if !ok {
+			// the pod created after the configMap is changed is started with its configs, the others are unknown
+			// and restarted
+			cur, ok = startedConfigHash(cm, pod)
+			if !ok {
+				pending = append(pending, pod)
+				continue
+			}
+			if pod.Annotations == nil {
+				pod.Annotations = map[string]string{}
+			}
+			pod.Annotations[resources.ConfigHash] = cur
+			if err = h.k8s.UpdatePod(pod); err != nil {
+				return nil, err
+			}
+		}
+		if cur != hash {
+			pending = append(pending, pod)
+		}
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+	names := make([]string, 0, len(pending))
+	for _, pod := range pending {
+		if pod.DeletionTimestamp != nil {
+			return []string{pod.Name}, nil
+		}
+		names = append(names, pod.Name)
+	}
+	sort.Strings(names)
+	roles := map[string]*kvrocks.Node{}
+	for _, node := range nodes {
+		if node != nil {
+			roles[fmt.Sprintf("%s-%d", key.Name, node.PodIndex)] = node
+		}
+	}
+	var master *corev1.Pod
+	for _, pod := range pending {
+		node, ok := roles[pod.Name]
+		if ok && node.Role == kvrocks.RoleMaster {
+			master = pod
+			continue
+		}
+		h.kvrocks.Logger().Info("restart pod to apply configs", "pod", pod.Name)
+		return names, h.k8s.DeletePod(pod)
+	}
+	if len(roles) > 1 {
+		h.kvrocks.Logger().Info("switch over master before restart", "pod", master.Name)
+		return names, switchover(roles[master.Name])
+	}
+	h.kvrocks.Logger().Info("restart pod to apply configs", "pod", master.Name)
+	return names, h.k8s.DeletePod(master)
+}
+
+// startedConfigHash returns the hash of the configMap if the pod is created after the hash is changed, the times
+// are in seconds so a pod created in the same second is taken as started with the changed configs
+func startedConfigHash(cm *corev1.ConfigMap, pod *corev1.Pod) (string, bool) {
+	hash, ok := cm.Annotations[resources.ConfigHash]
+	if !ok {
+		return "", false
+	}
+	changed, err := time.Parse(time.RFC3339, cm.Annotations[resources.ConfigHashTime])
+	if err != nil || pod.CreationTimestamp.Time.Before(changed) {
+		return "", false
+	}
+	return hash, true
+}
+
+// UpdateKVRocksConfigMap updates the configMap of the kvrocks, the time its hash is changed is kept if the
+// restart-required configs are not changed. A configMap without hash is written by an older operator, the running
+// pods are taken as started with the current configs, so that they are stamped instead of restarted on upgrade
+func (h *CommandHandler) UpdateKVRocksConfigMap() error {
+	cm := resources.NewKVRocksConfigMap(h.instance)
+	oldCM, err := h.k8s.GetConfigMap(types.NamespacedName{
+		Namespace: cm.Namespace,
+		Name:      cm.Name,
+	})
+	if err != nil {
+		return err
+	}
+	if _, ok := oldCM.Annotations[resources.ConfigHash]; !ok {
+		cm.Annotations[resources.ConfigHashTime] = time.Unix(0, 0).UTC().Format(time.RFC3339)
+	} else if oldCM.Annotations[resources.ConfigHash] == cm.Annotations[resources.ConfigHash] {
+		if changed, ok := oldCM.Annotations[resources.ConfigHashTime]; ok {
+			cm.Annotations[resources.ConfigHashTime] = changed
+		}
+	}
+	cm.ResourceVersion = oldCM.ResourceVersion
+	return h.k8s.UpdateConfigMap(cm)
+}
+
+// UpdateRestartCondition reports the pods waiting for restart in the PendingRestart condition
+func (h *CommandHandler) UpdateRestartCondition(pending []string) error {
+	changed := false
+	if len(pending) != 0 {
+		changed = SetCondition(h.instance, kvrocksv1alpha1.ConditionPendingRestart, metav1.ConditionTrue,
+			"RollingRestart", "waiting for restart: "+strings.Join(pending, ","))
+	} else if meta.IsStatusConditionTrue(h.instance.Status.Conditions, kvrocksv1alpha1.ConditionPendingRestart) {
+		changed = SetCondition(h.instance, kvrocksv1alpha1.ConditionPendingRestart, metav1.ConditionFalse,
+			"RestartCompleted", "all pods run with the current configs")
+	}
+	if !changed {
+		return nil
+	}
+	return h.k8s.UpdateKVRocks(h.instance)
+}
@@ -0,0 +1,188 @@
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8sApiClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+func TestEnsureRestart(t *testing.T) {
+	changed := time.Now().Add(-time.Hour).Truncate(time.Second)
+	before := metav1.NewTime(changed.Add(-time.Minute))
+	after := metav1.NewTime(changed.Add(time.Minute))
+
+	tests := []struct {
+		name          string
+		cmAnnotated   bool
+		podHashes     []string
+		podCreated    []metav1.Time
+		roles         []string
+		expPending    []string
+		expDeleted    []string
+		expSwitchover bool
+		expStamped    []string
+	}{
+		{
+			name:        "Pods started with the current configs should not be restarted.",
+			cmAnnotated: true,
+			podHashes:   []string{"current", "current", "current"},
+			podCreated:  []metav1.Time{before, before, before},
+			roles:       []string{kvrocks.RoleMaster, kvrocks.RoleSlaver, kvrocks.RoleSlaver},
+			expPending:  nil,
+		}, {
+			name:        "Pods created after the configMap is changed should be stamped and not be restarted.",
+			cmAnnotated: true,
+			podHashes:   []string{"", "", ""},
+			podCreated:  []metav1.Time{after, after, after},
+			roles:       []string{kvrocks.RoleMaster, kvrocks.RoleSlaver, kvrocks.RoleSlaver},
+			expPending:  nil,
+			expStamped:  []string{"test-0", "test-1", "test-2"},
+		}, {
+			name:        "A pod without the hash created before the configMap is changed should be restarted.",
+			cmAnnotated: true,
+			podHashes:   []string{"current", "", "current"},
+			podCreated:  []metav1.Time{before, before, before},
+			roles:       []string{kvrocks.RoleMaster, kvrocks.RoleSlaver, kvrocks.RoleSlaver},
+			expPending:  []string{"test-1"},
+			expDeleted:  []string{"test-1"},
+		}, {
+			name:        "Pods should not be restarted until the configMap has a hash.",
+			cmAnnotated: false,
+			podHashes:   []string{"", "", ""},
+			podCreated:  []metav1.Time{before, before, before},
+			roles:       []string{kvrocks.RoleMaster, kvrocks.RoleSlaver, kvrocks.RoleSlaver},
+			expPending:  nil,
+		}, {
+			name:          "A master with an outdated hash should be switched over before restart.",
+			cmAnnotated:   true,
+			podHashes:     []string{"outdated", "current", "current"},
+			podCreated:    []metav1.Time{before, before, before},
+			roles:         []string{kvrocks.RoleMaster, kvrocks.RoleSlaver, kvrocks.RoleSlaver},
+			expPending:    []string{"test-0"},
+			expSwitchover: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			instance := newTestInstance()
+			instance.Spec.KVRocksConfig = map[string]string{"workers": "8"}
+			hash := resources.RestartConfigHash(resources.GetKVRocksConfig(instance))
+			cm := resources.NewKVRocksConfigMap(instance)
+			cm.Annotations[resources.ConfigHashTime] = changed.Format(time.RFC3339)
+			if !test.cmAnnotated {
+				cm.Annotations = nil
+			}
+			objs := []k8sApiClient.Object{cm, newTestStatefulSet(instance, "test")}
+			var nodes []*kvrocks.Node
+			for i, podHash := range test.podHashes {
+				pod := newTestPod(instance, "test", i, test.podCreated[i])
+				switch podHash {
+				case "current":
+					pod.Annotations = map[string]string{resources.ConfigHash: hash}
+				case "outdated":
+					pod.Annotations = map[string]string{resources.ConfigHash: "outdated"}
+				}
+				objs = append(objs, pod)
+				nodes = append(nodes, &kvrocks.Node{IP: pod.Name, Role: test.roles[i], PodIndex: i})
+			}
+			h, fakeClient, _ := newTestHandler(instance, newFakeKVRocks(), objs...)
+
+			switchover := false
+			pending, err := h.EnsureRestart(types.NamespacedName{Namespace: instance.Namespace, Name: "test"}, nodes, func(master *kvrocks.Node) error {
+				assert.Equal(0, master.PodIndex)
+				switchover = true
+				return nil
+			})
+			assert.NoError(err)
+			assert.Equal(test.expPending, pending)
+			assert.Equal(test.expSwitchover, switchover)
+			for i := range test.podHashes {
+				var pod corev1.Pod
+				err = fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: instance.Namespace, Name: nodes[i].IP}, &pod)
+				if contains(test.expDeleted, nodes[i].IP) {
+					assert.True(k8serrors.IsNotFound(err))
+					continue
+				}
+				assert.NoError(err)
+				if contains(test.expStamped, pod.Name) {
+					assert.Equal(hash, pod.Annotations[resources.ConfigHash])
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateKVRocksConfigMap(t *testing.T) {
+	changed := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name       string
+		upgraded   bool
+		config     map[string]string
+		expChanged bool
+		expTime    string
+	}{
+		{
+			name:       "The hash time should be kept if only the online changeable configs are changed.",
+			config:     map[string]string{"workers": "8", "maxclients": "100"},
+			expChanged: false,
+		}, {
+			name:       "The hash time should be updated if the restart-required configs are changed.",
+			config:     map[string]string{"workers": "16"},
+			expChanged: true,
+		}, {
+			name:       "The running pods should be taken as started with the configs of a configMap without hash.",
+			upgraded:   true,
+			config:     map[string]string{"workers": "8"},
+			expChanged: true,
+			expTime:    time.Unix(0, 0).UTC().Format(time.RFC3339),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			instance := newTestInstance()
+			instance.Spec.KVRocksConfig = map[string]string{"workers": "8"}
+			cm := resources.NewKVRocksConfigMap(instance)
+			cm.Annotations[resources.ConfigHashTime] = changed
+			if test.upgraded {
+				cm.Annotations = nil
+			}
+			h, fakeClient, _ := newTestHandler(instance, newFakeKVRocks(), cm)
+
+			instance.Spec.KVRocksConfig = test.config
+			assert.NoError(h.UpdateKVRocksConfigMap())
+			var newCM corev1.ConfigMap
+			assert.NoError(fakeClient.Get(context.TODO(), k8sApiClient.ObjectKeyFromObject(cm), &newCM))
+			assert.Equal(resources.RestartConfigHash(resources.GetKVRocksConfig(instance)), newCM.Annotations[resources.ConfigHash])
+			assert.Equal(test.expChanged, newCM.Annotations[resources.ConfigHashTime] != changed)
+			if test.expTime != "" {
+				assert.Equal(test.expTime, newCM.Annotations[resources.ConfigHashTime])
+			}
+		})
+	}
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -81,3 +81,32 @@ func (h *CommandHandler) GetSentinel(key types.NamespacedName) (*corev1.PodList,
 	}
 	return sentinelPods, &sentinel.Spec.Password, false, nil
 }
+
+// FailoverMonitor asks a sentinel of the kvrocks to fail over its master, it returns the sentinel which is asked
+func (h *CommandHandler) FailoverMonitor(index ...int) (string, error) {
+	key := types.NamespacedName{
+		Namespace: h.instance.Namespace,
+		Name:      h.instance.Labels[resources.MonitoredBy],
+	}
+	sentinelPods, sentinelPassword, requeue, err := h.GetSentinel(key)
+	if err != nil {
+		return "", err
+	}
+	if requeue {
+		return "", NewOperationError("sentinel %s is not running", key.Name)
+	}
+	_, masterName := resources.ParseRedisName(h.instance.Name)
+	if len(index) != 0 {
+		masterName = fmt.Sprintf("%s-%d", masterName, index[0])
+	}
+	for _, sentinel := range sentinelPods.Items {
+		if sentinel.Status.PodIP == "" {
+			continue
+		}
+		if err = h.kvrocks.FailoverMonitor(sentinel.Status.PodIP, *sentinelPassword, masterName); err != nil {
+			return "", err
+		}
+		return sentinel.Name, nil
+	}
+	return "", NewOperationError("no sentinel of %s is running", key.Name)
+}
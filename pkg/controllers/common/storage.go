@@ -0,0 +1,241 @@
+package common
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+// EnsureStorage expands the pvcs of the statefulSet when spec.storage.size grows, and recreates the statefulSet with
+// orphan pods after all pvcs are expanded so that new pods get the new size. It returns true while waiting for the resize
+func (h *CommandHandler) EnsureStorage(key types.NamespacedName) ([]kvrocksv1alpha1.KVRocksVolumeStatus, bool, error) {
+	sts, err := h.workload.GetStatefulSet(key)
+	if err != nil {
+		return nil, false, err
+	}
+	if sts.DeletionTimestamp != nil {
+		return nil, true, nil
+	}
+	if len(sts.Spec.VolumeClaimTemplates) == 0 {
+		return nil, false, nil
+	}
+	size := resources.GetStorageSize(h.instance)
+	current := sts.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage]
+	// volumes can not be shrunk
+	if size.Cmp(current) <= 0 {
+		return nil, false, nil
+	}
+	pvcList, err := h.workload.ListStatefulSetPVC(key)
+	if err != nil {
+		return nil, false, err
+	}
+	sort.Slice(pvcList.Items, func(i, j int) bool {
+		return pvcList.Items[i].Name < pvcList.Items[j].Name
+	})
+	var statuses []kvrocksv1alpha1.KVRocksVolumeStatus
+	resizing, unsupported := false, false
+	for i := range pvcList.Items {
+		pvc := &pvcList.Items[i]
+		capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+		status := kvrocksv1alpha1.KVRocksVolumeStatus{
+			Pod:      strings.TrimPrefix(pvc.Name, "data-"),
+			Capacity: capacity.String(),
+		}
+		request := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		if request.Cmp(size) < 0 {
+			expandable, err := h.allowVolumeExpansion(pvc.Spec.StorageClassName)
+			if err != nil {
+				return nil, false, err
+			}
+			if !expandable {
+				unsupported = true
+				status.Message = "storage class does not allow volume expansion"
+				statuses = append(statuses, status)
+				continue
+			}
+			pvc.Spec.Resources.Requests[corev1.ResourceStorage] = size
+			if err = h.k8s.UpdatePVC(pvc); err != nil {
+				return nil, false, err
+			}
+		}
+		if capacity.Cmp(size) < 0 {
+			resizing = true
+			status.Resizing = true
+			status.Message = "waiting for volume resize"
+			for _, condition := range pvc.Status.Conditions {
+				if condition.Type == corev1.PersistentVolumeClaimFileSystemResizePending && condition.Status == corev1.ConditionTrue {
+					status.Message = "waiting for file system resize"
+				}
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	if unsupported || resizing {
+		return statuses, resizing, nil
+	}
+	// all pvcs are expanded, volumeClaimTemplates are immutable so the statefulSet is recreated
+	if err = h.workload.DeleteStatefulSetOrphan(sts); err != nil {
+		return nil, false, err
+	}
+	h.kvrocks.Logger().Info("recreate statefulSet to update volumeClaimTemplates", "statefulSet", key.Name, "size", size.String())
+	return statuses, true, nil
+}
+
+func (h *CommandHandler) allowVolumeExpansion(className *string) (bool, error) {
+	if className == nil || *className == "" {
+		class, err := h.k8s.GetDefaultStorageClass()
+		if err != nil || class == nil {
+			return false, err
+		}
+		return class.AllowVolumeExpansion != nil && *class.AllowVolumeExpansion, nil
+	}
+	class, err := h.k8s.GetStorageClass(*className)
+	if err != nil {
+		return false, err
+	}
+	return class.AllowVolumeExpansion != nil && *class.AllowVolumeExpansion, nil
+}
+
+// UpdateStorageStatus sets the StorageResizing condition and the volumes status
+func (h *CommandHandler) UpdateStorageStatus(statuses []kvrocksv1alpha1.KVRocksVolumeStatus) error {
+	var resizing, unsupported []string
+	for _, status := range statuses {
+		if status.Resizing {
+			resizing = append(resizing, status.Pod)
+		} else if status.Message != "" {
+			unsupported = append(unsupported, status.Pod)
+		}
+	}
+	changed := false
+	switch {
+	case len(unsupported) != 0:
+		changed = SetCondition(h.instance, kvrocksv1alpha1.ConditionStorageResizing, metav1.ConditionFalse,
+			"ExpansionNotSupported", "storage class does not allow volume expansion for "+strings.Join(unsupported, ","))
+	case len(resizing) != 0:
+		changed = SetCondition(h.instance, kvrocksv1alpha1.ConditionStorageResizing, metav1.ConditionTrue,
+			"Resizing", "expanding volumes of "+strings.Join(resizing, ","))
+	case len(statuses) != 0:
+		changed = SetCondition(h.instance, kvrocksv1alpha1.ConditionStorageResizing, metav1.ConditionTrue,
+			"Recreating", "volumes are expanded, recreating statefulSet")
+	default:
+		if meta.FindStatusCondition(h.instance.Status.Conditions, kvrocksv1alpha1.ConditionStorageResizing) != nil {
+			changed = SetCondition(h.instance, kvrocksv1alpha1.ConditionStorageResizing, metav1.ConditionFalse,
+				"Resized", "all volumes have the size in spec")
+		}
+	}
+	if !reflect.DeepEqual(h.instance.Status.Volumes, statuses) {
+		h.instance.Status.Volumes = statuses
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return h.k8s.UpdateKVRocks(h.instance)
+}
+
+const defaultAutoScaleThreshold = 80
+
+// EnsureStorageAutoScale grows the storage size by the autoscale step when the disk usage of any node reaches the
+// threshold, the volumes are then expanded by EnsureStorage
+func (h *CommandHandler) EnsureStorageAutoScale(nodes []*kvrocks.Node) error {
+	if h.instance.Spec.Storage == nil || h.instance.Spec.Storage.AutoScale == nil {
+		return nil
+	}
+	autoScale := h.instance.Spec.Storage.AutoScale
+	threshold := int(autoScale.ThresholdPercent)
+	if threshold == 0 {
+		threshold = defaultAutoScaleThreshold
+	}
+	// an unreachable node is skipped, the decision is made from the nodes that answered
+	usedPercent, usedIP, answered := 0, "", 0
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		usage, err := h.kvrocks.GetDiskUsage(node.IP, h.password)
+		if err != nil {
+			h.kvrocks.Logger().Info("skip the disk usage of an unreachable node", "node", node.IP, "reason", err.Error())
+			continue
+		}
+		answered++
+		if usage.UsedPercent >= usedPercent {
+			usedPercent, usedIP = usage.UsedPercent, node.IP
+		}
+	}
+	if answered == 0 {
+		return nil
+	}
+	size := resources.GetStorageSize(h.instance)
+	limit := h.storageLimit(threshold)
+	changed := false
+	switch {
+	case usedPercent >= threshold && size.Cmp(limit) >= 0:
+		changed = SetCondition(h.instance, kvrocksv1alpha1.ConditionStorageMaxSizeReached, metav1.ConditionTrue, "MaxSizeReached",
+			fmt.Sprintf("disk usage reaches %d%% at the max storage size %s", threshold, limit.String()))
+		if changed {
+			h.Eventf(corev1.EventTypeWarning, ReasonStorageMaxSize, "disk usage of %s reaches %d%% at the max storage size %s", usedIP, usedPercent, limit.String())
+		}
+	case usedPercent >= threshold:
+		// the size is not grown until the last expansion is done
+		resizing := meta.FindStatusCondition(h.instance.Status.Conditions, kvrocksv1alpha1.ConditionStorageResizing)
+		if resizing != nil && resizing.Status == metav1.ConditionTrue {
+			return nil
+		}
+		if resizing != nil && resizing.Reason == "ExpansionNotSupported" {
+			h.Eventf(corev1.EventTypeWarning, ReasonStorageAutoScaleBlocked, "disk usage of %s reaches %d%%, but %s", usedIP, usedPercent, resizing.Message)
+			return nil
+		}
+		next := size.DeepCopy()
+		next.Add(autoScale.Step)
+		if next.Cmp(limit) > 0 {
+			next = limit
+		}
+		h.Eventf(corev1.EventTypeNormal, ReasonStorageAutoScaled, "disk usage of %s reaches %d%%, expand storage from %s to %s", usedIP, usedPercent, size.String(), next.String())
+		h.instance.Status.StorageSize = &next
+		changed = true
+		if next.Cmp(limit) >= 0 {
+			h.Eventf(corev1.EventTypeWarning, ReasonStorageMaxSize, "storage is expanded to the max size %s", next.String())
+			SetCondition(h.instance, kvrocksv1alpha1.ConditionStorageMaxSizeReached, metav1.ConditionFalse, "ApproachingMaxSize",
+				fmt.Sprintf("storage reaches the max size %s", limit.String()))
+		} else {
+			SetCondition(h.instance, kvrocksv1alpha1.ConditionStorageMaxSizeReached, metav1.ConditionFalse, "BelowMaxSize",
+				fmt.Sprintf("storage can be expanded up to %s", limit.String()))
+		}
+	case size.Cmp(limit) < 0:
+		changed = SetCondition(h.instance, kvrocksv1alpha1.ConditionStorageMaxSizeReached, metav1.ConditionFalse, "BelowMaxSize",
+			fmt.Sprintf("storage can be expanded up to %s", limit.String()))
+	default:
+		changed = SetCondition(h.instance, kvrocksv1alpha1.ConditionStorageMaxSizeReached, metav1.ConditionFalse, "ApproachingMaxSize",
+			fmt.Sprintf("storage reaches the max size %s", limit.String()))
+	}
+	if !changed {
+		return nil
+	}
+	return h.k8s.UpdateKVRocks(h.instance)
+}
+
+// storageLimit returns the max size of the autoscale. If max-db-size is set, the volume does not need to grow once
+// a full db stays below the threshold
+func (h *CommandHandler) storageLimit(threshold int) resource.Quantity {
+	limit := h.instance.Spec.Storage.AutoScale.MaxSize.DeepCopy()
+	maxDBSize, _ := strconv.ParseInt(resources.GetKVRocksConfig(h.instance)["max-db-size"], 10, 64)
+	if maxDBSize > 0 {
+		dbLimit := resource.NewQuantity(maxDBSize*(1<<30)*100/int64(threshold), resource.BinarySI)
+		if dbLimit.Cmp(limit) < 0 {
+			limit = *dbLimit
+		}
+	}
+	return limit
+}
@@ -0,0 +1,295 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8sApiClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+)
+
+func newTestPVC(instance *kvrocksv1alpha1.KVRocks, name, class, request, capacity string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: instance.Namespace,
+			Labels:    map[string]string{"kvrocks/statefulset": "test"},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &class,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(request)},
+			},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{
+			Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(capacity)},
+		},
+	}
+}
+
+func TestEnsureStorage(t *testing.T) {
+	expandable, fixed := true, false
+
+	tests := []struct {
+		name         string
+		size         string
+		class        *bool
+		pvcs         [][]string
+		expStatuses  []kvrocksv1alpha1.KVRocksVolumeStatus
+		expWaiting   bool
+		expRequests  []string
+		expRecreated bool
+	}{
+		{
+			name:        "Nothing should be done if the size is not increased.",
+			size:        "10Gi",
+			class:       &expandable,
+			pvcs:        [][]string{{"10Gi", "10Gi"}},
+			expRequests: []string{"10Gi"},
+		}, {
+			name:  "The pvcs should be expanded if the class allows expansion.",
+			size:  "20Gi",
+			class: &expandable,
+			pvcs:  [][]string{{"10Gi", "10Gi"}, {"20Gi", "20Gi"}},
+			expStatuses: []kvrocksv1alpha1.KVRocksVolumeStatus{
+				{Pod: "test-0", Capacity: "10Gi", Resizing: true, Message: "waiting for volume resize"},
+				{Pod: "test-1", Capacity: "20Gi"},
+			},
+			expWaiting:  true,
+			expRequests: []string{"20Gi", "20Gi"},
+		}, {
+			name:  "The pvcs should not be expanded if the class does not allow expansion.",
+			size:  "20Gi",
+			class: &fixed,
+			pvcs:  [][]string{{"10Gi", "10Gi"}},
+			expStatuses: []kvrocksv1alpha1.KVRocksVolumeStatus{
+				{Pod: "test-0", Capacity: "10Gi", Message: "storage class does not allow volume expansion"},
+			},
+			expRequests: []string{"10Gi"},
+		}, {
+			name:  "The statefulSet should be recreated once all pvcs are expanded.",
+			size:  "20Gi",
+			class: &expandable,
+			pvcs:  [][]string{{"20Gi", "20Gi"}},
+			expStatuses: []kvrocksv1alpha1.KVRocksVolumeStatus{
+				{Pod: "test-0", Capacity: "20Gi"},
+			},
+			expWaiting:   true,
+			expRequests:  []string{"20Gi"},
+			expRecreated: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			instance := newTestInstance()
+			instance.Spec.Storage = &kvrocksv1alpha1.KVRocksStorage{Size: resource.MustParse(test.size), Class: "standard"}
+			sts := newTestStatefulSet(instance, "test")
+			sts.Spec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{*newTestPVC(instance, "data", "standard", "10Gi", "10Gi")}
+			objs := []k8sApiClient.Object{sts, &storagev1.StorageClass{
+				ObjectMeta:           metav1.ObjectMeta{Name: "standard"},
+				AllowVolumeExpansion: test.class,
+			}}
+			var names []string
+			for i, pvc := range test.pvcs {
+				name := fmt.Sprintf("data-test-%d", i)
+				names = append(names, name)
+				objs = append(objs, newTestPVC(instance, name, "standard", pvc[0], pvc[1]))
+			}
+			h, fakeClient, _ := newTestHandler(instance, newFakeKVRocks(), objs...)
+
+			statuses, waiting, err := h.EnsureStorage(types.NamespacedName{Namespace: instance.Namespace, Name: "test"})
+			assert.NoError(err)
+			assert.Equal(test.expStatuses, statuses)
+			assert.Equal(test.expWaiting, waiting)
+			for i, name := range names {
+				var pvc corev1.PersistentVolumeClaim
+				assert.NoError(fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: instance.Namespace, Name: name}, &pvc))
+				request := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+				assert.Equal(test.expRequests[i], request.String())
+			}
+			err = fakeClient.Get(context.TODO(), k8sApiClient.ObjectKeyFromObject(sts), sts)
+			assert.Equal(test.expRecreated, k8serrors.IsNotFound(err))
+		})
+	}
+}
+
+func TestUpdateStorageStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		existing  bool
+		statuses  []kvrocksv1alpha1.KVRocksVolumeStatus
+		expStatus metav1.ConditionStatus
+		expReason string
+	}{
+		{
+			name:      "Unsupported expansion should be reported.",
+			statuses:  []kvrocksv1alpha1.KVRocksVolumeStatus{{Pod: "test-0", Message: "storage class does not allow volume expansion"}},
+			expStatus: metav1.ConditionFalse,
+			expReason: "ExpansionNotSupported",
+		}, {
+			name:      "Resizing volumes should be reported.",
+			statuses:  []kvrocksv1alpha1.KVRocksVolumeStatus{{Pod: "test-0", Resizing: true}},
+			expStatus: metav1.ConditionTrue,
+			expReason: "Resizing",
+		}, {
+			name:      "Expanded volumes should be reported as recreating the statefulSet.",
+			statuses:  []kvrocksv1alpha1.KVRocksVolumeStatus{{Pod: "test-0"}},
+			expStatus: metav1.ConditionTrue,
+			expReason: "Recreating",
+		}, {
+			name:      "The condition should be resized once nothing is left.",
+			existing:  true,
+			expStatus: metav1.ConditionFalse,
+			expReason: "Resized",
+		}, {
+			name: "No condition should be added if the storage is never resized.",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			instance := newTestInstance()
+			if test.existing {
+				SetCondition(instance, kvrocksv1alpha1.ConditionStorageResizing, metav1.ConditionTrue, "Recreating", "")
+			}
+			h, _, _ := newTestHandler(instance, newFakeKVRocks())
+
+			assert.NoError(h.UpdateStorageStatus(test.statuses))
+			condition := meta.FindStatusCondition(instance.Status.Conditions, kvrocksv1alpha1.ConditionStorageResizing)
+			if test.expReason == "" {
+				assert.Nil(condition)
+				return
+			}
+			if assert.NotNil(condition) {
+				assert.Equal(test.expStatus, condition.Status)
+				assert.Equal(test.expReason, condition.Reason)
+			}
+			assert.Equal(test.statuses, instance.Status.Volumes)
+		})
+	}
+}
+
+func TestEnsureStorageAutoScale(t *testing.T) {
+	tests := []struct {
+		name         string
+		size         string
+		usedPercent  int
+		down         []string
+		resizing     *metav1.Condition
+		expSize      string
+		expCondition string
+		expEvents    []string
+	}{
+		{
+			name:         "The size should not grow below the threshold.",
+			size:         "10Gi",
+			usedPercent:  50,
+			expSize:      "10Gi",
+			expCondition: "BelowMaxSize",
+		}, {
+			name:         "The size should grow by the step at the threshold.",
+			size:         "10Gi",
+			usedPercent:  85,
+			expSize:      "15Gi",
+			expCondition: "BelowMaxSize",
+			expEvents:    []string{"Normal StorageAutoScaled disk usage of 10.0.0.2 reaches 85%, expand storage from 10Gi to 15Gi"},
+		}, {
+			name:         "The size should grow up to the max size with a warning.",
+			size:         "18Gi",
+			usedPercent:  85,
+			expSize:      "20Gi",
+			expCondition: "ApproachingMaxSize",
+			expEvents: []string{
+				"Normal StorageAutoScaled disk usage of 10.0.0.2 reaches 85%, expand storage from 18Gi to 20Gi",
+				"Warning StorageMaxSize storage is expanded to the max size 20Gi",
+			},
+		}, {
+			name:         "Reaching the threshold at the max size should be warned.",
+			size:         "20Gi",
+			usedPercent:  85,
+			expSize:      "20Gi",
+			expCondition: "MaxSizeReached",
+			expEvents:    []string{"Warning StorageMaxSize disk usage of 10.0.0.2 reaches 85% at the max storage size 20Gi"},
+		}, {
+			name:        "The size should not grow while the volumes are resizing.",
+			size:        "10Gi",
+			usedPercent: 85,
+			resizing:    &metav1.Condition{Status: metav1.ConditionTrue, Reason: "Resizing"},
+			expSize:     "10Gi",
+		}, {
+			name:        "The size should not grow if the volumes can not be expanded.",
+			size:        "10Gi",
+			usedPercent: 85,
+			resizing:    &metav1.Condition{Status: metav1.ConditionFalse, Reason: "ExpansionNotSupported", Message: "storage class does not allow volume expansion for test-0"},
+			expSize:     "10Gi",
+			expEvents:   []string{"Warning StorageAutoScaleBlocked disk usage of 10.0.0.2 reaches 85%, but storage class does not allow volume expansion for test-0"},
+		}, {
+			name:         "An unreachable node should be skipped and the size decided from the others.",
+			size:         "10Gi",
+			usedPercent:  85,
+			down:         []string{"10.0.0.1"},
+			expSize:      "15Gi",
+			expCondition: "BelowMaxSize",
+			expEvents:    []string{"Normal StorageAutoScaled disk usage of 10.0.0.2 reaches 85%, expand storage from 10Gi to 15Gi"},
+		}, {
+			name:        "Nothing should be decided if no node answers.",
+			size:        "10Gi",
+			usedPercent: 85,
+			down:        []string{"10.0.0.1", "10.0.0.2"},
+			expSize:     "10Gi",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			instance := newTestInstance()
+			instance.Spec.Storage = &kvrocksv1alpha1.KVRocksStorage{
+				Size: resource.MustParse("10Gi"),
+				AutoScale: &kvrocksv1alpha1.KVRocksStorageAutoScale{
+					Step:    resource.MustParse("5Gi"),
+					MaxSize: resource.MustParse("20Gi"),
+				},
+			}
+			size := resource.MustParse(test.size)
+			instance.Status.StorageSize = &size
+			if test.resizing != nil {
+				SetCondition(instance, kvrocksv1alpha1.ConditionStorageResizing, test.resizing.Status, test.resizing.Reason, test.resizing.Message)
+			}
+			kvClient := newFakeKVRocks()
+			kvClient.usedPercent["10.0.0.1"] = 30
+			kvClient.usedPercent["10.0.0.2"] = test.usedPercent
+			for _, ip := range test.down {
+				kvClient.down[ip] = true
+			}
+			h, _, recorder := newTestHandler(instance, kvClient)
+
+			assert.NoError(h.EnsureStorageAutoScale([]*kvrocks.Node{{IP: "10.0.0.1"}, {IP: "10.0.0.2"}}))
+			assert.Equal(test.expSize, instance.Status.StorageSize.String())
+			condition := meta.FindStatusCondition(instance.Status.Conditions, kvrocksv1alpha1.ConditionStorageMaxSizeReached)
+			if test.expCondition == "" {
+				assert.Nil(condition)
+			} else if assert.NotNil(condition) {
+				assert.Equal(test.expCondition, condition.Reason)
+			}
+			assert.Equal(test.expEvents, drainEvents(recorder))
+		})
+	}
+}
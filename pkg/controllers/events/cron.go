@@ -7,6 +7,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 
 	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/metrics"
 	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
 )
 
@@ -41,6 +42,7 @@ func (e *event) producer() {
 					key:      key.String(),
 					systemId: systemId,
 				})
+				metrics.SentinelSubscriptions.Set(float64(len(e.producerSentinels)))
 			}
 			e.lock.Unlock()
 		}
@@ -8,10 +8,12 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/robfig/cron/v3"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 
 	"github.com/RocksLabs/kvrocks-operator/pkg/client/controller"
 	"github.com/RocksLabs/kvrocks-operator/pkg/client/k8s"
 	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/metrics"
 )
 
 type eventMessage struct {
@@ -47,9 +49,10 @@ type event struct {
 	kvrocks           kvrocks.Client
 	controller        *controller.Client
 	log               logr.Logger
+	recorder          record.EventRecorder
 }
 
-func NewEvent(k8s *k8s.Client, kvrocks kvrocks.Client, controller *controller.Client, log logr.Logger) *event {
+func NewEvent(k8s *k8s.Client, kvrocks kvrocks.Client, controller *controller.Client, log logr.Logger, recorder record.EventRecorder) *event {
 	return &event{
 		k8s:               k8s,
 		kvrocks:           kvrocks,
@@ -57,6 +60,7 @@ func NewEvent(k8s *k8s.Client, kvrocks kvrocks.Client, controller *controller.Cl
 		messages:          message,
 		producerSentinels: map[string]func(msg *produceMessage){},
 		log:               log,
+		recorder:          recorder,
 	}
 }
 
@@ -72,6 +76,7 @@ func (m *messageQueue) add(msg *eventMessage) {
 	if _, ok := m.keys[msg.ip]; !ok {
 		m.keys[msg.ip] = struct{}{}
 		m.message <- msg
+		metrics.EventQueueDepth.Set(float64(len(m.message)))
 	}
 }
 
@@ -6,11 +6,13 @@ import (
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 
 	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
 	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
 	"github.com/RocksLabs/kvrocks-operator/pkg/controllers/common"
+	"github.com/RocksLabs/kvrocks-operator/pkg/metrics"
 	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
 )
 
@@ -49,11 +51,13 @@ func (e *event) listen(pubsub *redis.PubSub, systemId, namespaceName string) {
 	// chan done, pusub exits
 	e.lock.Lock()
 	delete(e.producerSentinels, namespaceName)
+	metrics.SentinelSubscriptions.Set(float64(len(e.producerSentinels)))
 	defer e.lock.Unlock()
 }
 
 func (e *event) consumer() {
 	for msg := range e.messages.message {
+		metrics.EventQueueDepth.Set(float64(len(e.messages.message)))
 		go e.handleFailover(msg)
 	}
 }
@@ -64,13 +68,22 @@ func (e *event) handleFailover(msg *eventMessage) {
 	var err error
 	var instance *kvrocksv1alpha1.KVRocks
 	requeue := true
+	// result is empty if the message is ignored
+	result := ""
 
 	// if handle failover error, msg requeue
 	defer func() {
 		if err != nil && time.Since(msg.timeout) < 0 && requeue {
 			e.messages.message <- msg
+			metrics.EventQueueDepth.Set(float64(len(e.messages.message)))
 			return
 		}
+		if err != nil && result != "" {
+			result = metrics.FailoverFailed
+		}
+		if result != "" {
+			metrics.Failovers.WithLabelValues(msg.key.Namespace, msg.key.Name, result).Inc()
+		}
 		delete(e.messages.keys, msg.ip)
 		e.log.Info("failover successfully", "instance", msg.key, "partition", msg.partition)
 	}()
@@ -83,22 +96,40 @@ func (e *event) handleFailover(msg *eventMessage) {
 		requeue = false
 		return
 	}
-	commHandler := common.NewCommandHandler(instance, e.k8s, e.kvrocks, instance.Spec.Password)
+	// the failover is left to the admin while the instance is paused or under maintenance
+	if resources.IsPaused(instance) || resources.IsUnderMaintenance(instance) {
+		requeue = false
+		result = metrics.FailoverSuppressed
+		e.log.Info("failover is suppressed", "instance", msg.key, "partition", msg.partition)
+		e.recorder.Eventf(instance, corev1.EventTypeWarning, common.ReasonFailoverSuppressed, "failover of shard %d from %s is suppressed", msg.partition, msg.ip)
+		return
+	}
+	history := common.NewOperationHistory(e.k8s, instance)
+	defer func() {
+		if err := history.Flush(); err != nil {
+			e.log.Error(err, "update operation history failed", "instance", msg.key)
+		}
+	}()
+	controller := e.controller.WithRecorder(history.Record)
+	commHandler := common.NewCommandHandler(instance, e.k8s, kvrocks.NewRecordingClient(e.kvrocks, history.Record), instance.Spec.Password, e.recorder)
+	result = metrics.FailoverHandled
 
-	err = e.controller.SetEndPoint(instance.Namespace, e.k8s)
+	err = controller.SetEndPoint(instance.Namespace, e.k8s)
 	if err != nil {
 		e.log.Error(err, "set endpoint failed", "instance", msg.key, "partition", msg.partition)
 		return
 	}
 
 	// handle failover shard
-	err = e.controller.FailoverShard(msg.partition)
+	err = controller.FailoverShard(msg.partition)
 	if err != nil {
 		e.log.Error(err, "failover shard failed", "instance", msg.key, "partition", msg.partition)
+		commHandler.Eventf(corev1.EventTypeWarning, common.ReasonFailoverFailed, "failover shard %d from %s failed: %v", msg.partition, msg.ip, err)
 		instance.Status = kvrocksv1alpha1.KVRocksStatus{
 			Status: kvrocksv1alpha1.StatusFailed,
 			Reason: ErrNoSuitableSlaver,
 		}
+		result = metrics.FailoverFailed
 		if err = e.k8s.UpdateKVRocks(instance); err == nil {
 			requeue = false
 		}
@@ -124,25 +155,27 @@ func (e *event) handleFailover(msg *eventMessage) {
 	}
 
 	// update topology
-	shardData, err := e.controller.GetNodes(msg.partition)
+	shardData, err := controller.GetNodes(msg.partition)
 	if err != nil {
 		return
 	}
 
 	// find new masterID
-	masterID := ""
+	masterID, masterAddr := "", ""
 	for _, node := range shardData.Nodes {
 		if node.Role == kvrocks.RoleMaster {
-			masterID = node.ID
+			masterID, masterAddr = node.ID, node.Addr
 			break
 		}
 	}
 	if masterID == "" {
 		e.log.Error(err, "no master found in instance", msg.key, "partition", msg.partition)
+		commHandler.Eventf(corev1.EventTypeWarning, common.ReasonFailoverFailed, "no master found in shard %d after failover", msg.partition)
 		instance.Status = kvrocksv1alpha1.KVRocksStatus{
 			Status: kvrocksv1alpha1.StatusFailed,
 			Reason: ErrNoMaster,
 		}
+		result = metrics.FailoverFailed
 		if err = e.k8s.UpdateKVRocks(instance); err == nil {
 			requeue = false
 		}
@@ -168,4 +201,5 @@ func (e *event) handleFailover(msg *eventMessage) {
 	if err != nil {
 		return
 	}
+	commHandler.Eventf(corev1.EventTypeNormal, common.ReasonFailover, "shard %d is failed over from %s, the new master is %s", msg.partition, msg.ip, masterAddr)
 }
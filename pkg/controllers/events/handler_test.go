@@ -0,0 +1,210 @@
+package events
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sApiClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/controller"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/k8s"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/metrics"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+// roundTripper serves the requests of the default http transport by the handler
+type roundTripper struct {
+	handler http.Handler
+}
+
+func (r *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorder := httptest.NewRecorder()
+	r.handler.ServeHTTP(recorder, req)
+	return recorder.Result(), nil
+}
+
+// withController serves the requests of the kvrocks controller by the handler during the test
+func withController(t *testing.T, handler http.HandlerFunc) {
+	transport := http.DefaultTransport
+	http.DefaultTransport = &roundTripper{handler: handler}
+	t.Cleanup(func() {
+		http.DefaultTransport = transport
+	})
+}
+
+func newTestInstance() *kvrocksv1alpha1.KVRocks {
+	return &kvrocksv1alpha1.KVRocks{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "unit-test",
+		},
+		Spec: kvrocksv1alpha1.KVRocksSpec{
+			Type: kvrocksv1alpha1.ClusterType,
+		},
+		Status: kvrocksv1alpha1.KVRocksStatus{
+			Status: kvrocksv1alpha1.StatusRunning,
+			Topo: []kvrocksv1alpha1.KVRocksTopoPartitions{{
+				PartitionName: "test-0",
+				Topology: []kvrocksv1alpha1.KVRocksTopology{
+					{Pod: "test-0-0", Role: kvrocks.RoleMaster, NodeId: "node-0", Ip: "10.0.0.1"},
+					{Pod: "test-0-1", Role: kvrocks.RoleSlaver, NodeId: "node-1", Ip: "10.0.0.2", MasterId: "node-0"},
+				},
+			}},
+		},
+	}
+}
+
+func newTestEvent(instance *kvrocksv1alpha1.KVRocks) (*event, k8sApiClient.Client, *record.FakeRecorder) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = kvrocksv1alpha1.AddToScheme(scheme)
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: kvrocks.ControllerServiceName, Namespace: instance.Namespace},
+		Spec:       corev1.ServiceSpec{ClusterIP: "10.96.0.1"},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(instance, service).Build()
+	recorder := record.NewFakeRecorder(100)
+	log := ctrl.Log.WithName("events-test")
+	e := NewEvent(k8s.NewK8sClient(fakeClient, log), nil, controller.NewClient(log), log, recorder)
+	e.messages = &messageQueue{
+		message: make(chan *eventMessage, 10),
+		keys:    map[string]struct{}{},
+	}
+	return e, fakeClient, recorder
+}
+
+func drainEvents(recorder *record.FakeRecorder) []string {
+	var events []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestHandleFailover(t *testing.T) {
+	newShard := `{"data":{"shard":{"nodes":[{"id":"node-0","addr":"10.0.0.1:6379","role":"slave"},{"id":"node-1","addr":"10.0.0.2:6379","role":"master"}]}}}`
+	noMasterShard := `{"data":{"shard":{"nodes":[{"id":"node-0","addr":"10.0.0.1:6379","role":"slave"},{"id":"node-1","addr":"10.0.0.2:6379","role":"slave"}]}}}`
+
+	tests := []struct {
+		name         string
+		failoverCode int
+		shard        string
+		expStatus    kvrocksv1alpha1.KVRocksStatusType
+		expReason    string
+		expTopology  []kvrocksv1alpha1.KVRocksTopology
+		expEvents    []string
+	}{
+		{
+			name:         "A handled failover should update the topology and record the new master.",
+			failoverCode: http.StatusOK,
+			shard:        newShard,
+			expStatus:    kvrocksv1alpha1.StatusRunning,
+			expTopology: []kvrocksv1alpha1.KVRocksTopology{
+				{Pod: "test-0-0", Role: kvrocks.RoleSlaver, NodeId: "node-0", Ip: "10.0.0.1", MasterId: "node-1", Failover: true},
+				{Pod: "test-0-1", Role: kvrocks.RoleMaster, NodeId: "node-1", Ip: "10.0.0.2", MasterId: "node-0"},
+			},
+			expEvents: []string{"Normal Failover shard 0 is failed over from 10.0.0.1, the new master is 10.0.0.2:6379"},
+		}, {
+			name:         "A failed failover should fail the kvrocks with a warning.",
+			failoverCode: http.StatusInternalServerError,
+			expStatus:    kvrocksv1alpha1.StatusFailed,
+			expReason:    ErrNoSuitableSlaver,
+			expEvents:    []string{"Warning FailoverFailed failover shard 0 from 10.0.0.1 failed: unexpected response status code: 500"},
+		}, {
+			name:         "A shard without master after failover should fail the kvrocks with a warning.",
+			failoverCode: http.StatusOK,
+			shard:        noMasterShard,
+			expStatus:    kvrocksv1alpha1.StatusFailed,
+			expReason:    ErrNoMaster,
+			expEvents:    []string{"Warning FailoverFailed no master found in shard 0 after failover"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			withController(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPost {
+					w.WriteHeader(test.failoverCode)
+					return
+				}
+				_, _ = w.Write([]byte(test.shard))
+			})
+			instance := newTestInstance()
+			e, fakeClient, recorder := newTestEvent(instance)
+
+			key := types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name}
+			e.handleFailover(&eventMessage{ip: "10.0.0.1", port: "6379", key: key, partition: 0, timeout: time.Now()})
+			assert.Empty(e.messages.message)
+			assert.NoError(fakeClient.Get(context.TODO(), key, instance))
+			assert.Equal(test.expStatus, instance.Status.Status)
+			assert.Equal(test.expReason, instance.Status.Reason)
+			if test.expTopology != nil {
+				assert.Equal(test.expTopology, instance.Status.Topo[0].Topology)
+			}
+			assert.Equal(test.expEvents, drainEvents(recorder))
+		})
+	}
+}
+
+func TestHandleFailoverSuppressed(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+	}{
+		{
+			name:       "The failover of a paused kvrocks should be suppressed.",
+			annotation: resources.Paused,
+		}, {
+			name:       "The failover of a kvrocks under maintenance should be suppressed.",
+			annotation: resources.Maintenance,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var requests []string
+			withController(t, func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method+" "+r.URL.Path)
+			})
+			instance := newTestInstance()
+			instance.Annotations = map[string]string{test.annotation: "true"}
+			e, fakeClient, recorder := newTestEvent(instance)
+			suppressed := metrics.Failovers.WithLabelValues(instance.Namespace, instance.Name, metrics.FailoverSuppressed)
+			count := testutil.ToFloat64(suppressed)
+
+			key := types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name}
+			// the message is not requeued although it is not timed out
+			e.handleFailover(&eventMessage{ip: "10.0.0.1", port: "6379", key: key, partition: 0, timeout: time.Now().Add(time.Minute)})
+			assert.Empty(e.messages.message)
+			assert.Empty(requests)
+			expInstance := newTestInstance()
+			assert.NoError(fakeClient.Get(context.TODO(), key, instance))
+			assert.Equal(expInstance.Status.Status, instance.Status.Status)
+			assert.Equal(expInstance.Status.Topo, instance.Status.Topo)
+			assert.Equal([]string{"Warning FailoverSuppressed failover of shard 0 from 10.0.0.1 is suppressed"}, drainEvents(recorder))
+			assert.Equal(count+1, testutil.ToFloat64(suppressed))
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 	"sync"
 	"time"
 
@@ -28,20 +29,30 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	k8sApiClient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
 	controllerClient "github.com/RocksLabs/kvrocks-operator/pkg/client/controller"
 	k8s "github.com/RocksLabs/kvrocks-operator/pkg/client/k8s"
 	kv "github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/workload"
 	"github.com/RocksLabs/kvrocks-operator/pkg/controllers/cluster"
+	"github.com/RocksLabs/kvrocks-operator/pkg/controllers/common"
 	"github.com/RocksLabs/kvrocks-operator/pkg/controllers/events"
 	"github.com/RocksLabs/kvrocks-operator/pkg/controllers/sentinel"
 	"github.com/RocksLabs/kvrocks-operator/pkg/controllers/standard"
+	"github.com/RocksLabs/kvrocks-operator/pkg/metrics"
 	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
 )
 
@@ -54,24 +65,32 @@ type KVRocksHandler interface {
 // KVRocksReconciler reconciles a KVRocks object
 type KVRocksReconciler struct {
 	k8sApiClient.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
-	once   sync.Once
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	once     sync.Once
 }
 
 // +kubebuilder:rbac:groups=kvrocks.apache.org,resources=kvrocks,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=kvrocks.apache.org,resources=kvrocks/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=kvrocks.apache.org,resources=kvrocks/finalizers,verbs=update
+// +kubebuilder:rbac:groups=kvrocks.apache.org,resources=kvrocksoperations,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=kvrocks.apache.org,resources=kvrocksoperations/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=core,resources=secret,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=persistentvolumes,verbs=get;list;watch
 // +kubebuilder:rbac:groups=apps.kruise.io,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=podmonitors;prometheusrules,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -90,22 +109,39 @@ func (r *KVRocksReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	instance, err := k8sClient.GetKVRocks(req.NamespacedName)
 	if err != nil {
 		if errors.IsNotFound(err) {
+			metrics.DeleteInstance(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, err
 	}
 	r.once.Do(func() {
-		event := events.NewEvent(k8sClient, kvClient, controllerClient, log)
+		event := events.NewEvent(k8sClient, kvClient, controllerClient, log, r.Recorder)
 		go event.Run()
 	})
+	// the mutating commands are recorded in the operation history of the kvrocks
+	history := common.NewOperationHistory(k8sClient, instance)
+	defer func() {
+		if err := history.Flush(); err != nil {
+			log.Error(err, "update operation history failed")
+		}
+	}()
+	kvClient = kv.NewRecordingClient(kvClient, history.Record)
+	controllerClient = controllerClient.WithRecorder(history.Record)
+	// nothing is changed while the reconciliation is paused, even the deletion waits until it is resumed
+	if resources.IsPaused(instance) {
+		return r.observePaused(instance, log, k8sClient, kvClient)
+	}
+	if err = ensureWorkloadBackend(instance, k8sClient); err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, nil
+	}
 	var handler KVRocksHandler
 	switch instance.Spec.Type {
 	case kvrocksv1alpha1.SentinelType:
-		handler = sentinel.NewKVRocksSentinelHandler(k8sClient, kvClient, log, req.NamespacedName, instance)
+		handler = sentinel.NewKVRocksSentinelHandler(k8sClient, kvClient, log, req.NamespacedName, instance, r.Recorder)
 	case kvrocksv1alpha1.StandardType:
-		handler = standard.NewKVRocksStandardHandler(k8sClient, kvClient, log, req.NamespacedName, instance)
+		handler = standard.NewKVRocksStandardHandler(k8sClient, kvClient, log, req.NamespacedName, instance, r.Recorder)
 	case kvrocksv1alpha1.ClusterType:
-		handler = cluster.NewKVRocksClusterHandler(k8sClient, kvClient, log, req.NamespacedName, instance, controllerClient)
+		handler = cluster.NewKVRocksClusterHandler(k8sClient, kvClient, log, req.NamespacedName, instance, controllerClient, r.Recorder)
 	}
 	// delete
 	if instance.GetDeletionTimestamp() != nil {
@@ -129,19 +165,30 @@ func (r *KVRocksReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	}
 	// if kvrocks status failed ,do nothing
 	if instance.Status.Status == kvrocksv1alpha1.StatusFailed {
+		metrics.RecordInstance(instance)
 		return ctrl.Result{}, nil
 	}
 	// check kvrocks spec is reasonable
 	if err = checkSpecification(instance, log, k8sClient); err != nil {
 		return ctrl.Result{}, nil
 	}
+	// report the configs which will not be applied
+	if err = checkConfig(instance, log, k8sClient); err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, nil
+	}
 	// add labels and init status
 	err = runIfInitialize(instance, log, k8sClient)
 	if err != nil {
 		return ctrl.Result{}, nil
 	}
+	if common.UpdatePauseConditions(instance) {
+		if err = k8sClient.UpdateKVRocks(instance); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, nil
+		}
+	}
 	log.Info("reconcile begin")
 	err, _ = handler.Handle()
+	metrics.RecordInstance(instance)
 	if handler.Requeue() || shouldRetry(err) {
 		return ctrl.Result{RequeueAfter: time.Second * 10}, nil
 	}
@@ -150,22 +197,122 @@ func (r *KVRocksReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	}
 	if err == nil {
 		log.Info("reconcile end")
+		// refresh the observed status such as the replication health
+		return ctrl.Result{RequeueAfter: common.StatusRefreshInterval}, nil
 	}
 	return ctrl.Result{}, err
 }
 
+// observePaused only refreshes the observed status of the paused kvrocks, such as the replication health
+func (r *KVRocksReconciler) observePaused(instance *kvrocksv1alpha1.KVRocks, log logr.Logger, k8sClient *k8s.Client, kvClient kv.Client) (ctrl.Result, error) {
+	log.Info("reconciliation is paused, observe status only")
+	changed := common.UpdatePauseConditions(instance)
+	err := common.NewCommandHandler(instance, k8sClient, kvClient, instance.Spec.Password, r.Recorder).ObserveStatus(changed)
+	metrics.RecordInstance(instance)
+	if shouldRetry(err) {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, nil
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: common.StatusRefreshInterval}, nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *KVRocksReconciler) SetupWithManager(mgr ctrl.Manager, maxConcurrentReconciles int) error {
-	mgr.GetFieldIndexer().IndexField(context.Background(), &corev1.Pod{}, "spec.nodeName", func(o k8sApiClient.Object) []string {
+	mgr.GetFieldIndexer().IndexField(context.Background(), &corev1.Pod{}, k8s.PodNodeNameIndex, func(o k8sApiClient.Object) []string {
 		return []string{o.(*corev1.Pod).Spec.NodeName}
 	})
-	return ctrl.NewControllerManagedBy(mgr).
+	builder := ctrl.NewControllerManagedBy(mgr).
 		WithOptions(controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}).
 		For(&kvrocksv1alpha1.KVRocks{}).
-		Owns(&kruise.StatefulSet{}).
+		Owns(&appsv1.StatefulSet{}).
 		Owns(&corev1.Pod{}).
 		Owns(&appsv1.Deployment{}).
-		Complete(r)
+		Watches(&source.Kind{Type: &corev1.Node{}}, handler.EnqueueRequestsFromMapFunc(r.nodeToKVRocks)).
+		Watches(&source.Kind{Type: &kvrocksv1alpha1.KVRocksOperation{}}, handler.EnqueueRequestsFromMapFunc(operationToKVRocks))
+	// kruise may not be installed if only native statefulSets are used
+	if _, err := mgr.GetRESTMapper().RESTMapping(kruise.SchemeGroupVersion.WithKind("StatefulSet").GroupKind(), kruise.SchemeGroupVersion.Version); err == nil {
+		builder = builder.Owns(&kruise.StatefulSet{})
+	} else {
+		r.Log.Info("kruise statefulSet is not installed, only native statefulSets can be used")
+	}
+	return builder.Complete(r)
+}
+
+// nodeToKVRocks enqueues the kvrocks with pods on the draining or NotReady node, the masters on it are switched over
+// and the pods are replaced if the node is lost
+func (r *KVRocksReconciler) nodeToKVRocks(o k8sApiClient.Object) []reconcile.Request {
+	node := o.(*corev1.Node)
+	if !common.IsNodeDraining(node, nil) && isNodeReady(node) {
+		return nil
+	}
+	pods, err := k8s.NewK8sClient(r.Client, r.Log).ListNodePods(node.Name)
+	if err != nil {
+		r.Log.Error(err, "list pods on node failed", "node", node.Name)
+		return nil
+	}
+	var requests []reconcile.Request
+	seen := map[types.NamespacedName]bool{}
+	for _, pod := range pods.Items {
+		name, ok := pod.Labels["kvrocks/name"]
+		if !ok {
+			continue
+		}
+		key := types.NamespacedName{Namespace: pod.Namespace, Name: name}
+		if !seen[key] {
+			seen[key] = true
+			requests = append(requests, reconcile.Request{NamespacedName: key})
+		}
+	}
+	return requests
+}
+
+// operationToKVRocks enqueues the kvrocks of the unfinished operation, the operations are run by the kvrocks reconciler
+// so that they are serialized with the other changes
+func operationToKVRocks(o k8sApiClient.Object) []reconcile.Request {
+	op := o.(*kvrocksv1alpha1.KVRocksOperation)
+	if op.IsFinished() {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: op.Namespace, Name: op.Spec.Instance}}}
+}
+
+func isNodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// ensureWorkloadBackend records the workload backend in status when the kvrocks is created, so that changing the
+// operator default does not affect the existing kvrocks. A kvrocks created before the backend is recorded keeps
+// running on its kruise statefulSets
+func ensureWorkloadBackend(instance *kvrocksv1alpha1.KVRocks, k8sClient *k8s.Client) error {
+	if instance.Spec.Type == kvrocksv1alpha1.SentinelType || instance.Status.WorkloadBackend != "" {
+		return nil
+	}
+	backend := workload.GetBackendType(instance)
+	if backend != kvrocksv1alpha1.KruiseWorkloadBackend {
+		name := resources.GetStatefulSetName(instance.Name)
+		if instance.Spec.Type == kvrocksv1alpha1.ClusterType {
+			name = resources.GetStatefulSetName(instance.Name, 0)
+		}
+		_, err := k8sClient.GetStatefulSet(types.NamespacedName{
+			Namespace: instance.Namespace,
+			Name:      name,
+		})
+		switch {
+		case err == nil:
+			backend = kvrocksv1alpha1.KruiseWorkloadBackend
+		case !errors.IsNotFound(err) && !meta.IsNoMatchError(err):
+			return err
+		}
+	}
+	instance.Status.WorkloadBackend = backend
+	return k8sClient.UpdateKVRocks(instance)
 }
 
 // checkSpecification Check if the field is correct
@@ -182,6 +329,25 @@ func checkSpecification(instance *kvrocksv1alpha1.KVRocks, log logr.Logger, k8sC
 	return nil
 }
 
+// checkConfig sets the ConfigValid condition, the invalid configs are ignored and reported in the condition
+func checkConfig(instance *kvrocksv1alpha1.KVRocks, log logr.Logger, k8sClient *k8s.Client) error {
+	if instance.Spec.Type == kvrocksv1alpha1.SentinelType {
+		return nil
+	}
+	var changed bool
+	_, invalid := instance.Spec.ValidateConfigs()
+	if len(invalid) != 0 {
+		log.Info("ignore invalid configs", "configs", invalid)
+		changed = common.SetCondition(instance, kvrocksv1alpha1.ConditionConfigValid, metav1.ConditionFalse, "InvalidConfig", strings.Join(invalid, "; "))
+	} else {
+		changed = common.SetCondition(instance, kvrocksv1alpha1.ConditionConfigValid, metav1.ConditionTrue, "Valid", "all configs are valid")
+	}
+	if changed {
+		return k8sClient.UpdateKVRocks(instance)
+	}
+	return nil
+}
+
 // if err is NotFound error or update conflicts error, requeue
 func shouldRetry(err error) bool {
 	return err != nil && (errors.IsNotFound(err) || errors.IsConflict(err))
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"testing"
+
+	kruise "github.com/openkruise/kruise-api/apps/v1beta1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sApiClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/k8s"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/workload"
+)
+
+func TestEnsureWorkloadBackend(t *testing.T) {
+	defaultBackend := workload.DefaultBackend
+	defer func() {
+		workload.DefaultBackend = defaultBackend
+	}()
+
+	tests := []struct {
+		name           string
+		kvrocksType    kvrocksv1alpha1.KVRocksType
+		defaultBackend kvrocksv1alpha1.WorkloadBackendType
+		specBackend    kvrocksv1alpha1.WorkloadBackendType
+		existingSts    string
+		expBackend     kvrocksv1alpha1.WorkloadBackendType
+	}{
+		{
+			name:           "A new kvrocks should use the default backend.",
+			kvrocksType:    kvrocksv1alpha1.StandardType,
+			defaultBackend: kvrocksv1alpha1.StatefulSetWorkloadBackend,
+			expBackend:     kvrocksv1alpha1.StatefulSetWorkloadBackend,
+		}, {
+			name:           "A new kvrocks should use the backend in spec.",
+			kvrocksType:    kvrocksv1alpha1.StandardType,
+			defaultBackend: kvrocksv1alpha1.KruiseWorkloadBackend,
+			specBackend:    kvrocksv1alpha1.StatefulSetWorkloadBackend,
+			expBackend:     kvrocksv1alpha1.StatefulSetWorkloadBackend,
+		}, {
+			name:           "An existing kvrocks with kruise statefulSets should keep kruise.",
+			kvrocksType:    kvrocksv1alpha1.StandardType,
+			defaultBackend: kvrocksv1alpha1.StatefulSetWorkloadBackend,
+			existingSts:    "test",
+			expBackend:     kvrocksv1alpha1.KruiseWorkloadBackend,
+		}, {
+			name:           "An existing cluster with kruise statefulSets should keep kruise even if spec asks for another.",
+			kvrocksType:    kvrocksv1alpha1.ClusterType,
+			defaultBackend: kvrocksv1alpha1.StatefulSetWorkloadBackend,
+			specBackend:    kvrocksv1alpha1.StatefulSetWorkloadBackend,
+			existingSts:    "test-0",
+			expBackend:     kvrocksv1alpha1.KruiseWorkloadBackend,
+		}, {
+			name:           "No backend should be recorded for sentinel.",
+			kvrocksType:    kvrocksv1alpha1.SentinelType,
+			defaultBackend: kvrocksv1alpha1.StatefulSetWorkloadBackend,
+			expBackend:     "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			workload.DefaultBackend = test.defaultBackend
+			instance := &kvrocksv1alpha1.KVRocks{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "unit-test"},
+				Spec: kvrocksv1alpha1.KVRocksSpec{
+					Type:            test.kvrocksType,
+					WorkloadBackend: test.specBackend,
+				},
+			}
+			objs := []k8sApiClient.Object{instance}
+			if test.existingSts != "" {
+				objs = append(objs, &kruise.StatefulSet{
+					ObjectMeta: metav1.ObjectMeta{Name: test.existingSts, Namespace: "unit-test"},
+				})
+			}
+			scheme := runtime.NewScheme()
+			_ = kvrocksv1alpha1.AddToScheme(scheme)
+			_ = kruise.AddToScheme(scheme)
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+			c := k8s.NewK8sClient(fakeClient, ctrl.Log.WithName("controller-test"))
+			instance, err := c.GetKVRocks(k8sApiClient.ObjectKeyFromObject(instance))
+			assert.NoError(err)
+
+			assert.NoError(ensureWorkloadBackend(instance, c))
+			assert.Equal(test.expBackend, instance.Status.WorkloadBackend)
+		})
+	}
+}
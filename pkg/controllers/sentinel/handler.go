@@ -3,10 +3,12 @@ package sentinel
 import (
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 
 	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
 	"github.com/RocksLabs/kvrocks-operator/pkg/client/k8s"
 	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/metrics"
 )
 
 type KVRocksSentinelHandler struct {
@@ -16,7 +18,10 @@ type KVRocksSentinelHandler struct {
 	kvrocks  kvrocks.Client
 	log      logr.Logger
 	pods     []string
+	podNames []string
+	monitors []kvrocksv1alpha1.KVRocksSentinelMonitor
 	requeue  bool
+	recorder record.EventRecorder
 }
 
 func NewKVRocksSentinelHandler(
@@ -25,6 +30,7 @@ func NewKVRocksSentinelHandler(
 	logger logr.Logger,
 	key types.NamespacedName,
 	instance *kvrocksv1alpha1.KVRocks,
+	recorder record.EventRecorder,
 ) *KVRocksSentinelHandler {
 	return &KVRocksSentinelHandler{
 		instance: instance,
@@ -33,18 +39,27 @@ func NewKVRocksSentinelHandler(
 		log:      logger,
 		key:      key,
 		requeue:  false,
+		recorder: recorder,
 	}
 }
 
 func (h *KVRocksSentinelHandler) Handle() (error, bool) {
-	err := h.ensureKubernetes()
+	err := h.step("ensureKubernetes", h.ensureKubernetes)
 	if err != nil || h.requeue {
 		return err, false
 	}
-	err = h.ensureSentinel()
-	if err != nil || h.requeue {
+	err = h.step("ensureSentinel", h.ensureSentinel)
+	if err != nil {
+		return err, false
+	}
+	// the status is refreshed even if a monitored kvrocks is not running, so that the disagreement is still reported
+	err = h.step("ensureSentinelStatus", h.ensureSentinelStatus)
+	if err != nil {
 		return err, false
 	}
+	if h.requeue {
+		return h.k8s.UpdateKVRocks(h.instance), false
+	}
 	h.instance.Status.Status = kvrocksv1alpha1.StatusRunning
 	if err := h.k8s.UpdateKVRocks(h.instance); err != nil {
 		return err, false
@@ -56,6 +71,11 @@ func (h *KVRocksSentinelHandler) Requeue() bool {
 	return h.requeue
 }
 
+// step runs the reconcile step and observes its duration
+func (h *KVRocksSentinelHandler) step(name string, fn func() error) error {
+	return metrics.ObserveStep(h.instance.Spec.Type, name, fn)
+}
+
 func (h *KVRocksSentinelHandler) Finializer() error {
 	return nil
 }
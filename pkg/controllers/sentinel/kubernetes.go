@@ -15,11 +15,17 @@ func (h *KVRocksSentinelHandler) ensureKubernetes() error {
 		return err
 	}
 	service := resources.NewSentinelService(h.instance)
-	if err = h.k8s.CreateIfNotExistsService(service); err != nil {
+	if err = h.k8s.CreateOrUpdateService(service); err != nil {
+		return err
+	}
+	if err = h.k8s.CreateIfNotExistsService(resources.NewSentinelHeadlessService(h.instance)); err != nil {
 		return err
 	}
 	dep := resources.NewSentinelDeployment(h.instance)
-	if err = h.k8s.CreateIfNotExistsDeployment(dep); err != nil {
+	if err = h.k8s.CreateDeploymentOrUpdateTemplate(dep); err != nil {
+		return err
+	}
+	if err = h.k8s.CreateOrUpdatePDB(resources.NewSentinelPDB(h.instance)); err != nil {
 		return err
 	}
 	dep, err = h.k8s.GetDeployment(h.key)
@@ -37,6 +43,7 @@ func (h *KVRocksSentinelHandler) ensureKubernetes() error {
 	}
 	for _, pod := range pods.Items {
 		h.pods = append(h.pods, pod.Status.PodIP)
+		h.podNames = append(h.podNames, pod.Name)
 	}
 	h.log.Info("kubernetes resources ok")
 	return nil
@@ -4,10 +4,13 @@ import (
 	"errors"
 	"fmt"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 
 	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
 	kv "github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/workload"
+	"github.com/RocksLabs/kvrocks-operator/pkg/controllers/common"
 	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
 )
 
@@ -21,6 +24,8 @@ func (h *KVRocksSentinelHandler) ensureSentinel() error {
 			continue
 		}
 		if kvrocks.Status.Status != kvrocksv1alpha1.StatusRunning {
+			// the monitors are still observed with the last expected masters until the kvrocks is running again
+			h.monitors = append(h.monitors, h.lastMonitors(&kvrocks)...)
 			h.requeue = true
 			continue
 		}
@@ -31,11 +36,13 @@ func (h *KVRocksSentinelHandler) ensureSentinel() error {
 				Namespace: kvrocks.Namespace,
 				Name:      kvrocks.Name,
 			}
-			node, err := h.getMasterMsg(key, password)
+			node, err := h.getMasterMsg(&kvrocks, key, password)
 			if err != nil {
 				return err
 			}
-			return h.ensureMonitor(node.IP, name, password)
+			if err = h.ensureMonitor(node.IP, name, password); err != nil {
+				return err
+			}
 		} else { // cluster type
 			for index := 0; index < int(kvrocks.Spec.Master); index++ {
 				key := types.NamespacedName{
@@ -43,7 +50,7 @@ func (h *KVRocksSentinelHandler) ensureSentinel() error {
 					Name:      fmt.Sprintf("%s-%d", kvrocks.Name, index),
 				}
 				masterName := fmt.Sprintf("%s-%d", name, index)
-				node, err := h.getMasterMsg(key, password)
+				node, err := h.getMasterMsg(&kvrocks, key, password)
 				if err != nil {
 					return err
 				}
@@ -57,13 +64,33 @@ func (h *KVRocksSentinelHandler) ensureSentinel() error {
 	return nil
 }
 
-func (h *KVRocksSentinelHandler) getMasterMsg(key types.NamespacedName, password string) (*kv.Node, error) {
-	pods, err := h.k8s.ListStatefulSetPods(key)
+// lastMonitors returns the monitors of the kvrocks in the last status, without the observation
+func (h *KVRocksSentinelHandler) lastMonitors(instance *kvrocksv1alpha1.KVRocks) []kvrocksv1alpha1.KVRocksSentinelMonitor {
+	_, name := resources.ParseRedisName(instance.Name)
+	names := map[string]bool{}
+	if instance.Spec.Type == kvrocksv1alpha1.StandardType {
+		names[name] = true
+	} else {
+		for index := 0; index < int(instance.Spec.Master); index++ {
+			names[fmt.Sprintf("%s-%d", name, index)] = true
+		}
+	}
+	var monitors []kvrocksv1alpha1.KVRocksSentinelMonitor
+	for _, monitor := range h.instance.Status.Monitors {
+		if names[monitor.Name] {
+			monitors = append(monitors, kvrocksv1alpha1.KVRocksSentinelMonitor{Name: monitor.Name, Expected: monitor.Expected})
+		}
+	}
+	return monitors
+}
+
+func (h *KVRocksSentinelHandler) getMasterMsg(instance *kvrocksv1alpha1.KVRocks, key types.NamespacedName, password string) (*kv.Node, error) {
+	pods, err := workload.NewBackend(h.k8s, instance).ListStatefulSetPods(key)
 	if err != nil {
 		return nil, err
 	}
-	for _, pod := range pods.Items {
-		node, err := h.kvrocks.NodeInfo(pod.Status.PodIP, password)
+	for i := range pods.Items {
+		node, err := h.kvrocks.NodeInfo(resources.GetPodHost(&pods.Items[i]), password)
 		if err != nil {
 			return nil, err
 		}
@@ -76,6 +103,7 @@ func (h *KVRocksSentinelHandler) getMasterMsg(key types.NamespacedName, password
 
 func (h *KVRocksSentinelHandler) ensureMonitor(masterIP, masterName, password string) error {
 	sentinelPassword := h.instance.Spec.Password
+	updated := false
 	for _, sentinelIP := range h.pods {
 		master, err := h.kvrocks.GetMasterFromSentinel(sentinelIP, sentinelPassword, masterName)
 		if err != nil || master != masterIP {
@@ -83,12 +111,17 @@ func (h *KVRocksSentinelHandler) ensureMonitor(masterIP, masterName, password st
 			if err := h.kvrocks.CreateMonitor(sentinelIP, sentinelPassword, masterName, masterIP, password); err != nil {
 				return err
 			}
+			updated = true
 		} else {
 			if err = h.kvrocks.ResetMonitor(sentinelIP, sentinelPassword, masterName, password); err != nil {
 				return err
 			}
 		}
 	}
+	h.monitors = append(h.monitors, kvrocksv1alpha1.KVRocksSentinelMonitor{Name: masterName, Expected: masterIP})
+	if updated {
+		h.recorder.Eventf(h.instance, corev1.EventTypeNormal, common.ReasonMonitorUpdated, "monitor %s at %s", masterName, masterIP)
+	}
 	h.log.Info("sentinel monitor ok", "master", masterName)
 	return nil
 }
@@ -0,0 +1,71 @@
+package sentinel
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/controllers/common"
+)
+
+// ensureSentinelStatus publishes the master reported by each sentinel for the monitors, the status is written with
+// the running status at the end of Handle
+func (h *KVRocksSentinelHandler) ensureSentinelStatus() error {
+	sort.Slice(h.monitors, func(i, j int) bool {
+		return h.monitors[i].Name < h.monitors[j].Name
+	})
+	var disagreed []string
+	for i := range h.monitors {
+		monitor := &h.monitors[i]
+		h.observeMonitor(monitor)
+		if monitor.Disagreement != "" {
+			disagreed = append(disagreed, monitor.Name)
+		}
+	}
+	h.instance.Status.Monitors = h.monitors
+	if len(disagreed) != 0 {
+		h.log.Info("sentinels disagree on the masters", "masters", disagreed)
+		common.SetCondition(h.instance, kvrocksv1alpha1.ConditionSentinelsAgreed, metav1.ConditionFalse, "Disagreed",
+			"sentinels disagree on "+strings.Join(disagreed, ","))
+		return nil
+	}
+	common.SetCondition(h.instance, kvrocksv1alpha1.ConditionSentinelsAgreed, metav1.ConditionTrue, "Agreed",
+		"all sentinels report the expected masters")
+	return nil
+}
+
+// observeMonitor asks each sentinel for the master, the address reported by the most sentinels is the master
+func (h *KVRocksSentinelHandler) observeMonitor(monitor *kvrocksv1alpha1.KVRocksSentinelMonitor) {
+	votes := map[string]int{}
+	for i, ip := range h.pods {
+		view := kvrocksv1alpha1.KVRocksSentinelView{Pod: h.podNames[i]}
+		master, err := h.kvrocks.GetMasterFromSentinel(ip, h.instance.Spec.Password, monitor.Name)
+		if err != nil {
+			view.Error = err.Error()
+		} else {
+			view.Master = master
+			votes[master]++
+		}
+		monitor.Sentinels = append(monitor.Sentinels, view)
+	}
+	for master, count := range votes {
+		if count > monitor.Agreed || (count == monitor.Agreed && master < monitor.Master) {
+			monitor.Master, monitor.Agreed = master, count
+		}
+	}
+	var reasons []string
+	if monitor.Master != monitor.Expected {
+		reasons = append(reasons, fmt.Sprintf("the master is %s, expected %s", monitor.Master, monitor.Expected))
+	}
+	for _, view := range monitor.Sentinels {
+		if view.Error != "" {
+			reasons = append(reasons, fmt.Sprintf("%s can not be queried", view.Pod))
+		} else if view.Master != monitor.Master {
+			reasons = append(reasons, fmt.Sprintf("%s reports %s", view.Pod, view.Master))
+		}
+	}
+	monitor.Disagreement = strings.Join(reasons, "; ")
+}
@@ -0,0 +1,191 @@
+package sentinel
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sApiClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/k8s"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+// fakeKVRocks answers the masters reported by the sentinels from its map indexed by "sentinel ip/master name", the
+// sentinels absent from the map can not be queried. The other commands panic
+type fakeKVRocks struct {
+	kvrocks.Client
+	masters map[string]string
+}
+
+func (c *fakeKVRocks) Logger() logr.Logger {
+	return ctrl.Log.WithName("sentinel-test")
+}
+
+func (c *fakeKVRocks) GetMasterFromSentinel(sentinelIP, password, name string) (string, error) {
+	master, ok := c.masters[sentinelIP+"/"+name]
+	if !ok {
+		return "", errors.New("connection refused")
+	}
+	return master, nil
+}
+
+func newTestInstance() *kvrocksv1alpha1.KVRocks {
+	return &kvrocksv1alpha1.KVRocks{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sentinel",
+			Namespace: "unit-test",
+		},
+		Spec: kvrocksv1alpha1.KVRocksSpec{
+			Type:     kvrocksv1alpha1.SentinelType,
+			Replicas: 3,
+			Password: "password",
+		},
+	}
+}
+
+func newTestHandler(instance *kvrocksv1alpha1.KVRocks, kvClient kvrocks.Client, objs ...k8sApiClient.Object) *KVRocksSentinelHandler {
+	scheme := runtime.NewScheme()
+	_ = kvrocksv1alpha1.AddToScheme(scheme)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(objs, instance)...).Build()
+	log := ctrl.Log.WithName("sentinel-test")
+	h := NewKVRocksSentinelHandler(k8s.NewK8sClient(fakeClient, log), kvClient, log, k8sApiClient.ObjectKeyFromObject(instance), instance, record.NewFakeRecorder(100))
+	h.pods = []string{"10.0.1.1", "10.0.1.2", "10.0.1.3"}
+	h.podNames = []string{"sentinel-0", "sentinel-1", "sentinel-2"}
+	return h
+}
+
+func TestEnsureSentinelStatus(t *testing.T) {
+	master := "test-0.test-headless.unit-test.svc.cluster.local"
+	other := "test-1.test-headless.unit-test.svc.cluster.local"
+
+	tests := []struct {
+		name            string
+		masters         map[string]string
+		expMaster       string
+		expAgreed       int
+		expDisagreement string
+		expStatus       metav1.ConditionStatus
+	}{
+		{
+			name: "Sentinels reporting the expected master should agree.",
+			masters: map[string]string{
+				"10.0.1.1/test": master,
+				"10.0.1.2/test": master,
+				"10.0.1.3/test": master,
+			},
+			expMaster: master,
+			expAgreed: 3,
+			expStatus: metav1.ConditionTrue,
+		}, {
+			name: "A sentinel reporting another master should disagree.",
+			masters: map[string]string{
+				"10.0.1.1/test": master,
+				"10.0.1.2/test": master,
+				"10.0.1.3/test": other,
+			},
+			expMaster:       master,
+			expAgreed:       2,
+			expDisagreement: "sentinel-2 reports " + other,
+			expStatus:       metav1.ConditionFalse,
+		}, {
+			name: "The master reported by the most sentinels should disagree with the expected one.",
+			masters: map[string]string{
+				"10.0.1.1/test": other,
+				"10.0.1.2/test": other,
+				"10.0.1.3/test": master,
+			},
+			expMaster:       other,
+			expAgreed:       2,
+			expDisagreement: "the master is " + other + ", expected " + master + "; sentinel-2 reports " + master,
+			expStatus:       metav1.ConditionFalse,
+		}, {
+			name: "A sentinel which can not be queried should disagree.",
+			masters: map[string]string{
+				"10.0.1.1/test": master,
+				"10.0.1.2/test": master,
+			},
+			expMaster:       master,
+			expAgreed:       2,
+			expDisagreement: "sentinel-2 can not be queried",
+			expStatus:       metav1.ConditionFalse,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			instance := newTestInstance()
+			h := newTestHandler(instance, &fakeKVRocks{masters: test.masters})
+			h.monitors = []kvrocksv1alpha1.KVRocksSentinelMonitor{{Name: "test", Expected: master}}
+
+			assert.NoError(h.ensureSentinelStatus())
+			if assert.Len(instance.Status.Monitors, 1) {
+				monitor := instance.Status.Monitors[0]
+				assert.Equal(test.expMaster, monitor.Master)
+				assert.Equal(test.expAgreed, monitor.Agreed)
+				assert.Equal(test.expDisagreement, monitor.Disagreement)
+				assert.Len(monitor.Sentinels, 3)
+			}
+			condition := meta.FindStatusCondition(instance.Status.Conditions, kvrocksv1alpha1.ConditionSentinelsAgreed)
+			if assert.NotNil(condition) {
+				assert.Equal(test.expStatus, condition.Status)
+			}
+		})
+	}
+}
+
+func TestEnsureSentinelStatusNotRunning(t *testing.T) {
+	assert := assert.New(t)
+
+	master := "test-0.test-headless.unit-test.svc.cluster.local"
+	other := "test-1.test-headless.unit-test.svc.cluster.local"
+	instance := newTestInstance()
+	instance.Status.Monitors = []kvrocksv1alpha1.KVRocksSentinelMonitor{
+		{Name: "test", Expected: master, Master: master, Agreed: 3},
+		{Name: "removed", Expected: master, Master: master, Agreed: 3},
+	}
+	// the monitored kvrocks is failing, the sentinels failed over to another master in the meantime
+	monitored := &kvrocksv1alpha1.KVRocks{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kvrocks-standard-1-test",
+			Namespace: "unit-test",
+			Labels:    resources.MonitorLabels(instance.Name),
+		},
+		Spec: kvrocksv1alpha1.KVRocksSpec{
+			Type: kvrocksv1alpha1.StandardType,
+		},
+		Status: kvrocksv1alpha1.KVRocksStatus{
+			Status: kvrocksv1alpha1.StatusFailed,
+		},
+	}
+	h := newTestHandler(instance, &fakeKVRocks{masters: map[string]string{
+		"10.0.1.1/test": other,
+		"10.0.1.2/test": other,
+		"10.0.1.3/test": other,
+	}}, monitored)
+
+	assert.NoError(h.ensureSentinel())
+	assert.True(h.requeue)
+	assert.NoError(h.ensureSentinelStatus())
+	// the monitor is observed again with the last expected master, the one of another kvrocks is dropped
+	if assert.Len(instance.Status.Monitors, 1) {
+		monitor := instance.Status.Monitors[0]
+		assert.Equal("test", monitor.Name)
+		assert.Equal(master, monitor.Expected)
+		assert.Equal(other, monitor.Master)
+		assert.Equal(3, monitor.Agreed)
+		assert.Equal("the master is "+other+", expected "+master, monitor.Disagreement)
+	}
+	assert.True(meta.IsStatusConditionFalse(instance.Status.Conditions, kvrocksv1alpha1.ConditionSentinelsAgreed))
+}
@@ -3,11 +3,14 @@ package standard
 import (
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 
 	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
 	"github.com/RocksLabs/kvrocks-operator/pkg/client/k8s"
 	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/workload"
 	"github.com/RocksLabs/kvrocks-operator/pkg/controllers/common"
+	"github.com/RocksLabs/kvrocks-operator/pkg/metrics"
 	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
 )
 
@@ -15,11 +18,13 @@ type KVRocksStandardHandler struct {
 	instance *kvrocksv1alpha1.KVRocks
 	k8s      *k8s.Client
 	kvrocks  kvrocks.Client
+	workload workload.Backend
 	log      logr.Logger
 	password string
 	stsNodes []*kvrocks.Node
 	requeue  bool
 	key      types.NamespacedName
+	recorder record.EventRecorder
 }
 
 func NewKVRocksStandardHandler(
@@ -28,31 +33,66 @@ func NewKVRocksStandardHandler(
 	log logr.Logger,
 	key types.NamespacedName,
 	instance *kvrocksv1alpha1.KVRocks,
+	recorder record.EventRecorder,
 ) *KVRocksStandardHandler {
 	return &KVRocksStandardHandler{
 		instance: instance,
 		k8s:      k8s,
 		kvrocks:  kvrocks,
+		workload: workload.NewBackend(k8s, instance),
 		log:      log,
 		requeue:  false,
 		key:      key,
+		recorder: recorder,
 	}
 }
 
 func (h *KVRocksStandardHandler) Handle() (error, bool) {
-	err := h.ensureKubernetes()
+	err := h.step("ensureKubernetes", h.ensureKubernetes)
 	if err != nil || h.requeue {
 		return err, false
 	}
-	err = h.ensureKVRocksStatus()
+	err = h.step("ensureKVRocksStatus", h.ensureKVRocksStatus)
 	if err != nil || h.requeue {
 		return err, false
 	}
-	err = h.resizeStatefulSet()
+	err = h.step("ensureOperation", h.ensureOperation)
 	if err != nil || h.requeue {
 		return err, false
 	}
-	err = h.cleanPersistentVolumeClaim()
+	err = h.step("ensureNodeDrain", h.ensureNodeDrain)
+	if err != nil || h.requeue {
+		return err, false
+	}
+	err = h.step("ensureReadReplicas", h.ensureReadReplicas)
+	if err != nil || h.requeue {
+		return err, false
+	}
+	err = h.step("ensureReseed", h.ensureReseed)
+	if err != nil || h.requeue {
+		return err, false
+	}
+	err = h.step("ensureConnectionSecret", h.ensureConnectionSecret)
+	if err != nil || h.requeue {
+		return err, false
+	}
+	err = h.step("ensureMonitoring", h.ensureMonitoring)
+	if err != nil || h.requeue {
+		return err, false
+	}
+	err = h.step("ensureRestart", h.ensureRestart)
+	if err != nil || h.requeue {
+		return err, false
+	}
+	err = h.step("ensureStorage", h.ensureStorage)
+	if err != nil || h.requeue {
+		return err, false
+	}
+	err = h.step("resizeStatefulSet", h.resizeStatefulSet)
+	if err != nil || h.requeue {
+		return err, false
+	}
+	err = h.step("cleanPersistentVolumeClaim", h.cleanPersistentVolumeClaim)
 	if err != nil || h.requeue {
 		return err, false
 	}
@@ -63,11 +103,19 @@ func (h *KVRocksStandardHandler) Requeue() bool {
 	return h.requeue
 }
 
+// step runs the reconcile step and observes its duration
+func (h *KVRocksStandardHandler) step(name string, fn func() error) error {
+	return metrics.ObserveStep(h.instance.Spec.Type, name, fn)
+}
+
 func (h *KVRocksStandardHandler) Finializer() error {
+	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder)
+	if err := commHandler.RetainPVCs(); err != nil {
+		return err
+	}
 	if _, ok := h.instance.Labels[resources.MonitoredBy]; !ok {
 		return nil
 	}
-	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password)
 	_, masterName := resources.ParseRedisName(h.instance.Name)
 	requeue, err := commHandler.RemoveMonitor(masterName)
 	h.requeue = requeue
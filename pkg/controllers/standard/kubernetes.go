@@ -3,9 +3,11 @@ package standard
 import (
 	"sort"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 
 	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/controllers/common"
 	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
 )
 
@@ -15,7 +17,15 @@ func (h *KVRocksStandardHandler) ensureKubernetes() error {
 		return err
 	}
 	service := resources.NewKVRocksService(h.instance)
-	if err := h.k8s.CreateIfNotExistsService(service); err != nil {
+	if err := h.k8s.CreateOrUpdateService(service); err != nil {
+		return err
+	}
+	readService := resources.NewReadService(h.instance)
+	if err := h.k8s.CreateOrUpdateService(readService); err != nil {
+		return err
+	}
+	headless := resources.NewHeadlessService(h.instance, h.instance.Name)
+	if err := h.k8s.CreateIfNotExistsService(headless); err != nil {
 		return err
 	}
 	oldCM, err := h.k8s.GetConfigMap(h.key)
@@ -28,10 +38,13 @@ func (h *KVRocksStandardHandler) ensureKubernetes() error {
 	}
 	h.password = oldCM.Data["password"]
 	sts := resources.NewReplicationStatefulSet(h.instance)
-	if err = h.k8s.CreateStatefulSetOrUpdateImage(sts); err != nil {
+	if err = h.workload.CreateStatefulSetOrUpdateTemplate(sts); err != nil {
 		return err
 	}
-	oldSts, err := h.k8s.GetStatefulSet(h.key)
+	if err = h.k8s.CreateOrUpdatePDB(resources.NewKVRocksPDB(h.instance, sts.Name)); err != nil {
+		return err
+	}
+	oldSts, err := h.workload.GetStatefulSet(h.key)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			h.requeue = true
@@ -39,32 +52,46 @@ func (h *KVRocksStandardHandler) ensureKubernetes() error {
 		}
 		return err
 	}
+	if oldSts.DeletionTimestamp != nil {
+		h.log.Info("waiting for statefulSet recreated")
+		h.requeue = true
+		return nil
+	}
+	// serviceName is immutable, the statefulSet created before the headless service is recreated with orphan pods
+	if oldSts.Spec.ServiceName != sts.Spec.ServiceName {
+		h.log.Info("recreate statefulSet to update serviceName", "serviceName", sts.Spec.ServiceName)
+		h.requeue = true
+		return h.workload.DeleteStatefulSetOrphan(oldSts)
+	}
 	sts.ResourceVersion = oldSts.ResourceVersion
-	// we can resize statefulSet directly if scaling up
+	sts.Spec.VolumeClaimTemplates = oldSts.Spec.VolumeClaimTemplates
+	resources.KeepReadinessGates(&sts.Spec.Template, oldSts.Spec.Template.Spec.ReadinessGates)
+	// we can resize statefulSet directly if scaling up, scaling is suppressed under maintenance
 	delta := *sts.Spec.Replicas - *oldSts.Spec.Replicas
-	if delta > 0 {
+	if delta > 0 && !resources.IsUnderMaintenance(h.instance) {
 		reserve := oldSts.Spec.ReserveOrdinals
 		for delta > 0 && len(reserve) > 0 {
 			reserve = reserve[1:]
 			delta--
 		}
 		sts.Spec.ReserveOrdinals = reserve
-		if err = h.k8s.UpdateStatefulSet(sts); err != nil {
+		if err = h.workload.UpdateStatefulSet(sts); err != nil {
 			return err
 		}
 		oldSts = sts
 	}
-	if oldSts.Status.ReadyReplicas != *oldSts.Spec.Replicas {
-		h.log.Info("waiting for statefulSet ready")
-		h.requeue = true
-		return nil
-	}
-	pods, err := h.k8s.ListStatefulSetPods(h.key)
+	pods, err := h.workload.ListStatefulSetPods(h.key)
 	if err != nil {
 		return err
 	}
+	// the slaves lagging behind the master are not ready, but still take part in the replication
+	if resources.CountReadyPods(pods) != *oldSts.Spec.Replicas {
+		h.log.Info("waiting for statefulSet ready")
+		h.requeue = true
+		return h.ensureLostPods()
+	}
 	for _, pod := range pods.Items {
-		node, err := h.kvrocks.NodeInfo(pod.Status.PodIP, h.password)
+		node, err := h.kvrocks.NodeInfo(resources.GetPodHost(&pod), h.password)
 		if err != nil {
 			return err
 		}
@@ -82,14 +109,88 @@ func (h *KVRocksStandardHandler) ensureKubernetes() error {
 	return nil
 }
 
+// ensureLostPods replaces the pods on the lost nodes, which would never be ready again. A lost master is switched
+// over to a ready slave first, and replaced as a slave
+func (h *KVRocksStandardHandler) ensureLostPods() error {
+	if resources.IsUnderMaintenance(h.instance) {
+		return nil
+	}
+	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder)
+	_, lost, err := commHandler.ListDisruptedPods(h.key)
+	if err != nil {
+		return err
+	}
+	for _, pod := range lost {
+		if pod.Labels[resources.KvrocksRole] == kvrocks.RoleMaster {
+			return h.failoverLostMaster(pod)
+		}
+	}
+	for _, pod := range lost {
+		if err = commHandler.ReplacePod(pod); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// failoverLostMaster promotes the ready slave with the largest replication offset
+func (h *KVRocksStandardHandler) failoverLostMaster(master *corev1.Pod) error {
+	pods, err := h.workload.ListStatefulSetPods(h.key)
+	if err != nil {
+		return err
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Name == master.Name || !resources.IsPodReady(pod) {
+			continue
+		}
+		node, err := h.kvrocks.NodeInfo(resources.GetPodHost(pod), h.password)
+		if err != nil {
+			return err
+		}
+		if node.PodIndex, err = resources.GetPVCOrPodIndex(pod.Name); err != nil {
+			return err
+		}
+		h.stsNodes = append(h.stsNodes, &node)
+	}
+	index, err := resources.GetPVCOrPodIndex(master.Name)
+	if err != nil {
+		return err
+	}
+	if len(h.stsNodes) != 0 {
+		h.log.Info("node of master is lost, switch over", "pod", master.Name, "node", master.Spec.NodeName)
+		err = h.switchover(&kvrocks.Node{IP: resources.GetPodHost(master), PodIndex: index}, func(*kvrocks.Node) bool { return true })
+		if err != nil {
+			return err
+		}
+	}
+	return h.updateKVRocksRole(index, kvrocks.RoleSlaver)
+}
+
 func (h *KVRocksStandardHandler) resizeStatefulSet() error {
+	if resources.IsUnderMaintenance(h.instance) {
+		return nil
+	}
 	delta := len(h.stsNodes) - int(h.instance.Spec.Replicas)
 	// scaling down,delete slave node
 	if delta > 0 {
 		sts := resources.NewReplicationStatefulSet(h.instance)
+		if !h.workload.SupportReserveOrdinals() {
+			// only the pods of the largest ordinals can be removed, move the master to a lower ordinal first
+			replicas := int(h.instance.Spec.Replicas)
+			for _, node := range h.stsNodes {
+				if node.Role == kvrocks.RoleMaster && node.PodIndex >= replicas {
+					h.log.Info("move master to a lower ordinal before scaling down", "pod", node.PodIndex)
+					h.requeue = true
+					return h.switchover(node, func(candidate *kvrocks.Node) bool {
+						return candidate.PodIndex < replicas
+					})
+				}
+			}
+		}
 		reserve := make([]int, 0)
 		masterID := 0
-		for i := len(h.stsNodes) - 1; i >= 0; i-- {
+		for i := len(h.stsNodes) - 1; i >= 0 && h.workload.SupportReserveOrdinals(); i-- {
 			if delta > 0 {
 				if h.stsNodes[i].Role != kvrocks.RoleMaster {
 					reserve = append(reserve, h.stsNodes[i].PodIndex)
@@ -111,7 +212,7 @@ func (h *KVRocksStandardHandler) resizeStatefulSet() error {
 			sts.Spec.ReserveOrdinals = append(sts.Spec.ReserveOrdinals, id)
 		}
 		h.log.WithValues("from", len(h.stsNodes), "to", h.instance.Spec.Replicas, "reserve", sts.Spec.ReserveOrdinals).Info("scaling down")
-		if err := h.k8s.CreateOrUpdateStatefulSet(sts); err != nil {
+		if err := h.workload.CreateOrUpdateStatefulSet(sts); err != nil {
 			return err
 		}
 		h.requeue = true
@@ -119,8 +220,25 @@ func (h *KVRocksStandardHandler) resizeStatefulSet() error {
 	return nil
 }
 
+// ensureStorage expands the volumes when spec.storage.size grows or the disk usage reaches the autoscale threshold
+func (h *KVRocksStandardHandler) ensureStorage() error {
+	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder)
+	if err := commHandler.EnsureStorageAutoScale(h.stsNodes); err != nil {
+		return err
+	}
+	statuses, requeue, err := commHandler.EnsureStorage(h.key)
+	if err != nil {
+		return err
+	}
+	h.requeue = requeue
+	return commHandler.UpdateStorageStatus(statuses)
+}
+
 func (h *KVRocksStandardHandler) cleanPersistentVolumeClaim() error {
-	pvcList, err := h.k8s.ListStatefulSetPVC(h.key)
+	if resources.IsUnderMaintenance(h.instance) {
+		return nil
+	}
+	pvcList, err := h.workload.ListStatefulSetPVC(h.key)
 	if err != nil {
 		return err
 	}
@@ -128,15 +246,20 @@ func (h *KVRocksStandardHandler) cleanPersistentVolumeClaim() error {
 	for _, node := range h.stsNodes {
 		exitsPod[node.PodIndex] = struct{}{}
 	}
-	for _, pvc := range pvcList.Items {
+	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder)
+	for i := range pvcList.Items {
+		pvc := &pvcList.Items[i]
 		index, err := resources.GetPVCOrPodIndex(pvc.Name)
 		if err != nil {
 			return err
 		}
 		if _, ok := exitsPod[index]; !ok {
-			if err = h.k8s.DeletePVC(&pvc); err != nil {
-				return err
-			}
+			err = commHandler.CleanPVC(pvc)
+		} else {
+			err = commHandler.ReusePVC(pvc)
+		}
+		if err != nil {
+			return err
 		}
 	}
 	return nil
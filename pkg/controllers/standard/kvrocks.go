@@ -3,7 +3,9 @@ package standard
 import (
 	"errors"
 	"fmt"
+	"reflect"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
@@ -28,13 +30,16 @@ func (h *KVRocksStandardHandler) ensureKVRocksStatus() error {
 }
 
 func (h *KVRocksStandardHandler) ensureKVRocksConfig() error {
-	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password)
-	if err := commHandler.EnsureConfig(h.stsNodes); err != nil {
+	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder)
+	statuses, err := commHandler.EnsureConfig(h.instance.Name, h.stsNodes)
+	if updateErr := commHandler.UpdateConfigStatus(statuses); updateErr != nil {
+		return updateErr
+	}
+	if err != nil {
 		return err
 	}
 	h.password = h.instance.Spec.Password
-	cm := resources.NewKVRocksConfigMap(h.instance)
-	if err := h.k8s.UpdateConfigMap(cm); err != nil {
+	if err := commHandler.UpdateKVRocksConfigMap(); err != nil {
 		return err
 	}
 	h.log.Info("kvrocks config ok")
@@ -50,6 +55,7 @@ func (h *KVRocksStandardHandler) ensureKVRocksReplication() error {
 				if err := h.kvrocks.ChangeMyselfToMaster(node.IP, h.password); err != nil {
 					return err
 				}
+				h.podEventf(node.PodIndex, corev1.EventTypeNormal, common.ReasonPromotedMaster, "promoted to master")
 				if err := h.updateKVRocksRole(node.PodIndex, kvrocks.RoleMaster); err != nil {
 					return err
 				}
@@ -118,7 +124,12 @@ func (h *KVRocksStandardHandler) updateKVRocksRole(podID int, role string) error
 	}
 	if pod.Labels[resources.KvrocksRole] != role {
 		pod.Labels[resources.KvrocksRole] = role
-		return h.k8s.UpdatePod(pod)
+		if err = h.k8s.UpdatePod(pod); err != nil {
+			return err
+		}
+	}
+	if role == kvrocks.RoleMaster {
+		return common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder).EnsureMasterGate(podName)
 	}
 	return nil
 }
@@ -132,6 +143,7 @@ func (h *KVRocksStandardHandler) SlaveOfMaster(node *kvrocks.Node, masterIP stri
 		if err = h.kvrocks.SlaveOf(node.IP, masterIP, h.password); err != nil {
 			return err
 		}
+		h.podEventf(node.PodIndex, corev1.EventTypeNormal, common.ReasonReplicaRepointed, "replicates from %s", masterIP)
 	}
 	if err = h.updateKVRocksRole(node.PodIndex, kvrocks.RoleSlaver); err != nil {
 		return err
@@ -139,3 +151,137 @@ func (h *KVRocksStandardHandler) SlaveOfMaster(node *kvrocks.Node, masterIP stri
 	node.Role = kvrocks.RoleSlaver
 	return nil
 }
+
+// ensureReadReplicas excludes the lagging slaves from the read service and publishes the endpoints
+func (h *KVRocksStandardHandler) ensureReadReplicas() error {
+	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder)
+	replication := commHandler.GetReplication(h.instance.Name, h.stsNodes)
+	if err := commHandler.EnsureReplicationGate(h.instance.Name, h.stsNodes, replication); err != nil {
+		return err
+	}
+	if err := h.updateStatusNodes(replication); err != nil {
+		return err
+	}
+	return commHandler.UpdateEndpoints()
+}
+
+// updateStatusNodes publishes the nodes with their replication health in status
+func (h *KVRocksStandardHandler) updateStatusNodes(replication map[int]*kvrocksv1alpha1.KVRocksReplication) error {
+	var nodes []kvrocksv1alpha1.KVRocksTopology
+	for _, node := range h.stsNodes {
+		if node == nil {
+			continue
+		}
+		nodes = append(nodes, kvrocksv1alpha1.KVRocksTopology{
+			Pod:         fmt.Sprintf("%s-%d", h.instance.Name, node.PodIndex),
+			Role:        node.Role,
+			Ip:          node.IP,
+			Port:        kvrocks.KVRocksPort,
+			Announce:    node.Announce,
+			Replication: replication[node.PodIndex],
+		})
+	}
+	changed := !reflect.DeepEqual(h.instance.Status.Nodes, nodes)
+	h.instance.Status.Nodes = nodes
+	return common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder).UpdateReplicationHealth(changed)
+}
+
+func (h *KVRocksStandardHandler) ensureConnectionSecret() error {
+	return common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder).EnsureConnectionSecret(nil)
+}
+
+func (h *KVRocksStandardHandler) ensureMonitoring() error {
+	return common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder).EnsureMonitoring()
+}
+
+// ensureRestart restarts the pods which were started with outdated restart-required configs
+func (h *KVRocksStandardHandler) ensureRestart() error {
+	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder)
+	pending, err := commHandler.EnsureRestart(h.key, h.stsNodes, func(master *kvrocks.Node) error {
+		return h.switchover(master, func(*kvrocks.Node) bool { return true })
+	})
+	if err != nil {
+		return err
+	}
+	if len(pending) != 0 {
+		h.requeue = true
+	}
+	return commHandler.UpdateRestartCondition(pending)
+}
+
+// ensureNodeDrain switches over the master on the draining node to a slave on another node, before it is evicted
+func (h *KVRocksStandardHandler) ensureNodeDrain() error {
+	draining, _, err := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder).ListDisruptedPods(h.key)
+	if err != nil || len(draining) == 0 {
+		return err
+	}
+	drained := map[int]bool{}
+	for _, pod := range draining {
+		index, err := resources.GetPVCOrPodIndex(pod.Name)
+		if err != nil {
+			return err
+		}
+		drained[index] = true
+	}
+	for _, node := range h.stsNodes {
+		if node.Role != kvrocks.RoleMaster || !drained[node.PodIndex] || len(drained) == len(h.stsNodes) {
+			continue
+		}
+		h.log.Info("node of master is draining, switch over", "pod", node.PodIndex)
+		h.requeue = true
+		return h.switchover(node, func(candidate *kvrocks.Node) bool {
+			return !drained[candidate.PodIndex]
+		})
+	}
+	return nil
+}
+
+// switchover promotes the accepted slave with the largest replication offset, and makes the others slave of it
+func (h *KVRocksStandardHandler) switchover(master *kvrocks.Node, accept func(candidate *kvrocks.Node) bool) error {
+	var candidate *kvrocks.Node
+	maxOffset := -1
+	for _, node := range h.stsNodes {
+		if node.IP == master.IP || !accept(node) {
+			continue
+		}
+		offset, err := h.kvrocks.GetOffset(node.IP, h.password)
+		if err != nil {
+			return err
+		}
+		if offset > maxOffset {
+			candidate = node
+			maxOffset = offset
+		}
+	}
+	if candidate == nil {
+		return errors.New("no suitable slave to switch over")
+	}
+	if err := h.kvrocks.ChangeMyselfToMaster(candidate.IP, h.password); err != nil {
+		return err
+	}
+	h.podEventf(candidate.PodIndex, corev1.EventTypeNormal, common.ReasonPromotedMaster, "promoted to master, switched over from %s", master.IP)
+	if err := h.updateKVRocksRole(candidate.PodIndex, kvrocks.RoleMaster); err != nil {
+		return err
+	}
+	candidate.Role = kvrocks.RoleMaster
+	for _, node := range h.stsNodes {
+		if node.IP == candidate.IP {
+			continue
+		}
+		if err := h.SlaveOfMaster(node, candidate.IP); err != nil {
+			return err
+		}
+	}
+	h.log.Info("switch over successfully", "from", master.IP, "to", candidate.IP)
+	// notify sentinel to update
+	if v, ok := h.instance.Labels[resources.MonitoredBy]; ok {
+		return sentinel.UpdateSentinelAnnotationCount(h.k8s, h.instance.Namespace, v)
+	}
+	return nil
+}
+
+// podEventf records an event against the kvrocks and the pod of the node
+func (h *KVRocksStandardHandler) podEventf(podIndex int, eventtype, reason, messageFmt string, args ...interface{}) {
+	podName := fmt.Sprintf("%s-%d", h.instance.Name, podIndex)
+	common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder).PodEventf(podName, eventtype, reason, messageFmt, args...)
+}
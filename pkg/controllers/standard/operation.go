@@ -0,0 +1,177 @@
+package standard
+
+import (
+	"fmt"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+	"github.com/RocksLabs/kvrocks-operator/pkg/controllers/common"
+	"github.com/RocksLabs/kvrocks-operator/pkg/resources"
+)
+
+// ensureOperation runs a step of the oldest unfinished KVRocksOperation, the kvrocks is requeued until it finishes
+func (h *KVRocksStandardHandler) ensureOperation() error {
+	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder)
+	op, err := commHandler.NextOperation()
+	if err != nil || op == nil {
+		return err
+	}
+	done, err := h.runOperation(op)
+	running, err := commHandler.UpdateOperation(op, done, err)
+	if running {
+		h.requeue = true
+	}
+	return err
+}
+
+// runOperation starts the operation or checks if its current step is completed, it returns true if the operation
+// is completed
+func (h *KVRocksStandardHandler) runOperation(op *kvrocksv1alpha1.KVRocksOperation) (bool, error) {
+	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder)
+	master := h.getMaster()
+	if master == nil {
+		return false, nil
+	}
+	target, err := h.getOperationTarget(op)
+	if err != nil {
+		return false, err
+	}
+	switch op.Status.Step {
+	case "":
+		op.Status.PreviousMaster = h.podName(master)
+		switch op.Spec.Type {
+		case kvrocksv1alpha1.SwitchoverOperation, kvrocksv1alpha1.FailoverOperation:
+			return h.promote(op, master, target)
+		case kvrocksv1alpha1.RestartPodOperation, kvrocksv1alpha1.ReplaceNodeOperation:
+			if target.Role == kvrocks.RoleMaster {
+				return h.promote(op, master, nil)
+			}
+			return false, h.deleteOperationPod(op, target)
+		case kvrocksv1alpha1.ResyncOperation:
+			if target.Role == kvrocks.RoleMaster {
+				return false, common.NewOperationError("%s is the master, switch over before resync", h.podName(target))
+			}
+			return false, h.deleteOperationPod(op, target)
+		}
+		return false, common.NewOperationError("unknown operation type %s", op.Spec.Type)
+	case common.OperationStepWaitPromotion:
+		if h.podName(master) == op.Status.PreviousMaster {
+			return false, nil
+		}
+		if op.Spec.Type == kvrocksv1alpha1.RestartPodOperation || op.Spec.Type == kvrocksv1alpha1.ReplaceNodeOperation {
+			return false, h.deleteOperationPod(op, target)
+		}
+		op.Status.Message = fmt.Sprintf("%s is promoted to master", h.podName(master))
+		return true, nil
+	case common.OperationStepWaitReady:
+		recreated, err := commHandler.IsPodRecreated(op, op.Spec.Pod)
+		if recreated {
+			op.Status.Message = fmt.Sprintf("%s is recreated", op.Spec.Pod)
+		}
+		return recreated, err
+	case common.OperationStepWaitSync:
+		recreated, err := commHandler.IsPodRecreated(op, op.Spec.Pod)
+		if err != nil || !recreated {
+			return false, err
+		}
+		synced, err := commHandler.IsReplicaSynced(master, target)
+		if synced {
+			op.Status.Message = fmt.Sprintf("%s is resynced from %s", op.Spec.Pod, h.podName(master))
+		}
+		return synced, err
+	}
+	return false, common.NewOperationError("unknown step %s", op.Status.Step)
+}
+
+// promote switches over the master to the target, or to the slave with the largest offset. A Failover is done by
+// the sentinel if the kvrocks is monitored
+func (h *KVRocksStandardHandler) promote(op *kvrocksv1alpha1.KVRocksOperation, master, target *kvrocks.Node) (bool, error) {
+	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder)
+	if target == master {
+		op.Status.Message = fmt.Sprintf("%s is already the master", h.podName(master))
+		return true, nil
+	}
+	candidate := target
+	if candidate == nil {
+		maxOffset := -1
+		for _, node := range h.stsNodes {
+			if node.Role == kvrocks.RoleMaster {
+				continue
+			}
+			offset, err := h.kvrocks.GetOffset(node.IP, h.password)
+			if err != nil {
+				return false, err
+			}
+			if offset > maxOffset {
+				candidate = node
+				maxOffset = offset
+			}
+		}
+		if candidate == nil {
+			return false, common.NewOperationError("no slave to promote")
+		}
+	}
+	if !op.Spec.Force {
+		if err := commHandler.CheckReplicaInSync(master, candidate); err != nil {
+			return false, err
+		}
+	}
+	if _, ok := h.instance.Labels[resources.MonitoredBy]; ok && op.Spec.Type == kvrocksv1alpha1.FailoverOperation {
+		sentinel, err := commHandler.FailoverMonitor()
+		if err != nil {
+			return false, err
+		}
+		return false, commHandler.SetOperationStep(op, common.OperationStepWaitPromotion, "sentinel %s is failing over %s", sentinel, h.podName(master))
+	}
+	if err := h.switchover(master, func(node *kvrocks.Node) bool { return node == candidate }); err != nil {
+		return false, err
+	}
+	return false, commHandler.SetOperationStep(op, common.OperationStepWaitPromotion, "%s is switched over to %s", h.podName(master), h.podName(candidate))
+}
+
+// deleteOperationPod deletes the target pod, with its pvc if the node is replaced or resynced
+func (h *KVRocksStandardHandler) deleteOperationPod(op *kvrocksv1alpha1.KVRocksOperation, target *kvrocks.Node) error {
+	commHandler := common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder)
+	wipe := op.Spec.Type != kvrocksv1alpha1.RestartPodOperation
+	if err := commHandler.DeleteOperationPod(op, h.podName(target), wipe); err != nil {
+		return err
+	}
+	if op.Spec.Type == kvrocksv1alpha1.ResyncOperation {
+		return commHandler.SetOperationStep(op, common.OperationStepWaitSync, "waiting for %s to full sync", h.podName(target))
+	}
+	return commHandler.SetOperationStep(op, common.OperationStepWaitReady, "waiting for %s to be recreated", h.podName(target))
+}
+
+// getOperationTarget returns the node of spec.pod, it is required except for Switchover and Failover
+func (h *KVRocksStandardHandler) getOperationTarget(op *kvrocksv1alpha1.KVRocksOperation) (*kvrocks.Node, error) {
+	if op.Spec.Pod == "" {
+		if op.Spec.Type == kvrocksv1alpha1.SwitchoverOperation || op.Spec.Type == kvrocksv1alpha1.FailoverOperation {
+			return nil, nil
+		}
+		return nil, common.NewOperationError("spec.pod is required by %s", op.Spec.Type)
+	}
+	for _, node := range h.stsNodes {
+		if h.podName(node) == op.Spec.Pod {
+			return node, nil
+		}
+	}
+	return nil, common.NewOperationError("pod %s is not a node of %s", op.Spec.Pod, h.instance.Name)
+}
+
+// ensureReseed resyncs the slaves out of sync for too long by the reseed policy
+func (h *KVRocksStandardHandler) ensureReseed() error {
+	return common.NewCommandHandler(h.instance, h.k8s, h.kvrocks, h.password, h.recorder).EnsureReseed()
+}
+
+func (h *KVRocksStandardHandler) getMaster() *kvrocks.Node {
+	for _, node := range h.stsNodes {
+		if node.Role == kvrocks.RoleMaster {
+			return node
+		}
+	}
+	return nil
+}
+
+func (h *KVRocksStandardHandler) podName(node *kvrocks.Node) string {
+	return fmt.Sprintf("%s-%d", h.instance.Name, node.PodIndex)
+}
@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+)
+
+const namespace = "kvrocks_operator"
+
+const (
+	FailoverHandled    = "handled"
+	FailoverFailed     = "failed"
+	FailoverSuppressed = "suppressed"
+)
+
+var (
+	// ReconcileStepDuration observes the duration of each step of the handlers
+	ReconcileStepDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "reconcile_step_duration_seconds",
+		Help:      "Duration of the reconcile steps of the kvrocks handlers.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 4, 8),
+	}, []string{"type", "step"})
+
+	// Failovers counts the failover messages from sentinel which are handled, failed or suppressed
+	Failovers = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "failover_total",
+		Help:      "Number of the cluster failovers handled by the operator, partitioned by result.",
+	}, []string{"namespace", "name", "result"})
+
+	// EventQueueDepth is the number of the failover messages waiting to be handled
+	EventQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "event_queue_depth",
+		Help:      "Number of the failover messages waiting in the event queue.",
+	})
+
+	// SentinelSubscriptions is the number of the sentinel pods subscribed for the odown messages
+	SentinelSubscriptions = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "sentinel_subscriptions",
+		Help:      "Number of the active sentinel subscriptions.",
+	})
+
+	// SlotsMigrated counts the slots moved between the shards
+	SlotsMigrated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "slots_migrated_total",
+		Help:      "Number of the slots migrated between the shards.",
+	}, []string{"namespace", "name"})
+
+	// SlotMigrationFailures counts the failed slot migrations, each retry is counted
+	SlotMigrationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "slot_migration_failures_total",
+		Help:      "Number of the failed slot migrations, including retries.",
+	}, []string{"namespace", "name"})
+
+	// InstanceStatus is 1 for the current status of the kvrocks
+	InstanceStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "instance_status",
+		Help:      "Current status of the kvrocks, the series of the current status is 1.",
+	}, []string{"namespace", "name", "type", "status"})
+
+	// TopologyVersion is the topology version in the kvrocks status
+	TopologyVersion = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "topology_version",
+		Help:      "Topology version of the kvrocks.",
+	}, []string{"namespace", "name"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		ReconcileStepDuration,
+		Failovers,
+		EventQueueDepth,
+		SentinelSubscriptions,
+		SlotsMigrated,
+		SlotMigrationFailures,
+		InstanceStatus,
+		TopologyVersion,
+	)
+}
+
+// ObserveStep runs the reconcile step and observes its duration
+func ObserveStep(kind kvrocksv1alpha1.KVRocksType, step string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	ReconcileStepDuration.WithLabelValues(string(kind), step).Observe(time.Since(start).Seconds())
+	return err
+}
+
+// RecordInstance updates the status and topology version gauges of the kvrocks
+func RecordInstance(instance *kvrocksv1alpha1.KVRocks) {
+	key := prometheus.Labels{"namespace": instance.Namespace, "name": instance.Name}
+	InstanceStatus.DeletePartialMatch(key)
+	InstanceStatus.WithLabelValues(instance.Namespace, instance.Name, string(instance.Spec.Type), string(instance.Status.Status)).Set(1)
+	TopologyVersion.With(key).Set(float64(instance.Status.Version))
+}
+
+// DeleteInstance removes the series of the deleted kvrocks
+func DeleteInstance(key types.NamespacedName) {
+	labels := prometheus.Labels{"namespace": key.Namespace, "name": key.Name}
+	InstanceStatus.DeletePartialMatch(labels)
+	TopologyVersion.Delete(labels)
+	Failovers.DeletePartialMatch(labels)
+	SlotsMigrated.Delete(labels)
+	SlotMigrationFailures.Delete(labels)
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+)
+
+func TestRecordInstance(t *testing.T) {
+	assert := assert.New(t)
+
+	newInstance := func(name string, status kvrocksv1alpha1.KVRocksStatusType, version int) *kvrocksv1alpha1.KVRocks {
+		return &kvrocksv1alpha1.KVRocks{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "unit-test"},
+			Spec:       kvrocksv1alpha1.KVRocksSpec{Type: kvrocksv1alpha1.ClusterType},
+			Status:     kvrocksv1alpha1.KVRocksStatus{Status: status, Version: version},
+		}
+	}
+	RecordInstance(newInstance("other", kvrocksv1alpha1.StatusRunning, 1))
+
+	RecordInstance(newInstance("test", kvrocksv1alpha1.StatusCreating, 1))
+	assert.Equal(float64(1), testutil.ToFloat64(InstanceStatus.WithLabelValues("unit-test", "test", "cluster", string(kvrocksv1alpha1.StatusCreating))))
+	assert.Equal(float64(1), testutil.ToFloat64(TopologyVersion.WithLabelValues("unit-test", "test")))
+
+	// the series of the previous status is replaced
+	RecordInstance(newInstance("test", kvrocksv1alpha1.StatusRunning, 2))
+	assert.Equal(float64(1), testutil.ToFloat64(InstanceStatus.WithLabelValues("unit-test", "test", "cluster", string(kvrocksv1alpha1.StatusRunning))))
+	assert.Equal(float64(2), testutil.ToFloat64(TopologyVersion.WithLabelValues("unit-test", "test")))
+	assert.Equal(2, testutil.CollectAndCount(InstanceStatus))
+
+	Failovers.WithLabelValues("unit-test", "test", FailoverHandled).Inc()
+	SlotsMigrated.WithLabelValues("unit-test", "test").Add(10)
+	SlotMigrationFailures.WithLabelValues("unit-test", "test").Inc()
+	DeleteInstance(types.NamespacedName{Namespace: "unit-test", Name: "test"})
+	assert.Equal(1, testutil.CollectAndCount(InstanceStatus))
+	assert.Equal(1, testutil.CollectAndCount(TopologyVersion))
+	assert.Equal(0, testutil.CollectAndCount(Failovers))
+	assert.Equal(0, testutil.CollectAndCount(SlotsMigrated))
+	assert.Equal(0, testutil.CollectAndCount(SlotMigrationFailures))
+	assert.Equal(float64(1), testutil.ToFloat64(InstanceStatus.WithLabelValues("unit-test", "other", "cluster", string(kvrocksv1alpha1.StatusRunning))))
+}
@@ -2,7 +2,10 @@ package resources
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"fmt"
+	"sort"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -11,31 +14,17 @@ import (
 	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
 )
 
-var UnChangeCfg = map[string]struct{}{
-	"daemonize":                             {},
-	"bind":                                  {},
-	"port":                                  {},
-	"workers":                               {},
-	"tcp-backlog":                           {},
-	"slaveof":                               {},
-	"db-name":                               {},
-	"dir":                                   {},
-	"backup-dir":                            {},
-	"log-dir":                               {},
-	"pidfile":                               {},
-	"supervised":                            {},
-	"rename-command":                        {},
-	"rocksdb.block_size":                    {},
-	"rocksdb.wal_size_limit_mb":             {},
-	"rocksdb.enable_pipelined_write":        {},
-	"rocksdb.cache_index_and_filter_blocks": {},
-	"rocksdb.subkey_block_cache_size":       {},
-	"rocksdb.metadata_block_cache_size":     {},
-	"rocksdb.share_metadata_and_subkey_block_cache": {},
-	"rocksdb.row_cache_size":                        {},
-	"masterauth":                                    {},
-	"requirepass":                                   {},
-	"cluster-enabled":                               {},
+// UnChangeCfg lists the configs which can not be changed by CONFIG SET
+var UnChangeCfg = unchangeableConfigs()
+
+func unchangeableConfigs() map[string]struct{} {
+	cfg := map[string]struct{}{}
+	for key, schema := range kvrocksv1alpha1.KVRocksConfigSchema {
+		if !schema.Dynamic {
+			cfg[key] = struct{}{}
+		}
+	}
+	return cfg
 }
 
 const (
@@ -45,13 +34,21 @@ const (
 	sentinelDefaultUser = "user default +@all -acl -sentinel +sentinel|master +sentinel|replicas +sentinel|sentinels +sentinel|get-master-addr-by-name +sentinel|is-master-down-by-addr +sentinel|slaves on nopass\n"
 	// sentinel -> kvrocks
 	// sentinelUser = "user sentinel allchannels +multi +slaveof +ping +exec +subscribe +config|rewrite +role +publish +info +client|setname +client|kill +script|kill on >%s\n"
+	// the masters are monitored by the dns names of the pods
+	sentinelHostnames = "sentinel resolve-hostnames yes\nsentinel announce-hostnames yes\n"
 )
 
 const (
+	// kvrocks runs with a writable copy of the config file so that CONFIG REWRITE can persist the live config,
+	// the copy is only replaced when the config file in configMap changes
 	start = `
 #!/bin/bash
 sleep 15
-./bin/kvrocks -c /var/lib/kvrocks/conf/kvrocks.conf
+if ! cmp -s /var/lib/kvrocks/conf/kvrocks.conf /var/lib/kvrocks/kvrocks.conf.orig; then
+  cp /var/lib/kvrocks/conf/kvrocks.conf /var/lib/kvrocks/kvrocks.conf
+  cp /var/lib/kvrocks/conf/kvrocks.conf /var/lib/kvrocks/kvrocks.conf.orig
+fi
+./bin/kvrocks -c /var/lib/kvrocks/kvrocks.conf
 `
 
 	readinessProbe = `
@@ -64,6 +61,7 @@ func NewSentinelConfigMap(instance *kvrocksv1alpha1.KVRocks) *corev1.ConfigMap {
 	var buffer bytes.Buffer
 	buffer.WriteString(sentinelDefaultUser)
 	buffer.WriteString(fmt.Sprintf(superUser, instance.Spec.Password))
+	buffer.WriteString(sentinelHostnames)
 	configMap := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      instance.Name,
@@ -82,8 +80,15 @@ func NewSentinelConfigMap(instance *kvrocksv1alpha1.KVRocks) *corev1.ConfigMap {
 
 func NewKVRocksConfigMap(instance *kvrocksv1alpha1.KVRocks) *corev1.ConfigMap {
 	var buffer bytes.Buffer
-	// add kvrocks config
-	for k, v := range instance.Spec.KVRocksConfig {
+	// add kvrocks config, sorted to keep the file stable
+	config := GetKVRocksConfig(instance)
+	keys := make([]string, 0, len(config))
+	for k := range config {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := config[k]
 		if v == "" {
 			buffer.WriteString(fmt.Sprintf("%s \"\"\n", k))
 		} else {
@@ -108,6 +113,10 @@ func NewKVRocksConfigMap(instance *kvrocksv1alpha1.KVRocks) *corev1.ConfigMap {
 				*metav1.NewControllerRef(instance, instance.GroupVersionKind()),
 			},
 			Labels: instance.Labels,
+			Annotations: map[string]string{
+				ConfigHash:     RestartConfigHash(config),
+				ConfigHashTime: time.Now().UTC().Format(time.RFC3339),
+			},
 		},
 		Data: map[string]string{
 			"kvrocks.conf":       buffer.String(),
@@ -118,6 +127,12 @@ func NewKVRocksConfigMap(instance *kvrocksv1alpha1.KVRocks) *corev1.ConfigMap {
 	}
 }
 
+// GetKVRocksConfig returns the valid configs of kvrocksConfig and rocksDBConfig, the invalid ones are ignored
+func GetKVRocksConfig(instance *kvrocksv1alpha1.KVRocks) map[string]string {
+	config, _ := instance.Spec.ValidateConfigs()
+	return config
+}
+
 func ParseKVRocksConfigs(config map[string]string) map[string]string {
 	cfg := make(map[string]string)
 	for key, value := range config {
@@ -129,6 +144,33 @@ func ParseKVRocksConfigs(config map[string]string) map[string]string {
 	return cfg
 }
 
+// ParseRestartConfigs returns the configs which only take effect after kvrocks restarts
+func ParseRestartConfigs(config map[string]string) map[string]string {
+	cfg := make(map[string]string)
+	for key, value := range config {
+		if _, ok := UnChangeCfg[key]; ok {
+			cfg[key] = value
+		}
+	}
+	return cfg
+}
+
+// RestartConfigHash returns the hash of the restart-required configs,
+// a pod started with a different hash must be restarted to apply them
+func RestartConfigHash(config map[string]string) string {
+	cfg := ParseRestartConfigs(config)
+	keys := make([]string, 0, len(cfg))
+	for key := range cfg {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var buffer bytes.Buffer
+	for _, key := range keys {
+		buffer.WriteString(fmt.Sprintf("%s %s\n", key, cfg[key]))
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(buffer.Bytes()))[:16]
+}
+
 func NewKVRocksControllerConfigmap(instance *kvrocksv1alpha1.KVRocks) *corev1.ConfigMap {
 	configYAML := fmt.Sprintf(`
 addr: "0.0.0.0:%d"
@@ -157,3 +199,28 @@ etcd:
 		},
 	}
 }
+
+// HistoryKey is the key of the operation history in the configMap, one operation per line in json
+const HistoryKey = "operations"
+
+// HistoryLimit is the max number of the operations kept in the history of a kvrocks
+var HistoryLimit = 200
+
+// NewHistoryConfigMap returns the empty configMap of the operation history
+func NewHistoryConfigMap(instance *kvrocksv1alpha1.KVRocks) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetHistoryConfigMapName(instance.Name),
+			Namespace: instance.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(instance, instance.GroupVersionKind()),
+			},
+			Labels: instance.Labels,
+		},
+		Data: map[string]string{},
+	}
+}
+
+func GetHistoryConfigMapName(name string) string {
+	return name + "-history"
+}
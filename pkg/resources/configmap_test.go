@@ -0,0 +1,62 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+)
+
+func TestRestartConfigHash(t *testing.T) {
+	base := map[string]string{"workers": "8", "maxclients": "100"}
+
+	tests := []struct {
+		name     string
+		config   map[string]string
+		expEqual bool
+	}{
+		{
+			name:     "The same configs should have the same hash.",
+			config:   map[string]string{"maxclients": "100", "workers": "8"},
+			expEqual: true,
+		}, {
+			name:     "Changing an online changeable config should keep the hash.",
+			config:   map[string]string{"workers": "8", "maxclients": "200"},
+			expEqual: true,
+		}, {
+			name:     "Changing a restart-required config should change the hash.",
+			config:   map[string]string{"workers": "16", "maxclients": "100"},
+			expEqual: false,
+		}, {
+			name:     "Adding a restart-required config should change the hash.",
+			config:   map[string]string{"workers": "8", "maxclients": "100", "tcp-backlog": "1024"},
+			expEqual: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			hash := RestartConfigHash(test.config)
+			assert.Len(hash, 16)
+			assert.Equal(test.expEqual, hash == RestartConfigHash(base))
+		})
+	}
+}
+
+func TestNewSentinelConfigMap(t *testing.T) {
+	assert := assert.New(t)
+
+	instance := &kvrocksv1alpha1.KVRocks{
+		ObjectMeta: metav1.ObjectMeta{Name: "sentinel", Namespace: "unit-test"},
+		Spec:       kvrocksv1alpha1.KVRocksSpec{Password: "password"},
+	}
+	config := NewSentinelConfigMap(instance).Data["sentinel.conf"]
+	// the masters are monitored by the dns names of the pods
+	assert.Contains(config, "sentinel resolve-hostnames yes\n")
+	assert.Contains(config, "sentinel announce-hostnames yes\n")
+	assert.Contains(config, "user superuser ~* +@all on >password\n")
+}
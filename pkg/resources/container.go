@@ -31,9 +31,9 @@ func NewInstanceContainer(instance *kvrocksv1alpha1.KVRocks) *corev1.Container {
 }
 
 func NewExporterContainer(instance *kvrocksv1alpha1.KVRocks) *corev1.Container {
-	return &corev1.Container{
+	container := &corev1.Container{
 		Name:  "kvrocks-exporter",
-		Image: "hulkdev/kvrocks-exporter:latest",
+		Image: ExporterImage,
 		Args: []string{
 			fmt.Sprintf("--kvrocks.addr=http://localhost:%s", strconv.Itoa(kvrocks.KVRocksPort)),
 			fmt.Sprintf("--kvrocks.password=%s", instance.Spec.Password),
@@ -45,6 +45,24 @@ func NewExporterContainer(instance *kvrocksv1alpha1.KVRocks) *corev1.Container {
 			},
 		},
 	}
+	exporter := instance.Spec.Exporter
+	if exporter == nil {
+		return container
+	}
+	if exporter.Image != "" {
+		container.Image = exporter.Image
+	}
+	container.ImagePullPolicy = exporter.ImagePullPolicy
+	if exporter.Resources != nil {
+		container.Resources = *exporter.Resources
+	}
+	container.Args = append(container.Args, exporter.ExtraArgs...)
+	return container
+}
+
+// IsExporterEnabled checks if the exporter sidecar is added to the kvrocks pods, it is enabled by default
+func IsExporterEnabled(instance *kvrocksv1alpha1.KVRocks) bool {
+	return instance.Spec.Exporter == nil || instance.Spec.Exporter.Enabled == nil || *instance.Spec.Exporter.Enabled
 }
 
 func newSentinelContainer(instance *kvrocksv1alpha1.KVRocks) *corev1.Container {
@@ -33,6 +33,7 @@ func NewSentinelDeployment(instance *kvrocksv1alpha1.KVRocks) *appsv1.Deployment
 				},
 				Spec: corev1.PodSpec{
 					Affinity:                      getAffinity(instance, labels),
+					TopologySpreadConstraints:     getTopologySpreadConstraints(instance),
 					NodeSelector:                  instance.Spec.NodeSelector,
 					Tolerations:                   instance.Spec.Toleration,
 					TerminationGracePeriodSeconds: &TerminationGracePeriodSeconds,
@@ -57,6 +58,9 @@ func NewSentinelDeployment(instance *kvrocksv1alpha1.KVRocks) *appsv1.Deployment
 
 	dep.Spec.Template.Spec.Containers = append(dep.Spec.Template.Spec.Containers, *NewSentinelContainer(instance))
 
+	applyPodTemplate(instance, &dep.Spec.Template)
+	setTemplateHash(&dep.ObjectMeta, &dep.Spec.Template)
+
 	return dep
 }
 
@@ -80,7 +84,7 @@ func NewKVRocksControllerDeployment(instance *kvrocksv1alpha1.KVRocks) *appsv1.D
 	labels := map[string]string{"app": "kvrocks-controller"}
 	replicas := int32(1)
 
-	return &appsv1.Deployment{
+	dep := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      kvrocks.ControllerDeploymentName,
 			Namespace: instance.Namespace,
@@ -101,7 +105,7 @@ func NewKVRocksControllerDeployment(instance *kvrocksv1alpha1.KVRocks) *appsv1.D
 					Containers: []corev1.Container{
 						{
 							Name:  "controller",
-							Image: "jinxu95/kvrocks-controller:latest",
+							Image: ControllerImage,
 							Ports: []corev1.ContainerPort{
 								{
 									ContainerPort: kvrocks.ControllerPort,
@@ -132,4 +136,7 @@ func NewKVRocksControllerDeployment(instance *kvrocksv1alpha1.KVRocks) *appsv1.D
 			},
 		},
 	}
+	applyPodTemplate(instance, &dep.Spec.Template)
+	setTemplateHash(&dep.ObjectMeta, &dep.Spec.Template)
+	return dep
 }
@@ -14,9 +14,14 @@ import (
 	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
 )
 
-// redis image
-
-const SentinelImage = "redis:6.2.4"
+// the images of the components, which can be set by the operator flags
+var (
+	// SentinelImage is the default image of the sentinels created for kvrocks, the kvrocks image is used if it is empty
+	SentinelImage   = ""
+	ExporterImage   = "hulkdev/kvrocks-exporter:latest"
+	ControllerImage = "jinxu95/kvrocks-controller:latest"
+	EtcdImage       = "quay.io/coreos/etcd:latest"
+)
 
 var (
 	ErrorPasswordEmpty        = "passworUnreasonable"
@@ -64,26 +69,54 @@ func ParseRedisName(name string) (string, string) {
 	return fields[2], fields[3]
 }
 
+// IsPaused checks if the reconciliation of the kvrocks is paused by annotation
+func IsPaused(instance *kvrocksv1alpha1.KVRocks) bool {
+	return instance.Annotations[Paused] == "true"
+}
+
+// IsUnderMaintenance checks if the failover, pvc deletion and scaling of the kvrocks are suppressed by annotation
+func IsUnderMaintenance(instance *kvrocksv1alpha1.KVRocks) bool {
+	return instance.Annotations[Maintenance] == "true"
+}
+
 func GetSentinelInstance(instance *kvrocksv1alpha1.KVRocks) *kvrocksv1alpha1.KVRocks {
 	system, _ := ParseRedisName(instance.Name)
-	return &kvrocksv1alpha1.KVRocks{
+	sentinel := &kvrocksv1alpha1.KVRocks{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      GetSentinelName(system),
 			Namespace: instance.Namespace,
 		},
 		Spec: kvrocksv1alpha1.KVRocksSpec{
-			Image:           instance.Spec.Image,
-			ImagePullPolicy: instance.Spec.ImagePullPolicy,
-			Type:            instance.Spec.Type,
-			KVRocksConfig:   nil,
-			Replicas:        instance.Spec.Replicas,
-			Password:        instance.Spec.Password,
-			Resources:       instance.Spec.Resources,
-			NodeSelector:    instance.Spec.NodeSelector,
-			Toleration:      instance.Spec.Toleration,
-			Affinity:        instance.Spec.Affinity,
+			Image:                     instance.Spec.Image,
+			ImagePullPolicy:           instance.Spec.ImagePullPolicy,
+			Type:                      instance.Spec.Type,
+			KVRocksConfig:             nil,
+			Replicas:                  instance.Spec.Replicas,
+			Password:                  instance.Spec.Password,
+			Resources:                 instance.Spec.Resources,
+			NodeSelector:              instance.Spec.NodeSelector,
+			Toleration:                instance.Spec.Toleration,
+			Affinity:                  instance.Spec.Affinity,
+			TopologySpreadConstraints: instance.Spec.TopologySpreadConstraints,
+			Placement:                 instance.Spec.Placement,
+			PodTemplate:               instance.Spec.PodTemplate,
 		},
 	}
+	if SentinelImage != "" {
+		sentinel.Spec.Image = SentinelImage
+	}
+	if template := instance.Spec.SentinelTemplate; template != nil {
+		if template.Image != "" {
+			sentinel.Spec.Image = template.Image
+		}
+		if template.ImagePullPolicy != "" {
+			sentinel.Spec.ImagePullPolicy = template.ImagePullPolicy
+		}
+		if template.Resources != nil {
+			sentinel.Spec.Resources = template.Resources
+		}
+	}
+	return sentinel
 }
 
 var key = []byte{'0', '1', '2', '3', '4', '5', '6', '7', '8', '9',
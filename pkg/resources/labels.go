@@ -7,6 +7,31 @@ import (
 const (
 	MonitoredBy = "kvrocks/monitored-by"
 	KvrocksRole = "kvrocks/role"
+	// ConfigHash annotates the pod with the hash of the restart-required configs it was started with, and the
+	// configMap with the hash of the configs it holds
+	ConfigHash = "kvrocks/config-hash"
+	// ConfigHashTime annotates the configMap with the time its hash is changed, the pods created later are started
+	// with the configs it holds
+	ConfigHashTime = "kvrocks/config-hash-time"
+	// TemplateHash annotates the workload with the hash of the pod template rendered by the operator, the template is
+	// rolled out when the hash is changed
+	TemplateHash = "kvrocks/template-hash"
+	// PVCRetained labels the pvc retained by the retention policy with the reason, scaled or deleted
+	PVCRetained = "kvrocks/pvc-retained"
+	// Expose labels the exposed services of single pods
+	Expose = "kvrocks/expose"
+	// NodeDrain annotates the node which is going to be drained, the masters on it are switched over in advance
+	NodeDrain = "kvrocks/drain"
+	// Paused annotates the kvrocks whose reconciliation is paused, only its observed status is updated
+	Paused = "kvrocks.apache.org/paused"
+	// Maintenance annotates the kvrocks under maintenance, its health is still reported but failover, pvc deletion
+	// and scaling are suppressed
+	Maintenance = "kvrocks.apache.org/maintenance"
+)
+
+const (
+	PVCRetainedScaled  = "scaled"
+	PVCRetainedDeleted = "deleted"
 )
 
 func MergeLabels(allLabels ...map[string]string) map[string]string {
@@ -44,6 +69,12 @@ func SelectorLabels(instance *kvrocksv1alpha1.KVRocks) map[string]string {
 	}
 }
 
+func PodServiceLabels() map[string]string {
+	return map[string]string{
+		Expose: "pod",
+	}
+}
+
 func SentinelLabels() map[string]string {
 	return map[string]string{
 		"sentinel": "true",
@@ -0,0 +1,127 @@
+package resources
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+)
+
+// the kinds of the Prometheus operator, they are built as unstructured objects since the CRDs may be absent
+var (
+	PodMonitorGVK     = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PodMonitor"}
+	PrometheusRuleGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PrometheusRule"}
+)
+
+const DefaultDiskUsagePercent = 85
+
+// the pod labels copied to the metrics, they are sanitized to kvrocks_name, kvrocks_role and kvrocks_statefulset
+var podTargetLabels = []interface{}{"kvrocks/name", "kvrocks/role", "kvrocks/statefulset"}
+
+func NewPodMonitor(instance *kvrocksv1alpha1.KVRocks) *unstructured.Unstructured {
+	endpoint := map[string]interface{}{
+		"port": "exporter",
+		"path": "/metrics",
+	}
+	if instance.Spec.Monitoring != nil && instance.Spec.Monitoring.Interval != "" {
+		endpoint["interval"] = instance.Spec.Monitoring.Interval
+	}
+	return newMonitoringObject(instance, PodMonitorGVK, map[string]interface{}{
+		"selector": map[string]interface{}{
+			"matchLabels": toInterfaceMap(instance.Labels),
+		},
+		"namespaceSelector": map[string]interface{}{
+			"matchNames": []interface{}{instance.Namespace},
+		},
+		"podTargetLabels":     podTargetLabels,
+		"podMetricsEndpoints": []interface{}{endpoint},
+	})
+}
+
+// NewPrometheusRule returns the alerts of the kvrocks, the metrics are selected by the namespace and the
+// kvrocks_name label of the PodMonitor
+func NewPrometheusRule(instance *kvrocksv1alpha1.KVRocks) *unstructured.Unstructured {
+	selector := fmt.Sprintf(`namespace="%s",kvrocks_name="%s"`, instance.Namespace, instance.Name)
+	masters := 1
+	if instance.Spec.Type == kvrocksv1alpha1.ClusterType {
+		masters = int(instance.Spec.Master)
+	}
+	diskUsage := DefaultDiskUsagePercent
+	if instance.Spec.Monitoring != nil && instance.Spec.Monitoring.DiskUsagePercent != 0 {
+		diskUsage = int(instance.Spec.Monitoring.DiskUsagePercent)
+	}
+	rules := []interface{}{
+		newAlert("KVRocksMasterMissing", "critical", "1m",
+			fmt.Sprintf(`(count(kvrocks_instance_info{%s,role="master"}) or vector(0)) < %d`, selector, masters),
+			fmt.Sprintf("kvrocks %s/%s has fewer masters than %d", instance.Namespace, instance.Name, masters)),
+		newAlert("KVRocksReplicationLag", "warning", "5m",
+			fmt.Sprintf(`kvrocks_master_repl_offset{%s} - on(namespace, pod) group_right() kvrocks_connected_slave_offset_bytes{%s} > %d`,
+				selector, selector, GetMaxReplicationLag(instance)),
+			"slave {{ $labels.slave_ip }} of {{ $labels.pod }} lags behind the master"),
+		newAlert("KVRocksDiskNearFull", "warning", "5m",
+			fmt.Sprintf(`kubelet_volume_stats_used_bytes{namespace="%s",persistentvolumeclaim=~"data-%s-.*"} / kubelet_volume_stats_capacity_bytes{namespace="%s",persistentvolumeclaim=~"data-%s-.*"} * 100 > %d`,
+				instance.Namespace, instance.Name, instance.Namespace, instance.Name, diskUsage),
+			fmt.Sprintf("volume {{ $labels.persistentvolumeclaim }} is more than %d%% full", diskUsage)),
+	}
+	if instance.Spec.Type == kvrocksv1alpha1.ClusterType {
+		rules = append(rules, newAlert("KVRocksSlotsNotCovered", "critical", "1m",
+			fmt.Sprintf(`min(kvrocks_cluster_slots_ok{%s}) < %d`, selector, kvrocks.MaxSlotID+1),
+			fmt.Sprintf("not all slots of kvrocks %s/%s are served", instance.Namespace, instance.Name)))
+	}
+	if sentinel, ok := instance.Labels[MonitoredBy]; ok {
+		deployment := fmt.Sprintf(`namespace="%s",deployment="%s"`, instance.Namespace, GetDeploymentName(sentinel))
+		rules = append(rules, newAlert("KVRocksSentinelQuorumLost", "critical", "1m",
+			fmt.Sprintf(`kube_deployment_status_replicas_available{%s} < floor(kube_deployment_spec_replicas{%s} / 2) + 1`, deployment, deployment),
+			fmt.Sprintf("sentinel %s can not authorize a failover of kvrocks %s", sentinel, instance.Name)))
+	}
+	return newMonitoringObject(instance, PrometheusRuleGVK, map[string]interface{}{
+		"groups": []interface{}{
+			map[string]interface{}{
+				"name":  "kvrocks-" + instance.Name,
+				"rules": rules,
+			},
+		},
+	})
+}
+
+func newAlert(name, severity, duration, expr, summary string) map[string]interface{} {
+	return map[string]interface{}{
+		"alert": name,
+		"expr":  expr,
+		"for":   duration,
+		"labels": map[string]interface{}{
+			"severity": severity,
+		},
+		"annotations": map[string]interface{}{
+			"summary": summary,
+		},
+	}
+}
+
+func newMonitoringObject(instance *kvrocksv1alpha1.KVRocks, gvk schema.GroupVersionKind, spec map[string]interface{}) *unstructured.Unstructured {
+	labels := instance.Labels
+	if instance.Spec.Monitoring != nil {
+		labels = MergeLabels(instance.Spec.Monitoring.Labels, instance.Labels)
+	}
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetName(instance.Name)
+	obj.SetNamespace(instance.Namespace)
+	obj.SetLabels(labels)
+	obj.SetOwnerReferences([]metav1.OwnerReference{
+		*metav1.NewControllerRef(instance, instance.GroupVersionKind()),
+	})
+	return obj
+}
+
+func toInterfaceMap(labels map[string]string) map[string]interface{} {
+	result := map[string]interface{}{}
+	for k, v := range labels {
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,94 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+)
+
+func TestNewPrometheusRule(t *testing.T) {
+	tests := []struct {
+		name       string
+		kvType     kvrocksv1alpha1.KVRocksType
+		monitoring *kvrocksv1alpha1.KVRocksMonitoring
+		labels     map[string]string
+		expAlerts  map[string]string
+		expLabels  map[string]string
+	}{
+		{
+			name:   "A standard kvrocks should alert on a missing master, replication lag and full disks.",
+			kvType: kvrocksv1alpha1.StandardType,
+			expAlerts: map[string]string{
+				"KVRocksMasterMissing":  `(count(kvrocks_instance_info{namespace="unit-test",kvrocks_name="test",role="master"}) or vector(0)) < 1`,
+				"KVRocksReplicationLag": `kvrocks_master_repl_offset{namespace="unit-test",kvrocks_name="test"} - on(namespace, pod) group_right() kvrocks_connected_slave_offset_bytes{namespace="unit-test",kvrocks_name="test"} > 1048576`,
+				"KVRocksDiskNearFull":   `kubelet_volume_stats_used_bytes{namespace="unit-test",persistentvolumeclaim=~"data-test-.*"} / kubelet_volume_stats_capacity_bytes{namespace="unit-test",persistentvolumeclaim=~"data-test-.*"} * 100 > 85`,
+			},
+			expLabels: map[string]string{"app": "test"},
+		}, {
+			name:   "A cluster should alert on the missing masters of all shards and the uncovered slots.",
+			kvType: kvrocksv1alpha1.ClusterType,
+			expAlerts: map[string]string{
+				"KVRocksMasterMissing":   `(count(kvrocks_instance_info{namespace="unit-test",kvrocks_name="test",role="master"}) or vector(0)) < 3`,
+				"KVRocksReplicationLag":  `kvrocks_master_repl_offset{namespace="unit-test",kvrocks_name="test"} - on(namespace, pod) group_right() kvrocks_connected_slave_offset_bytes{namespace="unit-test",kvrocks_name="test"} > 1048576`,
+				"KVRocksDiskNearFull":    `kubelet_volume_stats_used_bytes{namespace="unit-test",persistentvolumeclaim=~"data-test-.*"} / kubelet_volume_stats_capacity_bytes{namespace="unit-test",persistentvolumeclaim=~"data-test-.*"} * 100 > 85`,
+				"KVRocksSlotsNotCovered": `min(kvrocks_cluster_slots_ok{namespace="unit-test",kvrocks_name="test"}) < 16384`,
+			},
+			expLabels: map[string]string{"app": "test"},
+		}, {
+			name:       "A monitored kvrocks should alert on the sentinel quorum, and use the disk usage and labels of the spec.",
+			kvType:     kvrocksv1alpha1.StandardType,
+			monitoring: &kvrocksv1alpha1.KVRocksMonitoring{DiskUsagePercent: 90, Labels: map[string]string{"release": "prometheus", "app": "other"}},
+			labels:     map[string]string{MonitoredBy: "sentinel"},
+			expAlerts: map[string]string{
+				"KVRocksMasterMissing":      `(count(kvrocks_instance_info{namespace="unit-test",kvrocks_name="test",role="master"}) or vector(0)) < 1`,
+				"KVRocksReplicationLag":     `kvrocks_master_repl_offset{namespace="unit-test",kvrocks_name="test"} - on(namespace, pod) group_right() kvrocks_connected_slave_offset_bytes{namespace="unit-test",kvrocks_name="test"} > 1048576`,
+				"KVRocksDiskNearFull":       `kubelet_volume_stats_used_bytes{namespace="unit-test",persistentvolumeclaim=~"data-test-.*"} / kubelet_volume_stats_capacity_bytes{namespace="unit-test",persistentvolumeclaim=~"data-test-.*"} * 100 > 90`,
+				"KVRocksSentinelQuorumLost": `kube_deployment_status_replicas_available{namespace="unit-test",deployment="sentinel"} < floor(kube_deployment_spec_replicas{namespace="unit-test",deployment="sentinel"} / 2) + 1`,
+			},
+			expLabels: map[string]string{"app": "test", MonitoredBy: "sentinel", "release": "prometheus"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			instance := &kvrocksv1alpha1.KVRocks{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "unit-test",
+					Labels:    MergeLabels(map[string]string{"app": "test"}, test.labels),
+				},
+				Spec: kvrocksv1alpha1.KVRocksSpec{
+					Type:       test.kvType,
+					Master:     3,
+					Monitoring: test.monitoring,
+				},
+			}
+			rule := NewPrometheusRule(instance)
+			assert.Equal(PrometheusRuleGVK, rule.GroupVersionKind())
+			assert.Equal("test", rule.GetName())
+			assert.Equal("unit-test", rule.GetNamespace())
+			assert.Equal(test.expLabels, rule.GetLabels())
+			assert.Len(rule.GetOwnerReferences(), 1)
+
+			groups, _, err := unstructured.NestedSlice(rule.Object, "spec", "groups")
+			assert.NoError(err)
+			if !assert.Len(groups, 1) {
+				return
+			}
+			group := groups[0].(map[string]interface{})
+			assert.Equal("kvrocks-test", group["name"])
+			alerts := map[string]string{}
+			for _, rule := range group["rules"].([]interface{}) {
+				alert := rule.(map[string]interface{})
+				alerts[alert["alert"].(string)] = alert["expr"].(string)
+			}
+			assert.Equal(test.expAlerts, alerts)
+		})
+	}
+}
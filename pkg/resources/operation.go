@@ -0,0 +1,29 @@
+package resources
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+)
+
+// NewReseedOperation returns the Resync operation created by the reseed policy, it is owned by the kvrocks
+func NewReseedOperation(instance *kvrocksv1alpha1.KVRocks, podName string) *kvrocksv1alpha1.KVRocksOperation {
+	return &kvrocksv1alpha1.KVRocksOperation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-reseed-%d", podName, time.Now().Unix()),
+			Namespace: instance.Namespace,
+			Labels:    SelectorLabels(instance),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(instance, instance.GroupVersionKind()),
+			},
+		},
+		Spec: kvrocksv1alpha1.KVRocksOperationSpec{
+			Instance: instance.Name,
+			Type:     kvrocksv1alpha1.ResyncOperation,
+			Pod:      podName,
+		},
+	}
+}
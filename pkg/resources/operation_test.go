@@ -0,0 +1,39 @@
+package resources
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+)
+
+func TestNewReseedOperation(t *testing.T) {
+	assert := assert.New(t)
+
+	instance := &kvrocksv1alpha1.KVRocks{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "KVRocks",
+			APIVersion: kvrocksv1alpha1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "unit-test", UID: "uid"},
+	}
+	op := NewReseedOperation(instance, "test-1")
+	assert.True(strings.HasPrefix(op.Name, "test-1-reseed-"))
+	assert.Equal(instance.Namespace, op.Namespace)
+	assert.Equal(SelectorLabels(instance), op.Labels)
+	assert.Equal(kvrocksv1alpha1.KVRocksOperationSpec{
+		Instance: "test",
+		Type:     kvrocksv1alpha1.ResyncOperation,
+		Pod:      "test-1",
+	}, op.Spec)
+	if assert.Len(op.OwnerReferences, 1) {
+		owner := op.OwnerReferences[0]
+		assert.Equal("KVRocks", owner.Kind)
+		assert.Equal("test", owner.Name)
+		assert.Equal(instance.UID, owner.UID)
+		assert.True(*owner.Controller)
+	}
+}
@@ -0,0 +1,59 @@
+package resources
+
+import (
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+)
+
+// NewKVRocksPDB allows one pod of the statefulSet to be evicted at a time, so a drain never takes the master and
+// its slaves of a shard together
+func NewKVRocksPDB(instance *kvrocksv1alpha1.KVRocks, stsName string) *policyv1.PodDisruptionBudget {
+	return newPDB(instance, stsName, MergeLabels(instance.Labels, StatefulSetLabels(stsName)), nil, intOrStr(1))
+}
+
+// NewSentinelPDB allows one sentinel to be evicted at a time, so the others still reach the quorum and authorize a
+// failover by the majority if there are at least 3 sentinels. A minAvailable of the quorum would block the drains when
+// there are not more sentinels than the quorum
+func NewSentinelPDB(instance *kvrocksv1alpha1.KVRocks) *policyv1.PodDisruptionBudget {
+	name := GetDeploymentName(instance.Name)
+	return newPDB(instance, name, MergeLabels(instance.Labels, DeploymentLabels(name)), nil, intOrStr(1))
+}
+
+// NewEtcdPDB allows the single etcd to be evicted, a minAvailable of 1 would block the drains of its node forever
+func NewEtcdPDB(instance *kvrocksv1alpha1.KVRocks) *policyv1.PodDisruptionBudget {
+	return newPDB(instance, kvrocks.EtcdStatefulName, map[string]string{"app": "etcd"}, nil, intOrStr(1))
+}
+
+// NewKVRocksControllerPDB allows one controller to be evicted at a time, it recovers the state from etcd
+func NewKVRocksControllerPDB(instance *kvrocksv1alpha1.KVRocks) *policyv1.PodDisruptionBudget {
+	return newPDB(instance, kvrocks.ControllerDeploymentName, map[string]string{"app": "kvrocks-controller"}, nil, intOrStr(1))
+}
+
+func newPDB(instance *kvrocksv1alpha1.KVRocks, name string, labels map[string]string, minAvailable, maxUnavailable *intstr.IntOrString) *policyv1.PodDisruptionBudget {
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: instance.Namespace,
+			Labels:    instance.Labels,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(instance, instance.GroupVersionKind()),
+			},
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable:   minAvailable,
+			MaxUnavailable: maxUnavailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+		},
+	}
+}
+
+func intOrStr(value int) *intstr.IntOrString {
+	result := intstr.FromInt(value)
+	return &result
+}
@@ -0,0 +1,60 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+)
+
+func TestPDBs(t *testing.T) {
+	tests := []struct {
+		name        string
+		replicas    int32
+		newPDB      func(instance *kvrocksv1alpha1.KVRocks) *policyv1.PodDisruptionBudget
+		expSelector map[string]string
+	}{
+		{
+			name:     "The kvrocks pdb should allow one pod to be evicted.",
+			replicas: 3,
+			newPDB: func(instance *kvrocksv1alpha1.KVRocks) *policyv1.PodDisruptionBudget {
+				return NewKVRocksPDB(instance, "test-0")
+			},
+			expSelector: MergeLabels(map[string]string{"app": "test"}, StatefulSetLabels("test-0")),
+		}, {
+			name:        "The sentinel pdb should allow one pod to be evicted even with 2 sentinels.",
+			replicas:    2,
+			newPDB:      NewSentinelPDB,
+			expSelector: MergeLabels(map[string]string{"app": "test"}, DeploymentLabels("test")),
+		}, {
+			name:        "The etcd pdb should allow the single etcd to be evicted.",
+			replicas:    1,
+			newPDB:      NewEtcdPDB,
+			expSelector: map[string]string{"app": "etcd"},
+		}, {
+			name:        "The controller pdb should allow one pod to be evicted.",
+			replicas:    1,
+			newPDB:      NewKVRocksControllerPDB,
+			expSelector: map[string]string{"app": "kvrocks-controller"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			instance := &kvrocksv1alpha1.KVRocks{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "unit-test", Labels: map[string]string{"app": "test"}},
+				Spec:       kvrocksv1alpha1.KVRocksSpec{Replicas: test.replicas},
+			}
+			pdb := test.newPDB(instance)
+			assert.Nil(pdb.Spec.MinAvailable)
+			assert.Equal(intstr.FromInt(1), *pdb.Spec.MaxUnavailable)
+			assert.Equal(test.expSelector, pdb.Spec.Selector.MatchLabels)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package resources
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+)
+
+// DefaultRunAsUser is the non-root user and group which the restricted pods run as by default
+const DefaultRunAsUser int64 = 999
+
+// applyPodTemplate overlays spec.podTemplate on the pod template, the first container is the main one. The default
+// security contexts of spec.podTemplate.restricted pass the restricted pod security
+func applyPodTemplate(instance *kvrocksv1alpha1.KVRocks, template *corev1.PodTemplateSpec) {
+	podTemplate := instance.Spec.PodTemplate
+	if podTemplate == nil {
+		podTemplate = &kvrocksv1alpha1.KVRocksPodTemplate{}
+	}
+	if len(podTemplate.Labels) != 0 {
+		template.Labels = MergeLabels(podTemplate.Labels, template.Labels)
+	}
+	if len(podTemplate.Annotations) != 0 {
+		template.Annotations = MergeLabels(podTemplate.Annotations, template.Annotations)
+	}
+	spec := &template.Spec
+	spec.ServiceAccountName = podTemplate.ServiceAccountName
+	spec.PriorityClassName = podTemplate.PriorityClassName
+	spec.SchedulerName = podTemplate.SchedulerName
+	spec.HostNetwork = podTemplate.HostNetwork
+	if spec.HostNetwork {
+		spec.DNSPolicy = corev1.DNSClusterFirstWithHostNet
+	}
+	spec.ImagePullSecrets = podTemplate.ImagePullSecrets
+	spec.SecurityContext = getPodSecurityContext(podTemplate)
+	for _, volume := range podTemplate.Volumes {
+		spec.Volumes = append(spec.Volumes, *volume.DeepCopy())
+	}
+	for _, container := range podTemplate.InitContainers {
+		spec.InitContainers = append(spec.InitContainers, *container.DeepCopy())
+	}
+	if len(spec.Containers) != 0 {
+		main := &spec.Containers[0]
+		main.Env = append(main.Env, podTemplate.Env...)
+		main.VolumeMounts = append(main.VolumeMounts, podTemplate.VolumeMounts...)
+	}
+	for i := range spec.InitContainers {
+		setContainerSecurityContext(podTemplate, &spec.InitContainers[i])
+	}
+	for i := range spec.Containers {
+		setContainerSecurityContext(podTemplate, &spec.Containers[i])
+	}
+}
+
+func getPodSecurityContext(podTemplate *kvrocksv1alpha1.KVRocksPodTemplate) *corev1.PodSecurityContext {
+	if podTemplate.SecurityContext != nil {
+		return podTemplate.SecurityContext.DeepCopy()
+	}
+	if !podTemplate.Restricted {
+		return nil
+	}
+	user := DefaultRunAsUser
+	nonRoot := true
+	return &corev1.PodSecurityContext{
+		RunAsNonRoot: &nonRoot,
+		RunAsUser:    &user,
+		RunAsGroup:   &user,
+		FSGroup:      &user,
+		SeccompProfile: &corev1.SeccompProfile{
+			Type: corev1.SeccompProfileTypeRuntimeDefault,
+		},
+	}
+}
+
+func setContainerSecurityContext(podTemplate *kvrocksv1alpha1.KVRocksPodTemplate, container *corev1.Container) {
+	if container.SecurityContext != nil {
+		return
+	}
+	if podTemplate.ContainerSecurityContext != nil {
+		container.SecurityContext = podTemplate.ContainerSecurityContext.DeepCopy()
+		return
+	}
+	if !podTemplate.Restricted {
+		return
+	}
+	escalation := false
+	container.SecurityContext = &corev1.SecurityContext{
+		AllowPrivilegeEscalation: &escalation,
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+		},
+	}
+}
+
+// GetTemplateHash returns the hash of the pod template rendered by the operator
+func GetTemplateHash(template *corev1.PodTemplateSpec) string {
+	data, _ := json.Marshal(template)
+	return fmt.Sprintf("%x", sha256.Sum256(data))[:16]
+}
+
+func setTemplateHash(meta *metav1.ObjectMeta, template *corev1.PodTemplateSpec) {
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	meta.Annotations[TemplateHash] = GetTemplateHash(template)
+}
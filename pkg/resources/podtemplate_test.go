@@ -0,0 +1,135 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+)
+
+func TestApplyPodTemplate(t *testing.T) {
+	user := DefaultRunAsUser
+	nonRoot := true
+	escalation := false
+	restrictedPod := &corev1.PodSecurityContext{
+		RunAsNonRoot:   &nonRoot,
+		RunAsUser:      &user,
+		RunAsGroup:     &user,
+		FSGroup:        &user,
+		SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+	restrictedContainer := &corev1.SecurityContext{
+		AllowPrivilegeEscalation: &escalation,
+		Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+	}
+	customUser := int64(1000)
+	customPod := &corev1.PodSecurityContext{RunAsUser: &customUser}
+	customContainer := &corev1.SecurityContext{RunAsUser: &customUser}
+	env := corev1.EnvVar{Name: "TZ", Value: "UTC"}
+	mount := corev1.VolumeMount{Name: "extra", MountPath: "/extra"}
+
+	tests := []struct {
+		name                 string
+		podTemplate          *kvrocksv1alpha1.KVRocksPodTemplate
+		expPodSecurity       *corev1.PodSecurityContext
+		expContainerSecurity *corev1.SecurityContext
+		expLabels            map[string]string
+		expAnnotations       map[string]string
+		expEnv               []corev1.EnvVar
+		expMounts            []corev1.VolumeMount
+		expDNSPolicy         corev1.DNSPolicy
+	}{
+		{
+			name:      "No security context should be set without the pod template.",
+			expLabels: map[string]string{"app": "test"},
+		}, {
+			name:                 "The restricted pod template should set the default security contexts.",
+			podTemplate:          &kvrocksv1alpha1.KVRocksPodTemplate{Restricted: true},
+			expPodSecurity:       restrictedPod,
+			expContainerSecurity: restrictedContainer,
+			expLabels:            map[string]string{"app": "test"},
+		}, {
+			name: "The security contexts of the pod template should replace the restricted ones.",
+			podTemplate: &kvrocksv1alpha1.KVRocksPodTemplate{
+				Restricted:               true,
+				SecurityContext:          customPod,
+				ContainerSecurityContext: customContainer,
+			},
+			expPodSecurity:       customPod,
+			expContainerSecurity: customContainer,
+			expLabels:            map[string]string{"app": "test"},
+		}, {
+			name: "The labels and annotations should not override those set by the operator.",
+			podTemplate: &kvrocksv1alpha1.KVRocksPodTemplate{
+				Labels:      map[string]string{"app": "other", "team": "db"},
+				Annotations: map[string]string{"owner": "db"},
+			},
+			expLabels:      map[string]string{"app": "test", "team": "db"},
+			expAnnotations: map[string]string{"owner": "db"},
+		}, {
+			name: "The env and volume mounts should be added to the main container.",
+			podTemplate: &kvrocksv1alpha1.KVRocksPodTemplate{
+				Env:          []corev1.EnvVar{env},
+				VolumeMounts: []corev1.VolumeMount{mount},
+			},
+			expLabels: map[string]string{"app": "test"},
+			expEnv:    []corev1.EnvVar{env},
+			expMounts: []corev1.VolumeMount{mount},
+		}, {
+			name:         "The host network should use the cluster dns.",
+			podTemplate:  &kvrocksv1alpha1.KVRocksPodTemplate{HostNetwork: true},
+			expLabels:    map[string]string{"app": "test"},
+			expDNSPolicy: corev1.DNSClusterFirstWithHostNet,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			instance := &kvrocksv1alpha1.KVRocks{
+				Spec: kvrocksv1alpha1.KVRocksSpec{PodTemplate: test.podTemplate},
+			}
+			template := &corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "test"}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "kvrocks"}, {Name: "exporter"}},
+				},
+			}
+			applyPodTemplate(instance, template)
+			assert.Equal(test.expPodSecurity, template.Spec.SecurityContext)
+			for _, container := range template.Spec.Containers {
+				assert.Equal(test.expContainerSecurity, container.SecurityContext)
+			}
+			assert.Equal(test.expLabels, template.Labels)
+			assert.Equal(test.expAnnotations, template.Annotations)
+			assert.Equal(test.expEnv, template.Spec.Containers[0].Env)
+			assert.Equal(test.expMounts, template.Spec.Containers[0].VolumeMounts)
+			assert.Empty(template.Spec.Containers[1].Env)
+			assert.Equal(test.expDNSPolicy, template.Spec.DNSPolicy)
+		})
+	}
+}
+
+func TestTemplateHash(t *testing.T) {
+	assert := assert.New(t)
+
+	instance := &kvrocksv1alpha1.KVRocks{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "unit-test"},
+		Spec: kvrocksv1alpha1.KVRocksSpec{
+			Type:      kvrocksv1alpha1.StandardType,
+			Image:     "apache/kvrocks:2.4.0",
+			Replicas:  3,
+			Resources: &corev1.ResourceRequirements{},
+		},
+	}
+	hash := NewReplicationStatefulSet(instance).Annotations[TemplateHash]
+	assert.Len(hash, 16)
+	assert.Equal(hash, NewReplicationStatefulSet(instance).Annotations[TemplateHash])
+
+	instance.Spec.PodTemplate = &kvrocksv1alpha1.KVRocksPodTemplate{PriorityClassName: "high"}
+	assert.NotEqual(hash, NewReplicationStatefulSet(instance).Annotations[TemplateHash])
+}
@@ -0,0 +1,35 @@
+package resources
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+)
+
+// ConnectionSecretType is the secret type of redis compatible services in the Service Binding spec
+const ConnectionSecretType corev1.SecretType = "servicebinding.io/redis"
+
+// NewConnectionSecret returns the secret with the connection info of kvrocks, its layout follows the Service Binding spec
+func NewConnectionSecret(instance *kvrocksv1alpha1.KVRocks, data map[string]string) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetConnectionSecretName(instance.Name),
+			Namespace: instance.Namespace,
+			Labels:    instance.Labels,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(instance, instance.GroupVersionKind()),
+			},
+		},
+		Type: ConnectionSecretType,
+		Data: map[string][]byte{},
+	}
+	for key, value := range data {
+		secret.Data[key] = []byte(value)
+	}
+	return secret
+}
+
+func GetConnectionSecretName(name string) string {
+	return name + "-connection"
+}
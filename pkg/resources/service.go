@@ -1,6 +1,9 @@
 package resources
 
 import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -9,6 +12,8 @@ import (
 	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
 )
 
+// NewSentinelService returns the service of the sentinels, it is never exposed since the sentinels return the internal
+// addresses of the masters
 func NewSentinelService(instance *kvrocksv1alpha1.KVRocks) *corev1.Service {
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -20,7 +25,6 @@ func NewSentinelService(instance *kvrocksv1alpha1.KVRocks) *corev1.Service {
 			},
 		},
 		Spec: corev1.ServiceSpec{
-			Type: corev1.ServiceTypeClusterIP,
 			Ports: []corev1.ServicePort{{
 				Name: "sentinel",
 				Port: kvrocks.SentinelPort,
@@ -30,18 +34,33 @@ func NewSentinelService(instance *kvrocksv1alpha1.KVRocks) *corev1.Service {
 	}
 }
 
+// NewSentinelHeadlessService returns the headless service of the sentinels, its dns name resolves to all ready sentinels
+// so the clients can find them without the pod ips
+func NewSentinelHeadlessService(instance *kvrocksv1alpha1.KVRocks) *corev1.Service {
+	service := NewSentinelService(instance)
+	service.Name = GetHeadlessServiceName(instance.Name)
+	service.Spec.ClusterIP = corev1.ClusterIPNone
+	return service
+}
+
+// GetSentinelAddress returns the address of the headless service of the sentinels
+func GetSentinelAddress(sentinelName, namespace string) string {
+	return fmt.Sprintf("%s.%s.svc:%d", GetHeadlessServiceName(sentinelName), namespace, kvrocks.SentinelPort)
+}
+
 func NewKVRocksService(instance *kvrocksv1alpha1.KVRocks) *corev1.Service {
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      instance.Name,
-			Namespace: instance.Namespace,
-			Labels:    instance.Labels,
+			Name:        instance.Name,
+			Namespace:   instance.Namespace,
+			Labels:      instance.Labels,
+			Annotations: getExposeAnnotations(instance),
 			OwnerReferences: []metav1.OwnerReference{
 				*metav1.NewControllerRef(instance, instance.GroupVersionKind()),
 			},
 		},
 		Spec: corev1.ServiceSpec{
-			Type: corev1.ServiceTypeClusterIP,
+			Type: GetExposeType(instance),
 			Ports: []corev1.ServicePort{
 				{
 					Name: "kvrocks",
@@ -55,6 +74,106 @@ func NewKVRocksService(instance *kvrocksv1alpha1.KVRocks) *corev1.Service {
 	}
 }
 
+// NewReadService returns the service of the slaves, the slaves lagging behind their masters are not ready and excluded
+func NewReadService(instance *kvrocksv1alpha1.KVRocks) *corev1.Service {
+	service := NewKVRocksService(instance)
+	service.Name = GetReadServiceName(instance.Name)
+	service.Spec.Selector = MergeLabels(instance.Labels, map[string]string{
+		KvrocksRole: kvrocks.RoleSlaver,
+	})
+	return service
+}
+
+func GetReadServiceName(name string) string {
+	return name + "-read"
+}
+
+// GetEndpoints returns the addresses of the master service and the read service
+func GetEndpoints(instance *kvrocksv1alpha1.KVRocks) *kvrocksv1alpha1.KVRocksEndpoints {
+	return &kvrocksv1alpha1.KVRocksEndpoints{
+		Write: fmt.Sprintf("%s.%s.svc:%d", instance.Name, instance.Namespace, kvrocks.KVRocksPort),
+		Read:  fmt.Sprintf("%s.%s.svc:%d", GetReadServiceName(instance.Name), instance.Namespace, kvrocks.KVRocksPort),
+	}
+}
+
+// NewHeadlessService returns the headless service of the statefulSet, which gives each pod a stable dns name
+func NewHeadlessService(instance *kvrocksv1alpha1.KVRocks, stsName string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetHeadlessServiceName(stsName),
+			Namespace: instance.Namespace,
+			Labels:    instance.Labels,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(instance, instance.GroupVersionKind()),
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			// the pods are resolvable before ready, replication is set up before the pods pass the readiness probe
+			PublishNotReadyAddresses: true,
+			Ports: []corev1.ServicePort{
+				{
+					Name: "kvrocks",
+					Port: kvrocks.KVRocksPort,
+				},
+			},
+			Selector: MergeLabels(instance.Labels, StatefulSetLabels(stsName)),
+		},
+	}
+}
+
+func GetHeadlessServiceName(stsName string) string {
+	return stsName + "-headless"
+}
+
+// NewPodService returns the exposed service of a single pod, whose address is announced by the node
+func NewPodService(instance *kvrocksv1alpha1.KVRocks, podName string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        GetPodServiceName(podName),
+			Namespace:   instance.Namespace,
+			Labels:      MergeLabels(instance.Labels, PodServiceLabels()),
+			Annotations: getExposeAnnotations(instance),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(instance, instance.GroupVersionKind()),
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Type: GetExposeType(instance),
+			Ports: []corev1.ServicePort{
+				{
+					Name: "kvrocks",
+					Port: kvrocks.KVRocksPort,
+				},
+			},
+			Selector: map[string]string{
+				appsv1.StatefulSetPodNameLabel: podName,
+			},
+		},
+	}
+}
+
+// GetPodServiceName returns the name of the exposed service of the pod, the suffix keeps it apart from the services
+// of the users named after the pods
+func GetPodServiceName(podName string) string {
+	return podName + "-external"
+}
+
+// GetExposeType returns the type of the exposed services, ClusterIP if kvrocks is not exposed
+func GetExposeType(instance *kvrocksv1alpha1.KVRocks) corev1.ServiceType {
+	if instance.Spec.Expose == nil {
+		return corev1.ServiceTypeClusterIP
+	}
+	return instance.Spec.Expose.Type
+}
+
+func getExposeAnnotations(instance *kvrocksv1alpha1.KVRocks) map[string]string {
+	if instance.Spec.Expose == nil {
+		return nil
+	}
+	return instance.Spec.Expose.Annotations
+}
+
 func NewEtcdService(instance *kvrocksv1alpha1.KVRocks) *corev1.Service {
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,49 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+	"github.com/RocksLabs/kvrocks-operator/pkg/client/kvrocks"
+)
+
+func TestNewHeadlessService(t *testing.T) {
+	assert := assert.New(t)
+
+	instance := &kvrocksv1alpha1.KVRocks{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "unit-test", Labels: map[string]string{"app": "test"}},
+	}
+	service := NewHeadlessService(instance, "test-0")
+	assert.Equal("test-0-headless", service.Name)
+	assert.Equal("unit-test", service.Namespace)
+	assert.Equal(corev1.ClusterIPNone, service.Spec.ClusterIP)
+	assert.True(service.Spec.PublishNotReadyAddresses)
+	assert.Equal(MergeLabels(map[string]string{"app": "test"}, StatefulSetLabels("test-0")), service.Spec.Selector)
+	if assert.Len(service.Spec.Ports, 1) {
+		assert.Equal(int32(kvrocks.KVRocksPort), service.Spec.Ports[0].Port)
+	}
+	if assert.Len(service.OwnerReferences, 1) {
+		assert.True(*service.OwnerReferences[0].Controller)
+	}
+}
+
+func TestNewPodService(t *testing.T) {
+	assert := assert.New(t)
+
+	instance := &kvrocksv1alpha1.KVRocks{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "unit-test", Labels: map[string]string{"app": "test"}},
+		Spec: kvrocksv1alpha1.KVRocksSpec{
+			Expose: &kvrocksv1alpha1.KVRocksExpose{Type: corev1.ServiceTypeNodePort},
+		},
+	}
+	service := NewPodService(instance, "test-0-1")
+	// the service is not named after the pod, which may collide with the services of the users
+	assert.Equal("test-0-1-external", service.Name)
+	assert.Equal(corev1.ServiceTypeNodePort, service.Spec.Type)
+	assert.Equal(map[string]string{appsv1.StatefulSetPodNameLabel: "test-0-1"}, service.Spec.Selector)
+}
@@ -21,6 +21,30 @@ var TerminationGracePeriodSeconds int64 = 20
 
 const DefaultStorageSize = "10Gi"
 
+// DefaultMaxReplicationLag is the max replication offset a slave can lag behind in the read service by default
+const DefaultMaxReplicationLag int64 = 1048576
+
+// ReplicationReadyGate is the readiness gate of the kvrocks pods, it is false when a slave lags behind its master
+// more than spec.maxReplicationLag, and true for a master as soon as its role is known
+const ReplicationReadyGate corev1.PodConditionType = "kvrocks/replication-ready"
+
+// KeepReadinessGates removes the readiness gates of the rendered template which are not in gates, the ones of the
+// existing template or pods. The readiness gates of a pod are immutable, adding one would recreate all pods at once
+// without switching over the master, so a new gate only takes effect for the statefulSets created without pods
+func KeepReadinessGates(template *corev1.PodTemplateSpec, gates []corev1.PodReadinessGate) {
+	existing := map[corev1.PodConditionType]struct{}{}
+	for _, gate := range gates {
+		existing[gate.ConditionType] = struct{}{}
+	}
+	var kept []corev1.PodReadinessGate
+	for _, gate := range template.Spec.ReadinessGates {
+		if _, ok := existing[gate.ConditionType]; ok {
+			kept = append(kept, gate)
+		}
+	}
+	template.Spec.ReadinessGates = kept
+}
+
 func NewStatefulSet(instance *kvrocksv1alpha1.KVRocks, name string) *kruise.StatefulSet {
 	labels := MergeLabels(instance.Labels, StatefulSetLabels(name))
 	sts := &kruise.StatefulSet{
@@ -34,6 +58,7 @@ func NewStatefulSet(instance *kvrocksv1alpha1.KVRocks, name string) *kruise.Stat
 		},
 		Spec: kruise.StatefulSetSpec{
 			Replicas:            &instance.Spec.Replicas,
+			ServiceName:         GetHeadlessServiceName(name),
 			PodManagementPolicy: v1.ParallelPodManagement, // 并行启动终止 pod
 			Selector: &metav1.LabelSelector{
 				MatchLabels: labels,
@@ -53,15 +78,15 @@ func NewStatefulSet(instance *kvrocksv1alpha1.KVRocks, name string) *kruise.Stat
 					Labels: labels,
 				},
 				Spec: corev1.PodSpec{
-					Affinity: getAffinity(instance, labels),
-					// ImagePullSecrets:              instance.Spec.ImagePullSecrets,
-					// SecurityContext:               instance.Spec.SecurityContext,
+					Affinity:                      getAffinity(instance, labels),
+					TopologySpreadConstraints:     getTopologySpreadConstraints(instance),
 					NodeSelector:                  instance.Spec.NodeSelector,
 					Tolerations:                   instance.Spec.Toleration,
 					TerminationGracePeriodSeconds: &TerminationGracePeriodSeconds,
-					// SchedulerName:                 instance.Spec.SchedulerName,
 					ReadinessGates: []corev1.PodReadinessGate{{
 						ConditionType: pub.InPlaceUpdateReady,
+					}, {
+						ConditionType: ReplicationReadyGate,
 					}},
 					Volumes: []corev1.Volume{
 						{
@@ -104,22 +129,75 @@ func getAffinity(instance *kvrocksv1alpha1.KVRocks, labels map[string]string) *c
 	}
 	affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
 		affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, hostNameTopoWeak)
+	if instance.Spec.Placement == nil {
+		return affinity
+	}
+	// the replicas of a shard are in different zones, so that a zone outage never takes a whole shard down
+	zoneTopo := corev1.PodAffinityTerm{
+		TopologyKey: GetZoneKey(instance),
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: labels,
+		},
+	}
+	if instance.Spec.Placement.Policy == kvrocksv1alpha1.RequiredZonePlacement {
+		affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(
+			affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution, zoneTopo)
+		return affinity
+	}
+	affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+		affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, corev1.WeightedPodAffinityTerm{
+			Weight:          100,
+			PodAffinityTerm: zoneTopo,
+		})
 	return affinity
 }
 
+// getTopologySpreadConstraints selects all pods of the kvrocks by the constraints without labelSelector. With the
+// zone-aware placement, the pods of all shards are spread across zones evenly
+func getTopologySpreadConstraints(instance *kvrocksv1alpha1.KVRocks) []corev1.TopologySpreadConstraint {
+	var constraints []corev1.TopologySpreadConstraint
+	for _, constraint := range instance.Spec.TopologySpreadConstraints {
+		constraint = *constraint.DeepCopy()
+		if constraint.LabelSelector == nil {
+			constraint.LabelSelector = &metav1.LabelSelector{
+				MatchLabels: instance.Labels,
+			}
+		}
+		constraints = append(constraints, constraint)
+	}
+	if instance.Spec.Placement != nil {
+		constraints = append(constraints, corev1.TopologySpreadConstraint{
+			MaxSkew:           1,
+			TopologyKey:       GetZoneKey(instance),
+			WhenUnsatisfiable: corev1.ScheduleAnyway,
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: instance.Labels,
+			},
+		})
+	}
+	return constraints
+}
+
+// GetZoneKey returns the node label of the zone used by the zone-aware placement
+func GetZoneKey(instance *kvrocksv1alpha1.KVRocks) string {
+	if instance.Spec.Placement != nil && instance.Spec.Placement.ZoneKey != "" {
+		return instance.Spec.Placement.ZoneKey
+	}
+	return corev1.LabelTopologyZone
+}
+
+// RebalanceMasters checks if the masters are spread across zones by switchover
+func RebalanceMasters(instance *kvrocksv1alpha1.KVRocks) bool {
+	return instance.Spec.Placement != nil && instance.Spec.Placement.RebalanceMasters
+}
+
 func getPersistentClaim(instance *kvrocksv1alpha1.KVRocks, labels map[string]string) corev1.PersistentVolumeClaim {
 	mode := corev1.PersistentVolumeFilesystem
 	var class *string = nil
-	size := resource.MustParse(DefaultStorageSize)
-	if instance.Spec.Storage != nil {
-		if instance.Spec.Storage.Class != "" {
-			class = &instance.Spec.Storage.Class
-		}
-
-		if !instance.Spec.Storage.Size.IsZero() {
-			size = instance.Spec.Storage.Size
-		}
+	if instance.Spec.Storage != nil && instance.Spec.Storage.Class != "" {
+		class = &instance.Spec.Storage.Class
 	}
+	size := GetStorageSize(instance)
 	return corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:   "data",
@@ -141,21 +219,62 @@ func getPersistentClaim(instance *kvrocksv1alpha1.KVRocks, labels map[string]str
 	}
 }
 
+// GetStorageSize returns the requested size of the data volume, the larger one of the spec and the autoscaled size
+func GetStorageSize(instance *kvrocksv1alpha1.KVRocks) resource.Quantity {
+	size := resource.MustParse(DefaultStorageSize)
+	if instance.Spec.Storage != nil && !instance.Spec.Storage.Size.IsZero() {
+		size = instance.Spec.Storage.Size
+	}
+	if instance.Status.StorageSize != nil && instance.Status.StorageSize.Cmp(size) > 0 {
+		size = *instance.Status.StorageSize
+	}
+	return size
+}
+
+// GetPVCRetentionPolicy returns the retention policy of the pvcs with defaults
+func GetPVCRetentionPolicy(instance *kvrocksv1alpha1.KVRocks) kvrocksv1alpha1.KVRocksPVCRetentionPolicy {
+	policy := kvrocksv1alpha1.KVRocksPVCRetentionPolicy{}
+	if instance.Spec.Storage != nil && instance.Spec.Storage.RetentionPolicy != nil {
+		policy = *instance.Spec.Storage.RetentionPolicy
+	}
+	if policy.WhenScaled == "" {
+		policy.WhenScaled = kvrocksv1alpha1.DeletePVCRetentionPolicy
+	}
+	if policy.WhenDeleted == "" {
+		policy.WhenDeleted = kvrocksv1alpha1.DeletePVCRetentionPolicy
+	}
+	if policy.WhenFailover == "" {
+		policy.WhenFailover = kvrocksv1alpha1.DeleteFailoverPVCPolicy
+	}
+	return policy
+}
+
 func NewSentinelStatefulSet(instance *kvrocksv1alpha1.KVRocks) *kruise.StatefulSet {
 	sts := NewStatefulSet(instance, GetStatefulSetName(instance.Name))
 	sts.Spec.Template.Spec.Containers = append(sts.Spec.Template.Spec.Containers, *NewSentinelContainer(instance))
+	applyPodTemplate(instance, &sts.Spec.Template)
 	return sts
 }
 
 func NewReplicationStatefulSet(instance *kvrocksv1alpha1.KVRocks) *kruise.StatefulSet {
 	sts := NewStatefulSet(instance, GetStatefulSetName(instance.Name))
-	sts.Spec.Template.Spec.Containers = append(sts.Spec.Template.Spec.Containers, *NewInstanceContainer(instance), *NewExporterContainer(instance))
+	sts.Spec.Template.Spec.Containers = append(sts.Spec.Template.Spec.Containers, *NewInstanceContainer(instance))
+	if IsExporterEnabled(instance) {
+		sts.Spec.Template.Spec.Containers = append(sts.Spec.Template.Spec.Containers, *NewExporterContainer(instance))
+	}
+	applyPodTemplate(instance, &sts.Spec.Template)
+	setTemplateHash(&sts.ObjectMeta, &sts.Spec.Template)
 	return sts
 }
 
 func NewClusterStatefulSet(instance *kvrocksv1alpha1.KVRocks, index int) *kruise.StatefulSet {
 	sts := NewStatefulSet(instance, GetStatefulSetName(instance.Name, index))
-	sts.Spec.Template.Spec.Containers = append(sts.Spec.Template.Spec.Containers, *NewInstanceContainer(instance), *NewExporterContainer(instance))
+	sts.Spec.Template.Spec.Containers = append(sts.Spec.Template.Spec.Containers, *NewInstanceContainer(instance))
+	if IsExporterEnabled(instance) {
+		sts.Spec.Template.Spec.Containers = append(sts.Spec.Template.Spec.Containers, *NewExporterContainer(instance))
+	}
+	applyPodTemplate(instance, &sts.Spec.Template)
+	setTemplateHash(&sts.ObjectMeta, &sts.Spec.Template)
 	return sts
 }
 
@@ -164,6 +283,59 @@ func GetPVCOrPodIndex(podName string) (int, error) {
 	return strconv.Atoi(index)
 }
 
+// GetMaxReplicationLag returns spec.maxReplicationLag with default
+func GetMaxReplicationLag(instance *kvrocksv1alpha1.KVRocks) int64 {
+	if instance.Spec.MaxReplicationLag == nil {
+		return DefaultMaxReplicationLag
+	}
+	return *instance.Spec.MaxReplicationLag
+}
+
+// IsPodReady checks if the containers of the pod are ready and the readiness gates except the replication one are
+// passed, a lagging slave still takes part in the replication
+func IsPodReady(pod *corev1.Pod) bool {
+	conditions := map[corev1.PodConditionType]corev1.ConditionStatus{}
+	for _, condition := range pod.Status.Conditions {
+		conditions[condition.Type] = condition.Status
+	}
+	if conditions[corev1.ContainersReady] != corev1.ConditionTrue {
+		return false
+	}
+	for _, gate := range pod.Spec.ReadinessGates {
+		if gate.ConditionType != ReplicationReadyGate && conditions[gate.ConditionType] != corev1.ConditionTrue {
+			return false
+		}
+	}
+	return true
+}
+
+func CountReadyPods(pods *corev1.PodList) int32 {
+	var count int32
+	for i := range pods.Items {
+		if IsPodReady(&pods.Items[i]) {
+			count++
+		}
+	}
+	return count
+}
+
+// ClusterDomain is the dns domain of the kubernetes cluster, the dns names of the pods are fully qualified so that
+// they do not depend on the search paths of the resolver
+var ClusterDomain = "cluster.local"
+
+// GetPodHost returns the stable dns name of the pod, the pods created before the statefulSet has a headless service
+// have no dns name and use their ip
+func GetPodHost(pod *corev1.Pod) string {
+	if pod.Spec.Subdomain == "" {
+		return pod.Status.PodIP
+	}
+	hostname := pod.Spec.Hostname
+	if hostname == "" {
+		hostname = pod.Name
+	}
+	return fmt.Sprintf("%s.%s.%s.svc.%s", hostname, pod.Spec.Subdomain, pod.Namespace, ClusterDomain)
+}
+
 func GetStatefulSetName(name string, index ...int) string {
 	if len(index) != 0 {
 		return fmt.Sprintf("%s-%d", name, index[0])
@@ -175,7 +347,7 @@ func GetStatefulSetName(name string, index ...int) string {
 func NewEtcdStatefulSet(instance *kvrocksv1alpha1.KVRocks) *appsv1.StatefulSet {
 	replicas := int32(1)
 
-	return &appsv1.StatefulSet{
+	sts := &appsv1.StatefulSet{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "apps/v1",
 			Kind:       "StatefulSet",
@@ -201,7 +373,7 @@ func NewEtcdStatefulSet(instance *kvrocksv1alpha1.KVRocks) *appsv1.StatefulSet {
 					Containers: []corev1.Container{
 						{
 							Name:  "etcd",
-							Image: "quay.io/coreos/etcd:latest",
+							Image: EtcdImage,
 							Ports: []corev1.ContainerPort{
 								{
 									ContainerPort: kvrocks.EtcdServerPort,
@@ -218,6 +390,22 @@ func NewEtcdStatefulSet(instance *kvrocksv1alpha1.KVRocks) *appsv1.StatefulSet {
 								"--initial-advertise-peer-urls=http://" + kvrocks.EtcdServiceName + ":" + strconv.Itoa(kvrocks.EtcdServerPort),
 								"--initial-cluster=etcd0=http://" + kvrocks.EtcdServiceName + ":" + strconv.Itoa(kvrocks.EtcdServerPort),
 								"--initial-cluster-state=new",
+								// the working directory is not writable by the non-root user
+								"--data-dir=/var/lib/etcd",
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "data",
+									MountPath: "/var/lib/etcd",
+								},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "data",
+							VolumeSource: corev1.VolumeSource{
+								EmptyDir: &corev1.EmptyDirVolumeSource{},
 							},
 						},
 					},
@@ -225,4 +413,7 @@ func NewEtcdStatefulSet(instance *kvrocksv1alpha1.KVRocks) *appsv1.StatefulSet {
 			},
 		},
 	}
+	applyPodTemplate(instance, &sts.Spec.Template)
+	setTemplateHash(&sts.ObjectMeta, &sts.Spec.Template)
+	return sts
 }
@@ -0,0 +1,94 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kvrocksv1alpha1 "github.com/RocksLabs/kvrocks-operator/api/v1alpha1"
+)
+
+func TestGetPVCRetentionPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		storage   *kvrocksv1alpha1.KVRocksStorage
+		expPolicy kvrocksv1alpha1.KVRocksPVCRetentionPolicy
+	}{
+		{
+			name:    "The pvcs should be deleted by default.",
+			storage: nil,
+			expPolicy: kvrocksv1alpha1.KVRocksPVCRetentionPolicy{
+				WhenScaled:   kvrocksv1alpha1.DeletePVCRetentionPolicy,
+				WhenDeleted:  kvrocksv1alpha1.DeletePVCRetentionPolicy,
+				WhenFailover: kvrocksv1alpha1.DeleteFailoverPVCPolicy,
+			},
+		}, {
+			name: "The policy in spec should be kept.",
+			storage: &kvrocksv1alpha1.KVRocksStorage{
+				RetentionPolicy: &kvrocksv1alpha1.KVRocksPVCRetentionPolicy{
+					WhenScaled:   kvrocksv1alpha1.RetainPVCRetentionPolicy,
+					WhenFailover: kvrocksv1alpha1.DeleteOnNodeLossFailoverPVCPolicy,
+				},
+			},
+			expPolicy: kvrocksv1alpha1.KVRocksPVCRetentionPolicy{
+				WhenScaled:   kvrocksv1alpha1.RetainPVCRetentionPolicy,
+				WhenDeleted:  kvrocksv1alpha1.DeletePVCRetentionPolicy,
+				WhenFailover: kvrocksv1alpha1.DeleteOnNodeLossFailoverPVCPolicy,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			instance := &kvrocksv1alpha1.KVRocks{Spec: kvrocksv1alpha1.KVRocksSpec{Storage: test.storage}}
+			assert.Equal(t, test.expPolicy, GetPVCRetentionPolicy(instance))
+		})
+	}
+}
+
+func TestGetPodHost(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    corev1.PodSpec
+		domain  string
+		expHost string
+	}{
+		{
+			name:    "A pod without subdomain should use its ip.",
+			expHost: "10.0.0.1",
+		}, {
+			name:    "A pod with subdomain should use its fully qualified dns name.",
+			spec:    corev1.PodSpec{Subdomain: "test-0-headless"},
+			expHost: "test-0-1.test-0-headless.unit-test.svc.cluster.local",
+		}, {
+			name:    "The hostname of the pod should be preferred to its name.",
+			spec:    corev1.PodSpec{Hostname: "host", Subdomain: "test-0-headless"},
+			expHost: "host.test-0-headless.unit-test.svc.cluster.local",
+		}, {
+			name:    "The dns name should end with the cluster domain.",
+			spec:    corev1.PodSpec{Subdomain: "test-0-headless"},
+			domain:  "example.org",
+			expHost: "test-0-1.test-0-headless.unit-test.svc.example.org",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.domain != "" {
+				domain := ClusterDomain
+				ClusterDomain = test.domain
+				t.Cleanup(func() {
+					ClusterDomain = domain
+				})
+			}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-0-1", Namespace: "unit-test"},
+				Spec:       test.spec,
+				Status:     corev1.PodStatus{PodIP: "10.0.0.1"},
+			}
+			assert.Equal(t, test.expHost, GetPodHost(pod))
+		})
+	}
+}